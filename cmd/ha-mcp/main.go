@@ -3,11 +3,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -19,15 +25,17 @@ import (
 	"github.com/zorak1103/ha-mcp/internal/homeassistant"
 	"github.com/zorak1103/ha-mcp/internal/logging"
 	"github.com/zorak1103/ha-mcp/internal/mcp"
+	"github.com/zorak1103/ha-mcp/internal/metrics"
 )
 
 // App holds the CLI application state and dependencies.
 type App struct {
-	cfgFile string
-	haURL   string
-	haToken string
-	port    int
-	rootCmd *cobra.Command
+	cfgFile   string
+	haURL     string
+	haToken   string
+	port      int
+	transport string
+	rootCmd   *cobra.Command
 }
 
 // NewApp creates a new CLI application instance with all dependencies.
@@ -48,7 +56,7 @@ func (a *App) buildRootCmd() *cobra.Command {
 AI agents like Cline and opencode with access to Home Assistant.
 
 It exposes Home Assistant entities, automations, scripts, scenes,
-and helpers through the MCP protocol over HTTP.`,
+and helpers through the MCP protocol over HTTP or stdio.`,
 		RunE: a.run,
 	}
 }
@@ -59,10 +67,12 @@ func (a *App) setupFlags() {
 	a.rootCmd.PersistentFlags().StringVar(&a.haURL, "ha-url", "", "Home Assistant URL")
 	a.rootCmd.PersistentFlags().StringVar(&a.haToken, "ha-token", "", "Home Assistant long-lived access token")
 	a.rootCmd.PersistentFlags().IntVar(&a.port, "port", 0, "MCP server port")
+	a.rootCmd.PersistentFlags().StringVar(&a.transport, "transport", "", "MCP transport: http or stdio (default: http)")
 
 	bindPFlag("homeassistant.url", a.rootCmd.PersistentFlags().Lookup("ha-url"))
 	bindPFlag("homeassistant.token", a.rootCmd.PersistentFlags().Lookup("ha-token"))
 	bindPFlag("server.port", a.rootCmd.PersistentFlags().Lookup("port"))
+	bindPFlag("server.transport", a.rootCmd.PersistentFlags().Lookup("transport"))
 }
 
 // addCommands adds subcommands to the root command.
@@ -169,18 +179,109 @@ func (a *App) runConfig(_ *cobra.Command, _ []string) error {
 	fmt.Println("=======================")
 	fmt.Println()
 	fmt.Println("Home Assistant:")
-	fmt.Printf("  URL:   %s\n", masked.HomeAssistant.URL)
-	fmt.Printf("  Token: %s\n", masked.HomeAssistant.Token)
+	fmt.Printf("  URL:         %s\n", masked.HomeAssistant.URL)
+	fmt.Printf("  Token:       %s\n", masked.HomeAssistant.Token)
+	fmt.Printf("  Timeout:     %ds\n", masked.HomeAssistant.Timeout)
+	fmt.Printf("  Retries:     %d\n", masked.HomeAssistant.Retries)
+	fmt.Printf("  State cache: %t\n", masked.HomeAssistant.StateCache)
 	fmt.Println()
 	fmt.Println("Server:")
-	fmt.Printf("  Port:  %d\n", masked.Server.Port)
+	fmt.Printf("  Port:      %d\n", masked.Server.Port)
+	fmt.Printf("  Transport: %s\n", masked.Server.Transport)
+	fmt.Printf("  Page size: %d\n", masked.Server.PageSize)
+	fmt.Printf("  Metrics:   %t\n", masked.Server.Metrics)
 	fmt.Println()
+	printAuthConfig(&masked.Server.Auth)
+	printRateLimitConfig(&masked.Server.RateLimit)
 	fmt.Println("Logging:")
 	fmt.Printf("  Level: %s\n", masked.Logging.Level)
+	fmt.Println()
+	printToolsConfig(&masked.Tools)
+	fmt.Println()
+	printAuditConfig(&masked.Audit)
 
 	return nil
 }
 
+// printAuthConfig prints the authentication section of the config command.
+func printAuthConfig(authCfg *config.AuthConfig) {
+	fmt.Println("Authentication:")
+	if len(authCfg.Keys) == 0 && authCfg.KeysFile == "" {
+		fmt.Println("  Disabled (no API keys configured)")
+	}
+	for _, k := range authCfg.Keys {
+		fmt.Printf("  Key:       %s (%s)\n", k.Name, k.Key)
+	}
+	if authCfg.KeysFile != "" {
+		fmt.Printf("  Keys file: %s\n", authCfg.KeysFile)
+	}
+	fmt.Printf("  Protect /health: %t\n", authCfg.ProtectHealth)
+	fmt.Println()
+}
+
+// printRateLimitConfig prints the rate limit section of the config command.
+func printRateLimitConfig(rlCfg *config.RateLimitConfig) {
+	fmt.Println("Rate limits:")
+	fmt.Printf("  Client:    %s\n", formatRateLimit(rlCfg.Client))
+	fmt.Printf("  Per tool:  %s\n", formatRateLimit(rlCfg.Tool))
+	for _, name := range slices.Sorted(maps.Keys(rlCfg.Tools)) {
+		fmt.Printf("  %s: %s\n", name, formatRateLimit(rlCfg.Tools[name]))
+	}
+	if rlCfg.MaxConcurrentCommands > 0 {
+		fmt.Printf("  Max concurrent commands: %d\n", rlCfg.MaxConcurrentCommands)
+	} else {
+		fmt.Println("  Max concurrent commands: unlimited")
+	}
+	fmt.Println()
+}
+
+// printToolsConfig prints the tools section of the config command.
+func printToolsConfig(toolsCfg *config.ToolsConfig) {
+	fmt.Println("Tools:")
+	fmt.Printf("  Read-only:     %t\n", toolsCfg.ReadOnly)
+	fmt.Printf("  Dry run:       %t\n", toolsCfg.DryRun)
+	if len(toolsCfg.Allow) > 0 {
+		fmt.Printf("  Allow:         %s\n", strings.Join(toolsCfg.Allow, ", "))
+	}
+	if len(toolsCfg.Deny) > 0 {
+		fmt.Printf("  Deny:          %s\n", strings.Join(toolsCfg.Deny, ", "))
+	}
+	if len(toolsCfg.DenyServices) > 0 {
+		fmt.Printf("  Deny services: %s\n", strings.Join(toolsCfg.DenyServices, ", "))
+	}
+	if len(toolsCfg.Confirm) > 0 {
+		fmt.Printf("  Confirm:       %s\n", strings.Join(toolsCfg.Confirm, ", "))
+	}
+	if len(toolsCfg.ConfirmServices) > 0 {
+		fmt.Printf("  Confirm services: %s\n", strings.Join(toolsCfg.ConfirmServices, ", "))
+	}
+	if len(toolsCfg.Toolsets) > 0 {
+		fmt.Printf("  Toolsets:      %s\n", strings.Join(toolsCfg.Toolsets, ", "))
+	} else {
+		fmt.Println("  Toolsets:      all")
+	}
+}
+
+// printAuditConfig prints the audit log section of the config command.
+func printAuditConfig(auditCfg *config.AuditConfig) {
+	fmt.Println("Audit log:")
+	if auditCfg.File == "" {
+		fmt.Println("  Disabled (no file configured)")
+	} else {
+		fmt.Printf("  File:        %s\n", auditCfg.File)
+		fmt.Printf("  Max size:    %d MB\n", auditCfg.MaxSizeMB)
+		fmt.Printf("  Max backups: %d\n", auditCfg.MaxBackups)
+	}
+}
+
+// formatRateLimit describes a rate limit for the config command.
+func formatRateLimit(limit config.RateLimit) string {
+	if limit.Rate <= 0 {
+		return "disabled"
+	}
+	return fmt.Sprintf("%g/s (burst %d)", limit.Rate, limit.Burst)
+}
+
 // Execute runs the CLI application.
 func (a *App) Execute() error {
 	return a.rootCmd.Execute()
@@ -218,8 +319,32 @@ func (a *App) run(_ *cobra.Command, _ []string) error {
 	}
 	defer a.closeHomeAssistantClient(haClient, logger)
 
-	mcpServer := a.initMCPServer(haClient, cfg.Server.Port, logger)
-	a.startMCPServer(mcpServer, logger, cancel)
+	mcpServer, err := a.initMCPServer(haClient, cfg.Server.Port, &cfg.Tools, logger)
+	if err != nil {
+		return err
+	}
+	mcpServer.SetRedactedValues(cfg.Secrets()...)
+	mcpServer.SetPageSize(cfg.Server.PageSize)
+	a.configureRateLimits(mcpServer, &cfg.Server.RateLimit, logger)
+	if cfg.Audit.File != "" {
+		auditLog, err := a.openAuditLog(&cfg.Audit, logger)
+		if err != nil {
+			return err
+		}
+		defer a.closeAuditLog(auditLog, logger)
+		mcpServer.SetAuditLog(auditLog)
+	}
+	if cfg.Server.Transport == config.TransportStdio {
+		a.startStdioServer(ctx, mcpServer, logger, cancel)
+	} else {
+		if err := a.configureAuth(mcpServer, &cfg.Server.Auth, logger); err != nil {
+			return err
+		}
+		if cfg.Server.Metrics {
+			a.configureMetrics(mcpServer, haClient, logger)
+		}
+		a.startMCPServer(mcpServer, logger, cancel)
+	}
 
 	<-ctx.Done()
 	logger.Info("Shutdown complete")
@@ -235,10 +360,16 @@ func (a *App) setupLogger(cfg *config.Config) *logging.Logger {
 		logLevel = logging.LevelInfo
 	}
 
-	logger := logging.New(logLevel)
+	// stdout carries the protocol stream in stdio mode, so logs must go to stderr
+	logOut := io.Writer(os.Stdout)
+	if cfg.Server.Transport == config.TransportStdio {
+		logOut = os.Stderr
+	}
+
+	logger := logging.NewWithWriter(logLevel, logOut)
 	logging.SetDefault(logger)
 
-	logger.Info("Starting ha-mcp server", "port", cfg.Server.Port)
+	logger.Info("Starting ha-mcp server", "port", cfg.Server.Port, "transport", cfg.Server.Transport)
 	logger.Info("Home Assistant URL", "url", cfg.HomeAssistant.URL)
 	logger.Info("Log level", "level", logging.LevelString(logLevel))
 
@@ -269,16 +400,38 @@ func (a *App) initHomeAssistantClient(
 ) (homeassistant.Client, error) {
 	logger.Info("Connecting to Home Assistant WebSocket API...")
 
-	haClient, err := homeassistant.NewDefaultWSClient(ctx, cfg.HomeAssistant.URL, cfg.HomeAssistant.Token)
+	wsConfig := homeassistant.DefaultWSClientConfig()
+	wsConfig.CommandTimeout = time.Duration(cfg.HomeAssistant.Timeout) * time.Second
+	wsConfig.CommandRetries = cfg.HomeAssistant.Retries
+
+	haClient, err := homeassistant.NewConnectedWSClient(ctx, cfg.HomeAssistant.URL, cfg.HomeAssistant.Token, &wsConfig)
 	if err != nil {
 		return nil, fmt.Errorf("connecting to Home Assistant: %w", err)
 	}
 
 	logger.Info("Connected to Home Assistant WebSocket API")
 
+	if cfg.HomeAssistant.StateCache {
+		a.enableStateCache(ctx, haClient, logger)
+	}
+
 	return haClient, nil
 }
 
+// enableStateCache serves entity states from memory. Without the cache every
+// call fetches the states, so a failure is logged rather than fatal.
+func (a *App) enableStateCache(ctx context.Context, haClient homeassistant.Client, logger *logging.Logger) {
+	cacher, ok := haClient.(homeassistant.StateCacher)
+	if !ok {
+		return
+	}
+	if err := cacher.EnableStateCache(ctx); err != nil {
+		logger.Warn("State cache disabled", "error", err)
+		return
+	}
+	logger.Info("State cache enabled")
+}
+
 // closeHomeAssistantClient gracefully closes the Home Assistant WebSocket connection.
 func (a *App) closeHomeAssistantClient(client homeassistant.Client, logger *logging.Logger) {
 	logger.Info("Closing Home Assistant WebSocket connection...")
@@ -288,19 +441,115 @@ func (a *App) closeHomeAssistantClient(client homeassistant.Client, logger *logg
 	}
 }
 
-// initMCPServer creates and configures the MCP server with all registered tools.
+// initMCPServer creates and configures the MCP server with all registered tools, resources, and prompts.
 func (a *App) initMCPServer(
 	haClient homeassistant.Client,
 	port int,
+	toolsCfg *config.ToolsConfig,
 	logger *logging.Logger,
-) *mcp.Server {
+) (*mcp.Server, error) {
 	registry := mcp.NewRegistry()
+	if toolsCfg.IsRestricted() {
+		registry.SetToolFilter(&handlers.ToolPolicy{
+			ReadOnly:     toolsCfg.ReadOnly,
+			Allow:        toolsCfg.Allow,
+			Deny:         toolsCfg.Deny,
+			DenyServices: toolsCfg.DenyServices,
+		})
+	}
 	handlers.RegisterAllTools(registry)
+	if err := registry.SetDefaultToolsets(toolsCfg.Toolsets); err != nil {
+		return nil, fmt.Errorf("configuring tools.toolsets: %w", err)
+	}
+	handlers.RegisterResources(registry)
+	handlers.RegisterPrompts(registry)
 
-	logger.Info("Registered MCP tools", "count", registry.ToolCount())
+	logger.Info("Registered MCP tools", "count", registry.ToolCount(),
+		"resource_templates", registry.ResourceTemplateCount(), "prompts", registry.PromptCount())
 	registry.LogRegisteredTools(logger)
 
-	return mcp.NewServer(haClient, registry, port, logger)
+	server := mcp.NewServer(haClient, registry, port, logger)
+	if toolsCfg.NeedsConfirmation() {
+		server.SetConfirmationPolicy(&handlers.ConfirmPolicy{
+			Tools:    toolsCfg.Confirm,
+			Services: toolsCfg.ConfirmServices,
+		})
+	}
+	if toolsCfg.DryRun {
+		server.SetDryRun(true)
+		logger.Info("Dry run enabled: tool calls will not change Home Assistant")
+	}
+	return server, nil
+}
+
+// configureAuth enables bearer-token authentication on the MCP server if API keys are configured.
+func (a *App) configureAuth(server *mcp.Server, authCfg *config.AuthConfig, logger *logging.Logger) error {
+	keys, err := authCfg.ResolveKeys()
+	if err != nil {
+		return fmt.Errorf("loading API keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	mcpKeys := make([]mcp.APIKey, len(keys))
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		mcpKeys[i] = mcp.APIKey{Name: k.Name, Key: k.Key}
+		names[i] = k.Name
+	}
+
+	server.SetAuthenticator(mcp.NewAuthenticator(mcpKeys), authCfg.ProtectHealth)
+	logger.Info("API key authentication enabled", "keys", names, "protect_health", authCfg.ProtectHealth)
+
+	return nil
+}
+
+// configureRateLimits applies the configured tool call rate limits to the MCP server.
+func (a *App) configureRateLimits(server *mcp.Server, rlCfg *config.RateLimitConfig, logger *logging.Logger) {
+	tools := make(map[string]mcp.RateLimit, len(rlCfg.Tools))
+	for name, limit := range rlCfg.Tools {
+		tools[name] = mcp.RateLimit{Rate: limit.Rate, Burst: limit.Burst}
+	}
+
+	server.SetRateLimits(mcp.RateLimits{
+		Client:                mcp.RateLimit{Rate: rlCfg.Client.Rate, Burst: rlCfg.Client.Burst},
+		Tool:                  mcp.RateLimit{Rate: rlCfg.Tool.Rate, Burst: rlCfg.Tool.Burst},
+		Tools:                 tools,
+		MaxConcurrentCommands: rlCfg.MaxConcurrentCommands,
+	})
+	logger.Info("Tool call limits",
+		"client_rate", rlCfg.Client.Rate, "tool_rate", rlCfg.Tool.Rate,
+		"tool_overrides", len(tools), "max_concurrent_commands", rlCfg.MaxConcurrentCommands)
+}
+
+// configureMetrics serves the metrics of tool calls and of the Home Assistant
+// connection at /metrics.
+func (a *App) configureMetrics(server *mcp.Server, haClient homeassistant.Client, logger *logging.Logger) {
+	reg := metrics.NewRegistry()
+	if reporter, ok := haClient.(homeassistant.MetricsReporter); ok {
+		reporter.RegisterMetrics(reg)
+	}
+	server.SetMetrics(reg)
+	logger.Info("Prometheus metrics enabled", "path", "/metrics")
+}
+
+// openAuditLog opens the configured audit log of mutating tool calls.
+func (a *App) openAuditLog(auditCfg *config.AuditConfig, logger *logging.Logger) (*mcp.AuditLog, error) {
+	auditLog, err := mcp.OpenAuditLog(auditCfg.File, int64(auditCfg.MaxSizeMB)*1024*1024, auditCfg.MaxBackups)
+	if err != nil {
+		return nil, fmt.Errorf("configuring audit.file: %w", err)
+	}
+	logger.Info("Audit log enabled", "file", auditCfg.File,
+		"max_size_mb", auditCfg.MaxSizeMB, "max_backups", auditCfg.MaxBackups)
+	return auditLog, nil
+}
+
+// closeAuditLog closes the audit log on shutdown.
+func (a *App) closeAuditLog(auditLog *mcp.AuditLog, logger *logging.Logger) {
+	if err := auditLog.Close(); err != nil {
+		logger.Error("Error closing audit log", "error", err)
+	}
 }
 
 // startMCPServer starts the MCP server in a goroutine.
@@ -312,3 +561,14 @@ func (a *App) startMCPServer(server *mcp.Server, logger *logging.Logger, cancel
 		}
 	}()
 }
+
+// startStdioServer serves MCP over stdin/stdout in a goroutine.
+// The server shuts down when stdin is closed, since the client process has gone away.
+func (a *App) startStdioServer(ctx context.Context, server *mcp.Server, logger *logging.Logger, cancel context.CancelFunc) {
+	go func() {
+		defer cancel()
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("MCP stdio server error", "error", err)
+		}
+	}()
+}
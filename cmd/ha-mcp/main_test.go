@@ -69,6 +69,7 @@ func TestSetupFlags(t *testing.T) {
 		{"ha-url flag", "ha-url"},
 		{"ha-token flag", "ha-token"},
 		{"port flag", "port"},
+		{"transport flag", "transport"},
 	}
 
 	for _, tt := range tests {
@@ -518,6 +519,7 @@ func TestFlagDescriptions(t *testing.T) {
 		{"ha-url", true},
 		{"ha-token", true},
 		{"port", true},
+		{"transport", true},
 	}
 
 	for _, tt := range tests {
@@ -3,13 +3,17 @@
 package config
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"path"
 	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
+
+	"github.com/zorak1103/ha-mcp/internal/logging"
 )
 
 var loadEnvOnce sync.Once
@@ -42,6 +46,30 @@ type Config struct {
 	HomeAssistant HomeAssistantConfig `mapstructure:"homeassistant"`
 	Server        ServerConfig        `mapstructure:"server"`
 	Logging       LoggingConfig       `mapstructure:"logging"`
+	Tools         ToolsConfig         `mapstructure:"tools"`
+	Audit         AuditConfig         `mapstructure:"audit"`
+}
+
+// AuditConfig holds audit log settings. The audit log records every tool
+// call that can change Home Assistant state as one JSON line.
+type AuditConfig struct {
+	// File is the path of the audit log. Empty disables the audit log.
+	File string `mapstructure:"file"`
+	// MaxSizeMB is the size in megabytes at which the file is rotated.
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int `mapstructure:"max_backups"`
+}
+
+// validate checks that the rotation settings are usable.
+func (a *AuditConfig) validate() error {
+	if a.MaxSizeMB < 0 {
+		return fmt.Errorf("audit.max_size_mb must not be negative")
+	}
+	if a.MaxBackups < 0 {
+		return fmt.Errorf("audit.max_backups must not be negative")
+	}
+	return nil
 }
 
 // LoggingConfig holds logging settings.
@@ -49,15 +77,286 @@ type LoggingConfig struct {
 	Level string `mapstructure:"level"`
 }
 
+// Default operations that need the user's confirmation: deleting
+// automations, scripts, scenes, and helpers, unlocking locks, disarming
+// alarms, and opening or moving covers.
+var (
+	DefaultConfirmTools    = []string{"delete_*"}
+	DefaultConfirmServices = []string{
+		"lock.unlock",
+		"lock.open",
+		"alarm_control_panel.alarm_disarm",
+		"cover.open_*",
+		"cover.toggle",
+		"cover.set_cover_position",
+	}
+)
+
+// ToolsConfig restricts the MCP tools exposed to clients.
+// Patterns use path.Match glob syntax, e.g. "delete_*".
+type ToolsConfig struct {
+	// ReadOnly exposes only tools that do not change Home Assistant state.
+	ReadOnly bool `mapstructure:"read_only"`
+	// Allow lists the tool name patterns to expose. Empty exposes every tool.
+	Allow []string `mapstructure:"allow"`
+	// Deny lists the tool name patterns to hide. It takes precedence over Allow.
+	Deny []string `mapstructure:"deny"`
+	// DenyServices lists the services call_service refuses to call, either as
+	// a bare domain ("lock") or as a "domain.service" pattern ("lock.*").
+	DenyServices []string `mapstructure:"deny_services"`
+	// Toolsets lists the toolsets enabled when a session starts. Clients can
+	// enable others with the enable_toolset tool. Empty enables all toolsets.
+	Toolsets []string `mapstructure:"toolsets"`
+	// DryRun makes every call of a tool that changes Home Assistant state
+	// return the requests it would send instead of sending them.
+	DryRun bool `mapstructure:"dry_run"`
+	// Confirm lists the tool name patterns whose calls need the user's
+	// confirmation before they run.
+	Confirm []string `mapstructure:"confirm"`
+	// ConfirmServices lists the services call_service needs the user's
+	// confirmation for, as a bare domain or a "domain.service" pattern.
+	ConfirmServices []string `mapstructure:"confirm_services"`
+}
+
+// NeedsConfirmation reports whether any call needs the user's confirmation.
+func (t *ToolsConfig) NeedsConfirmation() bool {
+	return len(t.Confirm) > 0 || len(t.ConfirmServices) > 0
+}
+
+// IsRestricted reports whether any tool restriction is configured.
+func (t *ToolsConfig) IsRestricted() bool {
+	return t.ReadOnly || len(t.Allow) > 0 || len(t.Deny) > 0 || len(t.DenyServices) > 0
+}
+
+// validate checks that all patterns are well-formed.
+func (t *ToolsConfig) validate() error {
+	lists := []struct {
+		key      string
+		patterns []string
+	}{
+		{"tools.allow", t.Allow},
+		{"tools.deny", t.Deny},
+		{"tools.deny_services", t.DenyServices},
+		{"tools.confirm", t.Confirm},
+		{"tools.confirm_services", t.ConfirmServices},
+	}
+	for _, list := range lists {
+		for _, pattern := range list.patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s: invalid pattern %q", list.key, pattern)
+			}
+		}
+	}
+	return nil
+}
+
 // HomeAssistantConfig holds Home Assistant connection settings.
 type HomeAssistantConfig struct {
 	URL   string `mapstructure:"url"`
 	Token string `mapstructure:"token"`
+	// Timeout is the number of seconds to wait for the response to a
+	// command (0 = no limit).
+	Timeout int `mapstructure:"timeout"`
+	// Retries is how often a read-only command is sent again when the
+	// connection is lost before it is answered.
+	Retries int `mapstructure:"retries"`
+	// StateCache serves entity states from memory, kept current by
+	// state_changed events, instead of fetching them for every call.
+	StateCache bool `mapstructure:"state_cache"`
 }
 
+// Supported MCP transports.
+const (
+	// TransportHTTP serves JSON-RPC over HTTP POST on the configured port.
+	TransportHTTP = "http"
+	// TransportStdio serves newline-delimited JSON-RPC over stdin/stdout.
+	TransportStdio = "stdio"
+)
+
 // ServerConfig holds MCP server settings.
 type ServerConfig struct {
-	Port int `mapstructure:"port"`
+	Port      int             `mapstructure:"port"`
+	Transport string          `mapstructure:"transport"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	// PageSize is the number of tools and resources per page of tools/list
+	// and resources/list (0 = everything in one page).
+	PageSize int `mapstructure:"page_size"`
+	// Metrics serves Prometheus metrics at /metrics on the HTTP transport.
+	Metrics bool `mapstructure:"metrics"`
+}
+
+// RateLimitConfig holds tool call rate limits. Limits are token buckets that
+// refill at Rate calls per second up to Burst calls; a Rate of 0 disables a limit.
+type RateLimitConfig struct {
+	// Client limits all tool calls of one client, identified by its API
+	// key, across all of its MCP sessions. Without authentication all
+	// clients count as one and share this budget.
+	Client RateLimit `mapstructure:"client"`
+	// Tool limits the calls of each tool by one client.
+	Tool RateLimit `mapstructure:"tool"`
+	// Tools overrides Tool for individual tools, keyed by tool name.
+	Tools map[string]RateLimit `mapstructure:"tools"`
+	// MaxConcurrentCommands caps the requests running against Home Assistant
+	// at the same time across all clients (0 = unlimited).
+	MaxConcurrentCommands int `mapstructure:"max_concurrent_commands"`
+}
+
+// RateLimit configures a token bucket.
+type RateLimit struct {
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+}
+
+// validate checks that all limits are non-negative.
+func (r *RateLimitConfig) validate() error {
+	limits := map[string]RateLimit{
+		"server.rate_limit.client": r.Client,
+		"server.rate_limit.tool":   r.Tool,
+	}
+	for name, limit := range r.Tools {
+		limits["server.rate_limit.tools."+name] = limit
+	}
+	for key, limit := range limits {
+		if limit.Rate < 0 || limit.Burst < 0 {
+			return fmt.Errorf("%s: rate and burst must not be negative", key)
+		}
+	}
+	if r.MaxConcurrentCommands < 0 {
+		return fmt.Errorf("server.rate_limit.max_concurrent_commands must not be negative")
+	}
+	return nil
+}
+
+// AuthConfig holds bearer-token authentication settings for the HTTP transport.
+// Authentication is enabled when at least one key is configured.
+type AuthConfig struct {
+	// Keys is a literal list of accepted API keys.
+	Keys []APIKey `mapstructure:"keys"`
+	// KeysFile is the path to a file with one "name:key" entry per line.
+	KeysFile string `mapstructure:"keys_file"`
+	// ProtectHealth requires authentication for the /health and /metrics endpoints as well.
+	ProtectHealth bool `mapstructure:"protect_health"`
+}
+
+// APIKey is a named bearer token accepted by the MCP HTTP endpoint.
+// The name identifies the client in log lines; the key is the secret.
+type APIKey struct {
+	Name string `mapstructure:"name"`
+	Key  string `mapstructure:"key"`
+}
+
+// ResolveKeys returns the configured keys followed by the keys read from KeysFile.
+// Every key must have a name and a value, and names must be unique.
+func (a *AuthConfig) ResolveKeys() ([]APIKey, error) {
+	keys := append([]APIKey(nil), a.Keys...)
+
+	if a.KeysFile != "" {
+		fileKeys, err := readKeysFile(a.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for i, k := range keys {
+		if k.Name == "" {
+			return nil, fmt.Errorf("server.auth: key #%d has no name", i+1)
+		}
+		if k.Key == "" {
+			return nil, fmt.Errorf("server.auth: key %q has no value", k.Name)
+		}
+		if seen[k.Name] {
+			return nil, fmt.Errorf("server.auth: duplicate key name %q", k.Name)
+		}
+		seen[k.Name] = true
+	}
+
+	return keys, nil
+}
+
+// readKeysFile reads API keys from a file with one "name:key" entry per line.
+// Blank lines and lines starting with # are ignored.
+func readKeysFile(path string) ([]APIKey, error) {
+	f, err := os.Open(path) //nolint:gosec // Path comes from trusted configuration
+	if err != nil {
+		return nil, fmt.Errorf("reading server.auth.keys_file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var keys []APIKey
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, key, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("server.auth.keys_file line %d: expected \"name:key\"", lineNum)
+		}
+		keys = append(keys, APIKey{Name: strings.TrimSpace(name), Key: strings.TrimSpace(key)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading server.auth.keys_file: %w", err)
+	}
+
+	return keys, nil
+}
+
+// setDefaults sets the default values of the config keys.
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("homeassistant.url", "http://homeassistant.local:8123")
+	v.SetDefault("homeassistant.token", "")
+	v.SetDefault("homeassistant.timeout", 30)
+	v.SetDefault("homeassistant.retries", 2)
+	v.SetDefault("homeassistant.state_cache", false)
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.transport", TransportHTTP)
+	v.SetDefault("server.auth.keys_file", "")
+	v.SetDefault("server.auth.protect_health", false)
+	v.SetDefault("logging.level", "INFO")
+	v.SetDefault("server.rate_limit.max_concurrent_commands", 0)
+	v.SetDefault("server.page_size", 0)
+	v.SetDefault("server.metrics", true)
+	v.SetDefault("tools.read_only", false)
+	v.SetDefault("tools.dry_run", false)
+	v.SetDefault("tools.confirm", DefaultConfirmTools)
+	v.SetDefault("tools.confirm_services", DefaultConfirmServices)
+	v.SetDefault("audit.file", "")
+	v.SetDefault("audit.max_size_mb", 10)
+	v.SetDefault("audit.max_backups", 5)
+}
+
+// bindEnvVars binds the environment variables to their config keys.
+func bindEnvVars(v *viper.Viper) {
+	mustBindEnv(v, "homeassistant.url", "HA_URL")
+	mustBindEnv(v, "homeassistant.token", "HA_TOKEN")
+	mustBindEnv(v, "homeassistant.timeout", "HA_MCP_TIMEOUT")
+	mustBindEnv(v, "homeassistant.retries", "HA_MCP_RETRIES")
+	mustBindEnv(v, "homeassistant.state_cache", "HA_MCP_STATE_CACHE")
+	mustBindEnv(v, "server.port", "HA_MCP_PORT")
+	mustBindEnv(v, "server.transport", "HA_MCP_TRANSPORT")
+	mustBindEnv(v, "server.auth.keys_file", "HA_MCP_AUTH_KEYS_FILE")
+	mustBindEnv(v, "server.auth.protect_health", "HA_MCP_AUTH_PROTECT_HEALTH")
+	mustBindEnv(v, "logging.level", "HA_MCP_LOG_LEVEL")
+	mustBindEnv(v, "server.rate_limit.client.rate", "HA_MCP_RATE_LIMIT_CLIENT_RATE")
+	mustBindEnv(v, "server.rate_limit.client.burst", "HA_MCP_RATE_LIMIT_CLIENT_BURST")
+	mustBindEnv(v, "server.rate_limit.tool.rate", "HA_MCP_RATE_LIMIT_TOOL_RATE")
+	mustBindEnv(v, "server.rate_limit.tool.burst", "HA_MCP_RATE_LIMIT_TOOL_BURST")
+	mustBindEnv(v, "server.rate_limit.max_concurrent_commands", "HA_MCP_MAX_CONCURRENT_COMMANDS")
+	mustBindEnv(v, "server.page_size", "HA_MCP_PAGE_SIZE")
+	mustBindEnv(v, "server.metrics", "HA_MCP_METRICS")
+	mustBindEnv(v, "tools.read_only", "HA_MCP_TOOLS_READ_ONLY")
+	mustBindEnv(v, "tools.toolsets", "HA_MCP_TOOLSETS")
+	mustBindEnv(v, "tools.dry_run", "HA_MCP_DRY_RUN")
+	mustBindEnv(v, "audit.file", "HA_MCP_AUDIT_FILE")
+	mustBindEnv(v, "audit.max_size_mb", "HA_MCP_AUDIT_MAX_SIZE_MB")
+	mustBindEnv(v, "audit.max_backups", "HA_MCP_AUDIT_MAX_BACKUPS")
 }
 
 // setupViper creates and configures a new viper instance with defaults and environment bindings.
@@ -67,11 +366,7 @@ func setupViper(configFile string) (*viper.Viper, error) {
 
 	v := viper.New()
 
-	// Set defaults
-	v.SetDefault("homeassistant.url", "http://homeassistant.local:8123")
-	v.SetDefault("homeassistant.token", "")
-	v.SetDefault("server.port", 8080)
-	v.SetDefault("logging.level", "INFO")
+	setDefaults(v)
 
 	// Load from config file if specified
 	if configFile != "" {
@@ -86,11 +381,7 @@ func setupViper(configFile string) (*viper.Viper, error) {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
-	// Bind specific env vars to config keys
-	mustBindEnv(v, "homeassistant.url", "HA_URL")
-	mustBindEnv(v, "homeassistant.token", "HA_TOKEN")
-	mustBindEnv(v, "server.port", "HA_MCP_PORT")
-	mustBindEnv(v, "logging.level", "HA_MCP_LOG_LEVEL")
+	bindEnvVars(v)
 
 	return v, nil
 }
@@ -137,11 +428,7 @@ func BindFlags(v *viper.Viper, haURL, haToken string, port int) {
 func LoadWithViper(v *viper.Viper, configFile string) (*Config, error) {
 	loadDotEnv()
 
-	// Set defaults
-	v.SetDefault("homeassistant.url", "http://homeassistant.local:8123")
-	v.SetDefault("homeassistant.token", "")
-	v.SetDefault("server.port", 8080)
-	v.SetDefault("logging.level", "INFO")
+	setDefaults(v)
 
 	// Load from config file if specified
 	if configFile != "" {
@@ -156,11 +443,7 @@ func LoadWithViper(v *viper.Viper, configFile string) (*Config, error) {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
-	// Bind specific env vars to config keys
-	mustBindEnv(v, "homeassistant.url", "HA_URL")
-	mustBindEnv(v, "homeassistant.token", "HA_TOKEN")
-	mustBindEnv(v, "server.port", "HA_MCP_PORT")
-	mustBindEnv(v, "logging.level", "HA_MCP_LOG_LEVEL")
+	bindEnvVars(v)
 
 	// Unmarshal into struct
 	cfg := &Config{}
@@ -200,15 +483,33 @@ func (c *Config) MaskedConfig() Config {
 	if masked.HomeAssistant.Token != "" {
 		masked.HomeAssistant.Token = maskToken(masked.HomeAssistant.Token)
 	}
+	if len(masked.Server.Auth.Keys) > 0 {
+		keys := make([]APIKey, len(masked.Server.Auth.Keys))
+		for i, k := range masked.Server.Auth.Keys {
+			keys[i] = APIKey{Name: k.Name, Key: maskToken(k.Key)}
+		}
+		masked.Server.Auth.Keys = keys
+	}
 	return masked
 }
 
+// Secrets returns the secret values in the configuration: the Home Assistant
+// token and all API keys. Keys that cannot be loaded are skipped.
+func (c *Config) Secrets() []string {
+	var secrets []string
+	if c.HomeAssistant.Token != "" {
+		secrets = append(secrets, c.HomeAssistant.Token)
+	}
+	keys, _ := c.Server.Auth.ResolveKeys()
+	for _, k := range keys {
+		secrets = append(secrets, k.Key)
+	}
+	return secrets
+}
+
 // maskToken masks a token, showing only the first 4 and last 4 characters.
 func maskToken(token string) string {
-	if len(token) <= 8 {
-		return "****"
-	}
-	return token[:4] + "****" + token[len(token)-4:]
+	return logging.MaskToken(token)
 }
 
 // validate checks that all required configuration is present.
@@ -219,8 +520,34 @@ func (c *Config) validate() error {
 	if c.HomeAssistant.Token == "" {
 		return fmt.Errorf("homeassistant.token is required (set via HA_TOKEN env var, --ha-token flag, or config file)")
 	}
+	if c.HomeAssistant.Timeout < 0 {
+		return fmt.Errorf("homeassistant.timeout must not be negative")
+	}
+	if c.HomeAssistant.Retries < 0 {
+		return fmt.Errorf("homeassistant.retries must not be negative")
+	}
 	if c.Server.Port <= 0 || c.Server.Port > 65535 {
 		return fmt.Errorf("server.port must be between 1 and 65535")
 	}
+	switch c.Server.Transport {
+	case "", TransportHTTP, TransportStdio:
+	default:
+		return fmt.Errorf("server.transport must be %q or %q", TransportHTTP, TransportStdio)
+	}
+	if c.Server.PageSize < 0 {
+		return fmt.Errorf("server.page_size must not be negative")
+	}
+	if _, err := c.Server.Auth.ResolveKeys(); err != nil {
+		return err
+	}
+	if err := c.Server.RateLimit.validate(); err != nil {
+		return err
+	}
+	if err := c.Tools.validate(); err != nil {
+		return err
+	}
+	if err := c.Audit.validate(); err != nil {
+		return err
+	}
 	return nil
 }
@@ -483,6 +483,40 @@ func TestMaskedConfig(t *testing.T) {
 	}
 }
 
+func TestMaskedConfig_AuthKeys(t *testing.T) {
+	cfg := Config{
+		Server: ServerConfig{
+			Auth: AuthConfig{
+				Keys: []APIKey{{Name: "laptop", Key: "abcdefghijklmnopqrstuvwxyz"}},
+			},
+		},
+	}
+
+	masked := cfg.MaskedConfig()
+
+	want := []APIKey{{Name: "laptop", Key: "abcd****wxyz"}}
+	if diff := cmp.Diff(want, masked.Server.Auth.Keys); diff != "" {
+		t.Errorf("MaskedConfig().Server.Auth.Keys mismatch (-want +got):\n%s", diff)
+	}
+	if cfg.Server.Auth.Keys[0].Key != "abcdefghijklmnopqrstuvwxyz" {
+		t.Error("MaskedConfig() modified the original keys")
+	}
+}
+
+func TestConfig_Secrets(t *testing.T) {
+	cfg := Config{
+		HomeAssistant: HomeAssistantConfig{Token: "ha-token"},
+		Server: ServerConfig{
+			Auth: AuthConfig{Keys: []APIKey{{Name: "laptop", Key: "laptop-secret"}}},
+		},
+	}
+
+	want := []string{"ha-token", "laptop-secret"}
+	if diff := cmp.Diff(want, cfg.Secrets()); diff != "" {
+		t.Errorf("Secrets() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestMaskToken(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -639,6 +673,138 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "stdio transport",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:   "http://test.local:8123",
+					Token: "valid-token",
+				},
+				Server:  ServerConfig{Port: 8080, Transport: TransportStdio},
+				Logging: LoggingConfig{Level: "info"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown transport",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:   "http://test.local:8123",
+					Token: "valid-token",
+				},
+				Server:  ServerConfig{Port: 8080, Transport: "grpc"},
+				Logging: LoggingConfig{Level: "info"},
+			},
+			wantErr:    true,
+			errContain: "server.transport must be",
+		},
+		{
+			name: "valid tool patterns",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:   "http://test.local:8123",
+					Token: "valid-token",
+				},
+				Server: ServerConfig{Port: 8080},
+				Tools: ToolsConfig{
+					Allow:        []string{"get_*", "list_*"},
+					Deny:         []string{"delete_*"},
+					DenyServices: []string{"lock", "alarm_control_panel.*"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative rate limit",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:   "http://test.local:8123",
+					Token: "valid-token",
+				},
+				Server: ServerConfig{
+					Port:      8080,
+					RateLimit: RateLimitConfig{Tools: map[string]RateLimit{"call_service": {Rate: -1}}},
+				},
+			},
+			wantErr:    true,
+			errContain: "server.rate_limit.tools.call_service: rate and burst must not be negative",
+		},
+		{
+			name: "negative concurrency cap",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:   "http://test.local:8123",
+					Token: "valid-token",
+				},
+				Server: ServerConfig{Port: 8080, RateLimit: RateLimitConfig{MaxConcurrentCommands: -1}},
+			},
+			wantErr:    true,
+			errContain: "max_concurrent_commands must not be negative",
+		},
+		{
+			name: "negative timeout",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:     "http://test.local:8123",
+					Token:   "valid-token",
+					Timeout: -1,
+				},
+				Server: ServerConfig{Port: 8080},
+			},
+			wantErr:    true,
+			errContain: "homeassistant.timeout must not be negative",
+		},
+		{
+			name: "negative retries",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:     "http://test.local:8123",
+					Token:   "valid-token",
+					Retries: -1,
+				},
+				Server: ServerConfig{Port: 8080},
+			},
+			wantErr:    true,
+			errContain: "homeassistant.retries must not be negative",
+		},
+		{
+			name: "negative page size",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:   "http://test.local:8123",
+					Token: "valid-token",
+				},
+				Server: ServerConfig{Port: 8080, PageSize: -1},
+			},
+			wantErr:    true,
+			errContain: "server.page_size must not be negative",
+		},
+		{
+			name: "invalid tool pattern",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:   "http://test.local:8123",
+					Token: "valid-token",
+				},
+				Server: ServerConfig{Port: 8080},
+				Tools:  ToolsConfig{Deny: []string{"delete_["}},
+			},
+			wantErr:    true,
+			errContain: "tools.deny: invalid pattern",
+		},
+		{
+			name: "negative audit size",
+			config: Config{
+				HomeAssistant: HomeAssistantConfig{
+					URL:   "http://test.local:8123",
+					Token: "valid-token",
+				},
+				Server: ServerConfig{Port: 8080},
+				Audit:  AuditConfig{File: "audit.jsonl", MaxSizeMB: -1},
+			},
+			wantErr:    true,
+			errContain: "audit.max_size_mb must not be negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -770,6 +936,24 @@ func TestConfigDefaults(t *testing.T) {
 	if cfg.Logging.Level != "INFO" {
 		t.Errorf("Default Level = %q, want %q", cfg.Logging.Level, "INFO")
 	}
+	if diff := cmp.Diff(DefaultConfirmTools, cfg.Tools.Confirm); diff != "" {
+		t.Errorf("Default Tools.Confirm mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(DefaultConfirmServices, cfg.Tools.ConfirmServices); diff != "" {
+		t.Errorf("Default Tools.ConfirmServices mismatch (-want +got):\n%s", diff)
+	}
+	if !cfg.Server.Metrics {
+		t.Error("Default Server.Metrics = false, want true")
+	}
+	if cfg.HomeAssistant.StateCache {
+		t.Error("Default HomeAssistant.StateCache = true, want false")
+	}
+	if cfg.HomeAssistant.Timeout != 30 {
+		t.Errorf("Default HomeAssistant.Timeout = %d, want %d", cfg.HomeAssistant.Timeout, 30)
+	}
+	if cfg.HomeAssistant.Retries != 2 {
+		t.Errorf("Default HomeAssistant.Retries = %d, want %d", cfg.HomeAssistant.Retries, 2)
+	}
 }
 
 func TestEnvVarOverrides(t *testing.T) {
@@ -780,6 +964,11 @@ func TestEnvVarOverrides(t *testing.T) {
 	t.Setenv("HA_TOKEN", "env-override-token")
 	t.Setenv("HA_MCP_PORT", "3333")
 	t.Setenv("HA_MCP_LOG_LEVEL", "debug")
+	t.Setenv("HA_MCP_PAGE_SIZE", "50")
+	t.Setenv("HA_MCP_METRICS", "false")
+	t.Setenv("HA_MCP_STATE_CACHE", "true")
+	t.Setenv("HA_MCP_TIMEOUT", "10")
+	t.Setenv("HA_MCP_RETRIES", "0")
 
 	cfg, err := Load("")
 	if err != nil {
@@ -798,6 +987,321 @@ func TestEnvVarOverrides(t *testing.T) {
 	if cfg.Logging.Level != "debug" {
 		t.Errorf("Level = %q, want %q", cfg.Logging.Level, "debug")
 	}
+	if cfg.Server.PageSize != 50 {
+		t.Errorf("PageSize = %d, want %d", cfg.Server.PageSize, 50)
+	}
+	if cfg.Server.Metrics {
+		t.Error("Metrics = true with HA_MCP_METRICS=false, want false")
+	}
+	if !cfg.HomeAssistant.StateCache {
+		t.Error("StateCache = false with HA_MCP_STATE_CACHE=true, want true")
+	}
+	if cfg.HomeAssistant.Timeout != 10 {
+		t.Errorf("Timeout = %d, want %d", cfg.HomeAssistant.Timeout, 10)
+	}
+	if cfg.HomeAssistant.Retries != 0 {
+		t.Errorf("Retries = %d, want %d", cfg.HomeAssistant.Retries, 0)
+	}
+}
+
+func TestLoad_Transport(t *testing.T) {
+	resetLoadEnvOnce()
+	clearEnvVars()
+
+	t.Setenv("HA_TOKEN", "test-token-12345678")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Server.Transport != TransportHTTP {
+		t.Errorf("default Transport = %q, want %q", cfg.Server.Transport, TransportHTTP)
+	}
+
+	resetLoadEnvOnce()
+	t.Setenv("HA_MCP_TRANSPORT", "stdio")
+
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Server.Transport != TransportStdio {
+		t.Errorf("Transport = %q, want %q", cfg.Server.Transport, TransportStdio)
+	}
+}
+
+func TestLoadWithConfigFile_Auth(t *testing.T) {
+	resetLoadEnvOnce()
+	clearEnvVars()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+homeassistant:
+  token: "yaml-token-12345678"
+server:
+  auth:
+    protect_health: true
+    keys:
+      - name: "laptop"
+        key: "laptop-secret"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := AuthConfig{
+		Keys:          []APIKey{{Name: "laptop", Key: "laptop-secret"}},
+		ProtectHealth: true,
+	}
+	if diff := cmp.Diff(want, cfg.Server.Auth); diff != "" {
+		t.Errorf("Server.Auth mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadWithConfigFile_RateLimit(t *testing.T) {
+	resetLoadEnvOnce()
+	clearEnvVars()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+homeassistant:
+  token: "yaml-token-12345678"
+server:
+  rate_limit:
+    client:
+      rate: 5
+      burst: 20
+    tool:
+      rate: 1
+    tools:
+      call_service:
+        rate: 0.2
+        burst: 3
+    max_concurrent_commands: 4
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := RateLimitConfig{
+		Client:                RateLimit{Rate: 5, Burst: 20},
+		Tool:                  RateLimit{Rate: 1},
+		Tools:                 map[string]RateLimit{"call_service": {Rate: 0.2, Burst: 3}},
+		MaxConcurrentCommands: 4,
+	}
+	if diff := cmp.Diff(want, cfg.Server.RateLimit); diff != "" {
+		t.Errorf("Server.RateLimit mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadWithConfigFile_Tools(t *testing.T) {
+	resetLoadEnvOnce()
+	clearEnvVars()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+homeassistant:
+  token: "yaml-token-12345678"
+tools:
+  read_only: true
+  deny:
+    - "get_history"
+  deny_services:
+    - "lock"
+    - "alarm_control_panel.*"
+  toolsets:
+    - "core"
+    - "automations"
+  dry_run: true
+  confirm:
+    - "delete_automation"
+  confirm_services: []
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := ToolsConfig{
+		ReadOnly:        true,
+		Deny:            []string{"get_history"},
+		DenyServices:    []string{"lock", "alarm_control_panel.*"},
+		Toolsets:        []string{"core", "automations"},
+		DryRun:          true,
+		Confirm:         []string{"delete_automation"},
+		ConfirmServices: []string{},
+	}
+	if diff := cmp.Diff(want, cfg.Tools); diff != "" {
+		t.Errorf("Tools mismatch (-want +got):\n%s", diff)
+	}
+	if !cfg.Tools.IsRestricted() {
+		t.Error("IsRestricted() = false, want true")
+	}
+
+	resetLoadEnvOnce()
+	clearEnvVars()
+	t.Setenv("HA_TOKEN", "test-token-12345678")
+	t.Setenv("HA_MCP_TOOLS_READ_ONLY", "true")
+	t.Setenv("HA_MCP_TOOLSETS", "core,media")
+	t.Setenv("HA_MCP_DRY_RUN", "true")
+
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Tools.ReadOnly {
+		t.Error("Tools.ReadOnly = false with HA_MCP_TOOLS_READ_ONLY=true, want true")
+	}
+	if diff := cmp.Diff([]string{"core", "media"}, cfg.Tools.Toolsets); diff != "" {
+		t.Errorf("Tools.Toolsets from HA_MCP_TOOLSETS mismatch (-want +got):\n%s", diff)
+	}
+	if !cfg.Tools.DryRun {
+		t.Error("Tools.DryRun = false with HA_MCP_DRY_RUN=true, want true")
+	}
+}
+
+func TestLoadWithConfigFile_Audit(t *testing.T) {
+	resetLoadEnvOnce()
+	clearEnvVars()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+homeassistant:
+  token: "yaml-token-12345678"
+audit:
+  file: "/var/log/ha-mcp/audit.jsonl"
+  max_backups: 2
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	t.Setenv("HA_MCP_AUDIT_MAX_SIZE_MB", "50")
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := AuditConfig{File: "/var/log/ha-mcp/audit.jsonl", MaxSizeMB: 50, MaxBackups: 2}
+	if diff := cmp.Diff(want, cfg.Audit); diff != "" {
+		t.Errorf("Audit mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAuthConfig_ResolveKeys(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	keysFile := filepath.Join(tmpDir, "keys")
+	keysContent := "# MCP clients\nclaude-desktop: desktop-secret\n\nci:ci-secret\n"
+	if err := os.WriteFile(keysFile, []byte(keysContent), 0600); err != nil {
+		t.Fatalf("Failed to write keys file: %v", err)
+	}
+	badFile := filepath.Join(tmpDir, "bad")
+	if err := os.WriteFile(badFile, []byte("no-separator\n"), 0600); err != nil {
+		t.Fatalf("Failed to write keys file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		auth    AuthConfig
+		want    []APIKey
+		wantErr string
+	}{
+		{
+			name: "no keys",
+			auth: AuthConfig{},
+			want: nil,
+		},
+		{
+			name: "literal keys",
+			auth: AuthConfig{Keys: []APIKey{{Name: "laptop", Key: "secret"}}},
+			want: []APIKey{{Name: "laptop", Key: "secret"}},
+		},
+		{
+			name: "literal keys and keys file",
+			auth: AuthConfig{
+				Keys:     []APIKey{{Name: "laptop", Key: "secret"}},
+				KeysFile: keysFile,
+			},
+			want: []APIKey{
+				{Name: "laptop", Key: "secret"},
+				{Name: "claude-desktop", Key: "desktop-secret"},
+				{Name: "ci", Key: "ci-secret"},
+			},
+		},
+		{
+			name:    "missing name",
+			auth:    AuthConfig{Keys: []APIKey{{Key: "secret"}}},
+			wantErr: "has no name",
+		},
+		{
+			name:    "missing key",
+			auth:    AuthConfig{Keys: []APIKey{{Name: "laptop"}}},
+			wantErr: "has no value",
+		},
+		{
+			name: "duplicate name",
+			auth: AuthConfig{
+				Keys:     []APIKey{{Name: "ci", Key: "other"}},
+				KeysFile: keysFile,
+			},
+			wantErr: "duplicate key name",
+		},
+		{
+			name:    "missing keys file",
+			auth:    AuthConfig{KeysFile: filepath.Join(tmpDir, "missing")},
+			wantErr: "keys_file",
+		},
+		{
+			name:    "malformed keys file",
+			auth:    AuthConfig{KeysFile: badFile},
+			wantErr: "line 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.auth.ResolveKeys()
+			if tt.wantErr != "" {
+				if err == nil || !containsString(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveKeys() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveKeys() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ResolveKeys() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
 }
 
 func TestConfigStruct(t *testing.T) {
@@ -835,7 +1339,13 @@ func TestConfigStruct(t *testing.T) {
 // Helper functions
 
 func clearEnvVars() {
-	envVars := []string{"HA_URL", "HA_TOKEN", "HA_MCP_PORT", "HA_MCP_LOG_LEVEL"}
+	envVars := []string{"HA_URL", "HA_TOKEN", "HA_MCP_PORT", "HA_MCP_TRANSPORT", "HA_MCP_LOG_LEVEL",
+		"HA_MCP_AUTH_KEYS_FILE", "HA_MCP_AUTH_PROTECT_HEALTH", "HA_MCP_TOOLS_READ_ONLY",
+		"HA_MCP_TOOLSETS", "HA_MCP_RATE_LIMIT_CLIENT_RATE", "HA_MCP_RATE_LIMIT_CLIENT_BURST",
+		"HA_MCP_RATE_LIMIT_TOOL_RATE", "HA_MCP_RATE_LIMIT_TOOL_BURST", "HA_MCP_MAX_CONCURRENT_COMMANDS",
+		"HA_MCP_AUDIT_FILE", "HA_MCP_AUDIT_MAX_SIZE_MB", "HA_MCP_AUDIT_MAX_BACKUPS", "HA_MCP_DRY_RUN",
+		"HA_MCP_PAGE_SIZE", "HA_MCP_METRICS", "HA_MCP_STATE_CACHE",
+		"HA_MCP_TIMEOUT", "HA_MCP_RETRIES"}
 	for _, v := range envVars {
 		_ = os.Unsetenv(v)
 	}
@@ -13,6 +13,10 @@ import (
 	"github.com/zorak1103/ha-mcp/internal/mcp"
 )
 
+// analysisSteps is the number of fixed progress steps of analyze_entity:
+// the state lookup and the five reference searches.
+const analysisSteps = 6
+
 // AnalysisHandlers provides MCP tool handlers for entity analysis operations.
 type AnalysisHandlers struct{}
 
@@ -31,6 +35,7 @@ func (h *AnalysisHandlers) analyzeEntityTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "analyze_entity",
 		Description: "Analyze an entity and find all automations, scripts, and scenes that reference it. Returns a comprehensive overview of how the entity is controlled and used in Home Assistant.",
+		Annotations: readOnlyAnnotations("Analyze Entity"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Parameters for analyzing an entity",
@@ -53,6 +58,7 @@ func (h *AnalysisHandlers) getEntityDependenciesTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_entity_dependencies",
 		Description: "Get all entities that an automation or script depends on. Shows triggers, conditions, and action targets.",
+		Annotations: readOnlyAnnotations("Get Entity Dependencies"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Parameters for getting entity dependencies",
@@ -60,6 +66,7 @@ func (h *AnalysisHandlers) getEntityDependenciesTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The automation or script entity ID (e.g., 'automation.my_automation', 'script.my_script')",
+					Pattern:     `^(automation|script)\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -187,10 +194,19 @@ func (h *AnalysisHandlers) handleAnalyzeEntity(ctx context.Context, client homea
 }
 
 func (h *AnalysisHandlers) buildEntityAnalysis(ctx context.Context, client homeassistant.Client, entityID string, includeHistory bool) (*EntityAnalysis, error) {
+	// One step for the state and one per reference search; the automation
+	// searches add a step per automation once the automations are listed.
+	progress := mcp.ProgressFromContext(ctx)
+	progress.AddTotal(analysisSteps)
+	if includeHistory {
+		progress.AddTotal(1)
+	}
+
 	state, err := client.GetState(ctx, entityID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting entity state: %w", err)
 	}
+	progress.Step("Fetched entity state")
 
 	parts := strings.SplitN(entityID, ".", 2)
 	domain := ""
@@ -215,12 +231,17 @@ func (h *AnalysisHandlers) buildEntityAnalysis(ctx context.Context, client homea
 
 	// Find all references
 	h.findAutomationReferences(ctx, client, entityID, analysis.References)
+	progress.Step("Searched automations")
 	h.findScriptReferences(ctx, client, entityID, analysis.References)
+	progress.Step("Searched scripts")
 	h.findSceneReferences(ctx, client, entityID, analysis.References)
+	progress.Step("Searched scenes")
 	h.findGroupReferences(ctx, client, entityID, analysis.References)
+	progress.Step("Searched groups")
 
 	// Find area-based references (entity controlled via area_id in automations/scripts)
 	h.findAreaReferences(ctx, client, entityID, analysis.References)
+	progress.Step("Searched area references")
 
 	// Calculate total references
 	analysis.References.TotalReferences = len(analysis.References.Automations) +
@@ -232,6 +253,7 @@ func (h *AnalysisHandlers) buildEntityAnalysis(ctx context.Context, client homea
 	// Include history if requested
 	if includeHistory {
 		analysis.History = h.getEntityHistory(ctx, client, entityID)
+		progress.Step("Fetched history")
 	}
 
 	analysis.Summary = h.generateEntitySummary(analysis)
@@ -245,9 +267,13 @@ func (h *AnalysisHandlers) findAutomationReferences(ctx context.Context, client
 		return
 	}
 
+	progress := mcp.ProgressFromContext(ctx)
+	progress.AddTotal(len(automations))
+
 	for _, auto := range automations {
 		autoID := strings.TrimPrefix(auto.EntityID, "automation.")
 		fullAuto, getErr := client.GetAutomation(ctx, autoID)
+		progress.Step("Checked " + auto.EntityID)
 		if getErr != nil || fullAuto.Config == nil {
 			continue
 		}
@@ -357,9 +383,13 @@ func (h *AnalysisHandlers) findAreaReferences(ctx context.Context, client homeas
 		return
 	}
 
+	progress := mcp.ProgressFromContext(ctx)
+	progress.AddTotal(len(automations))
+
 	for _, auto := range automations {
 		autoID := strings.TrimPrefix(auto.EntityID, "automation.")
 		fullAuto, getErr := client.GetAutomation(ctx, autoID)
+		progress.Step("Checked " + auto.EntityID + " for area " + entityArea)
 		if getErr != nil || fullAuto.Config == nil {
 			continue
 		}
@@ -0,0 +1,50 @@
+// Package handlers provides MCP tool handlers for Home Assistant operations.
+package handlers
+
+import "github.com/zorak1103/ha-mcp/internal/mcp"
+
+// readOnlyAnnotations describes a tool that only reads Home Assistant data.
+func readOnlyAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:          title,
+		ReadOnlyHint:   true,
+		IdempotentHint: true,
+	}
+}
+
+// createAnnotations describes a tool that adds a new object. Repeated calls
+// create duplicates or fail, so it is not idempotent.
+func createAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title: title,
+	}
+}
+
+// actionAnnotations describes a tool that changes the state of an existing
+// entity without losing configuration, such as setting a helper value.
+func actionAnnotations(title string, idempotent bool) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:          title,
+		IdempotentHint: idempotent,
+	}
+}
+
+// destructiveAnnotations describes a tool that deletes an object or
+// replaces its configuration.
+func destructiveAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:           title,
+		DestructiveHint: true,
+		IdempotentHint:  true,
+	}
+}
+
+// serviceAnnotations describes a tool that runs arbitrary Home Assistant
+// actions, which may reach any integration and the devices behind it.
+func serviceAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:           title,
+		DestructiveHint: true,
+		OpenWorldHint:   true,
+	}
+}
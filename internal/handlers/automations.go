@@ -34,6 +34,7 @@ func (h *AutomationHandlers) listAutomationsTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "list_automations",
 		Description: "List all automations in Home Assistant. By default returns a compact list. Use filters to narrow down results and 'verbose' for full details including configuration.",
+		Annotations: readOnlyAnnotations("List Automations"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Filter and output options for automations list",
@@ -63,6 +64,7 @@ func (h *AutomationHandlers) getAutomationTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_automation",
 		Description: "Get details of a specific automation",
+		Annotations: readOnlyAnnotations("Get Automation"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Parameters for getting automation details",
@@ -81,6 +83,7 @@ func (h *AutomationHandlers) createAutomationTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_automation",
 		Description: "Create a new automation in Home Assistant",
+		Annotations: createAnnotations("Create Automation"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Automation configuration",
@@ -119,6 +122,7 @@ func (h *AutomationHandlers) updateAutomationTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "update_automation",
 		Description: "Update an existing automation",
+		Annotations: destructiveAnnotations("Update Automation"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Automation ID and updated configuration",
@@ -161,6 +165,7 @@ func (h *AutomationHandlers) deleteAutomationTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_automation",
 		Description: "Delete an automation from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Automation"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Automation ID to delete",
@@ -179,6 +184,7 @@ func (h *AutomationHandlers) toggleAutomationTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "toggle_automation",
 		Description: "Enable or disable an automation",
+		Annotations: actionAnnotations("Enable or Disable Automation", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Automation ID and enabled state",
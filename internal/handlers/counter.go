@@ -33,6 +33,7 @@ func (h *CounterHandlers) createCounterTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_counter",
 		Description: "Create a new counter helper in Home Assistant. A counter can be incremented, decremented, and reset. Useful for tracking occurrences or counts.",
+		Annotations: createAnnotations("Create Counter"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Counter configuration",
@@ -75,6 +76,7 @@ func (h *CounterHandlers) deleteCounterTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_counter",
 		Description: "Delete a counter helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Counter"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Counter entity ID to delete",
@@ -82,6 +84,7 @@ func (h *CounterHandlers) deleteCounterTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the counter (e.g., counter.my_counter)",
+					Pattern:     `^counter\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -93,6 +96,7 @@ func (h *CounterHandlers) incrementCounterTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "increment_counter",
 		Description: "Increment a counter by its step value",
+		Annotations: actionAnnotations("Increment Counter", false),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Counter entity ID to increment",
@@ -100,6 +104,7 @@ func (h *CounterHandlers) incrementCounterTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the counter (e.g., counter.my_counter)",
+					Pattern:     `^counter\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -111,6 +116,7 @@ func (h *CounterHandlers) decrementCounterTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "decrement_counter",
 		Description: "Decrement a counter by its step value",
+		Annotations: actionAnnotations("Decrement Counter", false),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Counter entity ID to decrement",
@@ -118,6 +124,7 @@ func (h *CounterHandlers) decrementCounterTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the counter (e.g., counter.my_counter)",
+					Pattern:     `^counter\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -129,6 +136,7 @@ func (h *CounterHandlers) resetCounterTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "reset_counter",
 		Description: "Reset a counter to its initial value",
+		Annotations: actionAnnotations("Reset Counter", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Counter entity ID to reset",
@@ -136,6 +144,7 @@ func (h *CounterHandlers) resetCounterTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the counter (e.g., counter.my_counter)",
+					Pattern:     `^counter\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -147,6 +156,7 @@ func (h *CounterHandlers) setCounterValueTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "set_counter_value",
 		Description: "Set a counter to a specific value",
+		Annotations: actionAnnotations("Set Counter Value", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Counter entity ID and value to set",
@@ -154,6 +164,7 @@ func (h *CounterHandlers) setCounterValueTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the counter (e.g., counter.my_counter)",
+					Pattern:     `^counter\.`,
 				},
 				"value": {
 					Type:        "number",
@@ -32,6 +32,7 @@ func (h *DerivativeHandlers) createDerivativeTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_derivative",
 		Description: "Create a new derivative helper in Home Assistant. A derivative sensor tracks the rate of change of a source sensor over time.",
+		Annotations: createAnnotations("Create Derivative Sensor"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Derivative sensor configuration",
@@ -80,6 +81,7 @@ func (h *DerivativeHandlers) deleteDerivativeTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_derivative",
 		Description: "Delete a derivative helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Derivative Sensor"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Derivative entity ID to delete",
@@ -87,6 +89,7 @@ func (h *DerivativeHandlers) deleteDerivativeTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the derivative sensor (e.g., sensor.my_derivative)",
+					Pattern:     `^sensor\.`,
 				},
 			},
 			Required: []string{"entity_id"},
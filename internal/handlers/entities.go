@@ -35,11 +35,24 @@ func (h *EntityHandlers) RegisterTools(registry *mcp.Registry) {
 func (h *EntityHandlers) getStatesTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_states",
-		Description: "Get all entity states from Home Assistant. By default returns a compact list with entity_id, state, and friendly_name. Use 'verbose' for full details including all attributes.",
+		Description: "Get all entity states from Home Assistant, sorted by entity_id. By default returns a compact list with entity_id, state, and friendly_name. Use 'verbose' for full details including all attributes. Use 'limit' and 'cursor' to page through large installations.",
+		Annotations: readOnlyAnnotations("Get Entity States"),
+		OutputSchema: &mcp.JSONSchema{
+			Type: "object",
+			Properties: withPageOutputProperties(map[string]mcp.JSONSchema{
+				"count": {Type: "integer", Description: "Number of entities on this page"},
+				"entities": {
+					Type:        "array",
+					Description: "Matching entities in compact or verbose form, sorted by entity_id",
+					Items:       entityStateSchema(),
+				},
+			}, "entities"),
+			Required: []string{"count", "entities"},
+		},
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
-			Description: "Optional filters for entity states",
-			Properties: map[string]mcp.JSONSchema{
+			Description: "Optional filters and paging for entity states",
+			Properties: withPaginationProperties(map[string]mcp.JSONSchema{
 				"domain": {
 					Type:        "string",
 					Description: "Filter by domain (e.g., 'light', 'switch', 'sensor')",
@@ -60,15 +73,17 @@ func (h *EntityHandlers) getStatesTool() mcp.Tool {
 					Type:        "boolean",
 					Description: "If true, return full details (all attributes, timestamps, context). Default: false (compact output with entity_id, state, friendly_name only)",
 				},
-			},
+			}, "entities"),
 		},
 	}
 }
 
 func (h *EntityHandlers) getStateTool() mcp.Tool {
 	return mcp.Tool{
-		Name:        "get_state",
-		Description: "Get the state of a specific entity",
+		Name:         "get_state",
+		Description:  "Get the state of a specific entity",
+		Annotations:  readOnlyAnnotations("Get Entity State"),
+		OutputSchema: entityStateSchema(),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Parameters for getting entity state",
@@ -87,6 +102,32 @@ func (h *EntityHandlers) getHistoryTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_history",
 		Description: "Get historical state changes for an entity. By default returns compact output with state and timestamp. Use 'verbose' for full details.",
+		Annotations: readOnlyAnnotations("Get Entity History"),
+		OutputSchema: &mcp.JSONSchema{
+			Type: "object",
+			Properties: map[string]mcp.JSONSchema{
+				"entity_id":   {Type: "string"},
+				"count":       {Type: "integer", Description: "Number of returned entries"},
+				"total_count": {Type: "integer", Description: "Number of matching entries before the limit was applied"},
+				"next_cursor": {Type: "string", Description: "Cursor of the page of older entries; absent on the last page"},
+				"entries": {
+					Type:        "array",
+					Description: "History entries, oldest first. Compact entries have state and last_changed; verbose entries use the Home Assistant keys s, a, lc, and lu.",
+					Items: &mcp.JSONSchema{
+						Type: "object",
+						Properties: map[string]mcp.JSONSchema{
+							"state":        {Type: "string"},
+							"last_changed": {Type: "string", Description: "RFC3339 timestamp"},
+							"s":            {Type: "string", Description: "State"},
+							"a":            {Type: "object", Description: "Attributes"},
+							"lc":           {Type: "number", Description: "Last changed as Unix timestamp"},
+							"lu":           {Type: "number", Description: "Last updated as Unix timestamp"},
+						},
+					},
+				},
+			},
+			Required: []string{"entity_id", "count", "total_count", "entries"},
+		},
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Parameters for getting entity history",
@@ -106,6 +147,7 @@ func (h *EntityHandlers) getHistoryTool() mcp.Tool {
 				"hours": {
 					Type:        "number",
 					Description: "Number of hours to look back from now (e.g., 6 for last 6 hours). Overrides start_time if specified.",
+					Minimum:     float64Ptr(0),
 				},
 				"state": {
 					Type:        "string",
@@ -113,8 +155,10 @@ func (h *EntityHandlers) getHistoryTool() mcp.Tool {
 				},
 				"limit": {
 					Type:        "integer",
-					Description: "Maximum number of entries to return (most recent first). Default: all entries.",
+					Description: "Maximum number of entries to return, taking the most recent ones. Default: all entries. Use next_cursor to page back to older entries.",
+					Minimum:     float64Ptr(1),
 				},
+				"cursor": cursorProperty(),
 				"verbose": {
 					Type:        "boolean",
 					Description: "If true, return full details (all attributes). Default: false (compact output with state and timestamp only)",
@@ -129,6 +173,7 @@ func (h *EntityHandlers) listDomainsTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "list_domains",
 		Description: "List all available entity domains in Home Assistant",
+		Annotations: readOnlyAnnotations("List Domains"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "No parameters required",
@@ -140,6 +185,7 @@ func (h *EntityHandlers) getEntityDependenciesTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_entity_dependencies",
 		Description: "Find all automations that use a specific entity. Shows where the entity is used as trigger, condition, or action target. Useful for understanding the impact of changing or removing an entity.",
+		Annotations: readOnlyAnnotations("Get Entity Dependencies"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Parameters for finding entity dependencies",
@@ -154,6 +200,40 @@ func (h *EntityHandlers) getEntityDependenciesTool() mcp.Tool {
 	}
 }
 
+// entityStateSchema describes an entity state in compact or verbose output.
+func entityStateSchema() *mcp.JSONSchema {
+	return &mcp.JSONSchema{
+		Type: "object",
+		Properties: map[string]mcp.JSONSchema{
+			"entity_id":     {Type: "string"},
+			"state":         {Type: "string"},
+			"friendly_name": {Type: "string", Description: "Compact output only"},
+			"attributes":    {Type: "object"},
+			"last_changed":  {Type: "string", Description: "RFC3339 timestamp"},
+			"last_updated":  {Type: "string", Description: "RFC3339 timestamp"},
+			"context":       {Type: "object"},
+		},
+		Required: []string{"entity_id", "state"},
+	}
+}
+
+// statesOutput is the structured content of get_states.
+type statesOutput struct {
+	Count      int             `json:"count"`
+	TotalCount int             `json:"total_count"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Entities   json.RawMessage `json:"entities"`
+}
+
+// historyOutput is the structured content of get_history.
+type historyOutput struct {
+	EntityID   string          `json:"entity_id"`
+	Count      int             `json:"count"`
+	TotalCount int             `json:"total_count"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Entries    json.RawMessage `json:"entries"`
+}
+
 // compactEntityState represents a minimal entity state for compact output.
 type compactEntityState struct {
 	EntityID     string `json:"entity_id"`
@@ -269,7 +349,15 @@ func (h *EntityHandlers) handleGetStates(ctx context.Context, client homeassista
 	params := parseStateFilterParams(args)
 	states = filterStates(states, params)
 
-	output, err := formatStatesOutput(states, params.verbose)
+	page, next, err := paginate(states, func(e homeassistant.Entity) string { return e.EntityID }, args)
+	if err != nil {
+		return &mcp.ToolsCallResult{
+			Content: []mcp.ContentBlock{mcp.NewTextContent(fmt.Sprintf("Error paging states: %v", err))},
+			IsError: true,
+		}, nil
+	}
+
+	output, err := formatStatesOutput(page, params.verbose)
 	if err != nil {
 		return &mcp.ToolsCallResult{
 			Content: []mcp.ContentBlock{mcp.NewTextContent(fmt.Sprintf("Error formatting states: %v", err))},
@@ -277,13 +365,20 @@ func (h *EntityHandlers) handleGetStates(ctx context.Context, client homeassista
 		}, nil
 	}
 
-	summary := fmt.Sprintf("Found %d entities", len(states))
+	summary := pageSummary("entities", len(page), len(states))
 	if !params.verbose {
 		summary += VerboseHint
 	}
+	summary += nextPageHint(next)
 
 	return &mcp.ToolsCallResult{
 		Content: []mcp.ContentBlock{mcp.NewTextContent(summary + "\n\n" + string(output))},
+		StructuredContent: statesOutput{
+			Count:      len(page),
+			TotalCount: len(states),
+			NextCursor: next,
+			Entities:   output,
+		},
 	}, nil
 }
 
@@ -313,7 +408,8 @@ func (h *EntityHandlers) handleGetState(ctx context.Context, client homeassistan
 	}
 
 	return &mcp.ToolsCallResult{
-		Content: []mcp.ContentBlock{mcp.NewTextContent(string(output))},
+		Content:           []mcp.ContentBlock{mcp.NewTextContent(string(output))},
+		StructuredContent: state,
 	}, nil
 }
 
@@ -375,9 +471,13 @@ func (h *EntityHandlers) handleGetEntityDependencies(ctx context.Context, client
 func findEntityDependencies(ctx context.Context, client homeassistant.Client, automations []homeassistant.Automation, entityID string) []entityDependency {
 	var dependencies []entityDependency
 
+	progress := mcp.ProgressFromContext(ctx)
+	progress.AddTotal(len(automations))
+
 	for _, auto := range automations {
 		autoID := strings.TrimPrefix(auto.EntityID, "automation.")
 		fullAuto, err := client.GetAutomation(ctx, autoID)
+		progress.Step("Checked " + auto.EntityID)
 		if err != nil {
 			continue
 		}
@@ -508,6 +608,7 @@ type historyParams struct {
 	endTime     time.Time
 	stateFilter string
 	limit       int
+	skip        int // newest matching entries returned on previous pages
 	verbose     bool
 }
 
@@ -515,6 +616,15 @@ type historyParams struct {
 type historyResult struct {
 	entries    []homeassistant.HistoryEntry
 	totalCount int
+	olderCount int // matching entries older than the returned ones
+}
+
+// historyCursor is the position of a get_history cursor. It keeps the time
+// range of the first page, so that later pages are not shifted by new entries.
+type historyCursor struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Skip  int       `json:"skip"`
 }
 
 // parseHistoryParams extracts and validates all parameters from args.
@@ -529,6 +639,15 @@ func parseHistoryParams(args map[string]any) (*historyParams, error) {
 		return nil, err
 	}
 
+	skip := 0
+	if cursor, _ := args[argCursor].(string); cursor != "" {
+		var pos historyCursor
+		if err := mcp.DecodeCursor(cursor, &pos); err != nil {
+			return nil, err
+		}
+		startTime, endTime, skip = pos.Start, pos.End, pos.Skip
+	}
+
 	stateFilter, _ := args["state"].(string)
 
 	limit := 0
@@ -544,6 +663,7 @@ func parseHistoryParams(args map[string]any) (*historyParams, error) {
 		endTime:     endTime,
 		stateFilter: stateFilter,
 		limit:       limit,
+		skip:        skip,
 		verbose:     verbose,
 	}, nil
 }
@@ -573,11 +693,13 @@ func parseTimeRange(args map[string]any) (start, end time.Time, err error) {
 	return start, end, nil
 }
 
-// processHistoryEntries flattens, filters, and limits history entries.
+// processHistoryEntries flattens, filters, and limits history entries,
+// leaving out the newest skip entries returned on previous pages.
 func processHistoryEntries(
 	history [][]homeassistant.HistoryEntry,
 	stateFilter string,
 	limit int,
+	skip int,
 ) historyResult {
 	// Flatten history (it's [][]HistoryEntry, typically with one inner array per entity)
 	var entries []homeassistant.HistoryEntry
@@ -598,7 +720,8 @@ func processHistoryEntries(
 
 	totalCount := len(entries)
 
-	// Apply limit (take most recent entries)
+	// Skip the entries of previous pages, then apply limit (take most recent entries)
+	entries = entries[:max(len(entries)-skip, 0)]
 	if limit > 0 && len(entries) > limit {
 		entries = entries[len(entries)-limit:]
 	}
@@ -606,6 +729,7 @@ func processHistoryEntries(
 	return historyResult{
 		entries:    entries,
 		totalCount: totalCount,
+		olderCount: max(totalCount-skip, 0) - len(entries),
 	}
 }
 
@@ -665,7 +789,7 @@ func (h *EntityHandlers) handleGetHistory(ctx context.Context, client homeassist
 		}, nil
 	}
 
-	result := processHistoryEntries(history, params.stateFilter, params.limit)
+	result := processHistoryEntries(history, params.stateFilter, params.limit, params.skip)
 
 	output, err := formatHistoryOutput(result.entries, params.verbose)
 	if err != nil {
@@ -675,10 +799,25 @@ func (h *EntityHandlers) handleGetHistory(ctx context.Context, client homeassist
 		}, nil
 	}
 
-	summary := buildHistorySummary(params.entityID, result, params.stateFilter, params.verbose)
+	next := ""
+	if result.olderCount > 0 {
+		next = mcp.EncodeCursor(historyCursor{
+			Start: params.startTime,
+			End:   params.endTime,
+			Skip:  params.skip + len(result.entries),
+		})
+	}
+	summary := buildHistorySummary(params.entityID, result, params.stateFilter, params.verbose) + nextPageHint(next)
 
 	return &mcp.ToolsCallResult{
 		Content: []mcp.ContentBlock{mcp.NewTextContent(summary + "\n\n" + string(output))},
+		StructuredContent: historyOutput{
+			EntityID:   params.entityID,
+			Count:      len(result.entries),
+			TotalCount: result.totalCount,
+			NextCursor: next,
+			Entries:    output,
+		},
 	}, nil
 }
 
@@ -1199,7 +1199,7 @@ func TestProcessHistoryEntries(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			result := processHistoryEntries(tt.history, tt.stateFilter, tt.limit)
+			result := processHistoryEntries(tt.history, tt.stateFilter, tt.limit, 0)
 
 			if len(result.entries) != tt.wantCount {
 				t.Errorf("entries count = %d, want %d", len(result.entries), tt.wantCount)
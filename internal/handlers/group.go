@@ -31,6 +31,7 @@ func (h *GroupHandlers) createGroupTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_group",
 		Description: "Create a new group helper in Home Assistant. A group combines multiple entities into one. The group state is determined by the member entities' states.",
+		Annotations: createAnnotations("Create Group"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Group configuration",
@@ -68,6 +69,7 @@ func (h *GroupHandlers) deleteGroupTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_group",
 		Description: "Delete a group helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Group"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Group entity ID to delete",
@@ -75,6 +77,7 @@ func (h *GroupHandlers) deleteGroupTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the group (e.g., group.living_room_lights)",
+					Pattern:     `^group\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -86,6 +89,7 @@ func (h *GroupHandlers) setGroupEntitiesTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "set_group_entities",
 		Description: "Add or remove entities from an existing group. You can specify entities to add and/or remove.",
+		Annotations: destructiveAnnotations("Set Group Entities"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Group entity modification",
@@ -93,6 +97,7 @@ func (h *GroupHandlers) setGroupEntitiesTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the group (e.g., group.living_room_lights)",
+					Pattern:     `^group\.`,
 				},
 				"add_entities": {
 					Type:        "array",
@@ -118,6 +123,7 @@ func (h *GroupHandlers) reloadGroupTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "reload_group",
 		Description: "Reload all group helpers from configuration. Use this after manually editing group configuration files.",
+		Annotations: actionAnnotations("Reload Groups", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "No parameters required",
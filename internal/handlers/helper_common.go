@@ -52,3 +52,8 @@ func IsValidHelperPlatform(platform string) bool {
 	}
 	return false
 }
+
+// float64Ptr returns a pointer to v, for optional JSON Schema keywords such as Minimum.
+func float64Ptr(v float64) *float64 {
+	return &v
+}
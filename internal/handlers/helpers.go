@@ -29,6 +29,7 @@ func (h *HelperHandlers) listHelpersTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "list_helpers",
 		Description: "List all input helpers in Home Assistant (input_boolean, input_number, input_text, input_select, input_datetime)",
+		Annotations: readOnlyAnnotations("List Helpers"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "No parameters required",
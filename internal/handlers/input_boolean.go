@@ -28,6 +28,7 @@ func (h *InputBooleanHandlers) createInputBooleanTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_input_boolean",
 		Description: "Create a new input_boolean helper in Home Assistant",
+		Annotations: createAnnotations("Create Input Boolean"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input boolean configuration",
@@ -58,6 +59,7 @@ func (h *InputBooleanHandlers) deleteInputBooleanTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_input_boolean",
 		Description: "Delete an input_boolean helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Input Boolean"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input boolean entity ID to delete",
@@ -65,6 +67,7 @@ func (h *InputBooleanHandlers) deleteInputBooleanTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_boolean (e.g., input_boolean.my_switch)",
+					Pattern:     `^input_boolean\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -76,6 +79,7 @@ func (h *InputBooleanHandlers) toggleInputBooleanTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "toggle_input_boolean",
 		Description: "Toggle an input_boolean helper (switch between on and off)",
+		Annotations: actionAnnotations("Toggle Input Boolean", false),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input boolean entity ID to toggle",
@@ -83,6 +87,7 @@ func (h *InputBooleanHandlers) toggleInputBooleanTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_boolean (e.g., input_boolean.my_switch)",
+					Pattern:     `^input_boolean\.`,
 				},
 			},
 			Required: []string{"entity_id"},
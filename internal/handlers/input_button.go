@@ -30,6 +30,7 @@ func (h *InputButtonHandlers) createInputButtonTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_input_button",
 		Description: "Create a new input_button helper in Home Assistant. An input button is a virtual button that can be pressed to trigger automations.",
+		Annotations: createAnnotations("Create Input Button"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input button configuration",
@@ -56,6 +57,7 @@ func (h *InputButtonHandlers) deleteInputButtonTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_input_button",
 		Description: "Delete an input_button helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Input Button"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input button entity ID to delete",
@@ -63,6 +65,7 @@ func (h *InputButtonHandlers) deleteInputButtonTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_button (e.g., input_button.my_button)",
+					Pattern:     `^input_button\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -74,6 +77,7 @@ func (h *InputButtonHandlers) pressInputButtonTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "press_input_button",
 		Description: "Press an input_button helper. This triggers any automations listening for this button press.",
+		Annotations: actionAnnotations("Press Input Button", false),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input button entity ID to press",
@@ -81,6 +85,7 @@ func (h *InputButtonHandlers) pressInputButtonTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_button (e.g., input_button.my_button)",
+					Pattern:     `^input_button\.`,
 				},
 			},
 			Required: []string{"entity_id"},
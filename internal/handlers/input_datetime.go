@@ -28,6 +28,7 @@ func (h *InputDatetimeHandlers) createInputDatetimeTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_input_datetime",
 		Description: "Create a new input_datetime helper in Home Assistant",
+		Annotations: createAnnotations("Create Input Datetime"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input datetime configuration",
@@ -66,6 +67,7 @@ func (h *InputDatetimeHandlers) deleteInputDatetimeTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_input_datetime",
 		Description: "Delete an input_datetime helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Input Datetime"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input datetime entity ID to delete",
@@ -73,6 +75,7 @@ func (h *InputDatetimeHandlers) deleteInputDatetimeTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_datetime (e.g., input_datetime.my_datetime)",
+					Pattern:     `^input_datetime\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -84,6 +87,7 @@ func (h *InputDatetimeHandlers) setInputDatetimeTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "set_input_datetime",
 		Description: "Set the value of an input_datetime helper",
+		Annotations: actionAnnotations("Set Input Datetime", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input datetime entity ID and new value",
@@ -91,6 +95,7 @@ func (h *InputDatetimeHandlers) setInputDatetimeTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_datetime (e.g., input_datetime.my_datetime)",
+					Pattern:     `^input_datetime\.`,
 				},
 				"datetime": {
 					Type:        "string",
@@ -28,6 +28,7 @@ func (h *InputNumberHandlers) createInputNumberTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_input_number",
 		Description: "Create a new input_number helper in Home Assistant",
+		Annotations: createAnnotations("Create Input Number"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input number configuration",
@@ -78,6 +79,7 @@ func (h *InputNumberHandlers) deleteInputNumberTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_input_number",
 		Description: "Delete an input_number helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Input Number"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input number entity ID to delete",
@@ -85,6 +87,7 @@ func (h *InputNumberHandlers) deleteInputNumberTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_number (e.g., input_number.my_value)",
+					Pattern:     `^input_number\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -96,6 +99,7 @@ func (h *InputNumberHandlers) setInputNumberValueTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "set_input_number_value",
 		Description: "Set the value of an input_number helper",
+		Annotations: actionAnnotations("Set Input Number Value", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input number entity ID and new value",
@@ -103,6 +107,7 @@ func (h *InputNumberHandlers) setInputNumberValueTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_number (e.g., input_number.my_value)",
+					Pattern:     `^input_number\.`,
 				},
 				"value": {
 					Type:        "number",
@@ -31,6 +31,7 @@ func (h *InputSelectHandlers) createInputSelectTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_input_select",
 		Description: "Create a new input_select helper in Home Assistant",
+		Annotations: createAnnotations("Create Input Select"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input select configuration",
@@ -65,6 +66,7 @@ func (h *InputSelectHandlers) deleteInputSelectTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_input_select",
 		Description: "Delete an input_select helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Input Select"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input select entity ID to delete",
@@ -72,6 +74,7 @@ func (h *InputSelectHandlers) deleteInputSelectTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_select (e.g., input_select.my_dropdown)",
+					Pattern:     `^input_select\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -83,6 +86,7 @@ func (h *InputSelectHandlers) selectOptionTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "select_option",
 		Description: "Select an option in an input_select helper",
+		Annotations: actionAnnotations("Select Input Select Option", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input select entity ID and option to select",
@@ -90,6 +94,7 @@ func (h *InputSelectHandlers) selectOptionTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_select (e.g., input_select.my_dropdown)",
+					Pattern:     `^input_select\.`,
 				},
 				"option": {
 					Type:        "string",
@@ -105,6 +110,7 @@ func (h *InputSelectHandlers) setOptionsTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "set_options",
 		Description: "Set the available options for an input_select helper",
+		Annotations: destructiveAnnotations("Set Input Select Options"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input select entity ID and new options list",
@@ -112,6 +118,7 @@ func (h *InputSelectHandlers) setOptionsTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_select (e.g., input_select.my_dropdown)",
+					Pattern:     `^input_select\.`,
 				},
 				"options": {
 					Type:        "array",
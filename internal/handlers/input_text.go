@@ -28,6 +28,7 @@ func (h *InputTextHandlers) createInputTextTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_input_text",
 		Description: "Create a new input_text helper in Home Assistant",
+		Annotations: createAnnotations("Create Input Text"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input text configuration",
@@ -74,6 +75,7 @@ func (h *InputTextHandlers) deleteInputTextTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_input_text",
 		Description: "Delete an input_text helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Input Text"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input text entity ID to delete",
@@ -81,6 +83,7 @@ func (h *InputTextHandlers) deleteInputTextTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_text (e.g., input_text.my_text)",
+					Pattern:     `^input_text\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -92,6 +95,7 @@ func (h *InputTextHandlers) setInputTextValueTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "set_input_text_value",
 		Description: "Set the value of an input_text helper",
+		Annotations: actionAnnotations("Set Input Text Value", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Input text entity ID and new value",
@@ -99,6 +103,7 @@ func (h *InputTextHandlers) setInputTextValueTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the input_text (e.g., input_text.my_text)",
+					Pattern:     `^input_text\.`,
 				},
 				"value": {
 					Type:        "string",
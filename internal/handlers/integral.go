@@ -31,6 +31,7 @@ func (h *IntegralHandlers) createIntegralTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_integral",
 		Description: "Create a new integral (integration) helper in Home Assistant. An integral sensor calculates the integral (sum over time) of a source sensor's values.",
+		Annotations: createAnnotations("Create Integral Sensor"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Integral sensor configuration",
@@ -80,6 +81,7 @@ func (h *IntegralHandlers) deleteIntegralTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_integral",
 		Description: "Delete an integral (integration) helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Integral Sensor"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Integral entity ID to delete",
@@ -87,6 +89,7 @@ func (h *IntegralHandlers) deleteIntegralTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the integral sensor (e.g., sensor.my_integral)",
+					Pattern:     `^sensor\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -98,6 +101,7 @@ func (h *IntegralHandlers) resetIntegralTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "reset_integral",
 		Description: "Reset an integral (integration) sensor to zero",
+		Annotations: actionAnnotations("Reset Integral", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Integral entity ID to reset",
@@ -105,6 +109,7 @@ func (h *IntegralHandlers) resetIntegralTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the integral sensor (e.g., sensor.my_integral)",
+					Pattern:     `^sensor\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -29,6 +29,7 @@ func (h *LovelaceHandlers) getLovelaceConfigTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_lovelace_config",
 		Description: "Get the Lovelace dashboard configuration. By default returns a compact overview of views. Use 'view' filter to get a specific view, and 'verbose' for full details.",
+		Annotations: readOnlyAnnotations("Get Dashboard Configuration"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Filter and output options for Lovelace configuration",
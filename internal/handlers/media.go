@@ -30,6 +30,7 @@ func (h *MediaHandlers) signPathTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "sign_media_path",
 		Description: "Sign a media path to generate a temporary authenticated URL. Useful for accessing protected media files like camera snapshots or local media.",
+		Annotations: readOnlyAnnotations("Sign Media Path"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -40,6 +41,7 @@ func (h *MediaHandlers) signPathTool() mcp.Tool {
 				"expires": {
 					Type:        "integer",
 					Description: "Expiration time in seconds (default: 30)",
+					Minimum:     float64Ptr(1),
 				},
 			},
 			Required: []string{"path"},
@@ -105,6 +107,7 @@ func (h *MediaHandlers) getCameraStreamTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_camera_stream",
 		Description: "Get the streaming URL for a camera entity. Returns HLS stream URL for live camera feeds.",
+		Annotations: readOnlyAnnotations("Get Camera Stream"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -166,6 +169,7 @@ func (h *MediaHandlers) browseMediaTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "browse_media",
 		Description: "Browse media content in Home Assistant. Navigate through media sources, folders, and files available in the media browser.",
+		Annotations: readOnlyAnnotations("Browse Media"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
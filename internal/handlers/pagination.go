@@ -0,0 +1,84 @@
+// Package handlers provides MCP tool handlers for Home Assistant operations.
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zorak1103/ha-mcp/internal/mcp"
+)
+
+// Pagination arguments of list tools.
+const (
+	argLimit  = "limit"
+	argCursor = "cursor"
+)
+
+// withPaginationProperties adds the limit and cursor arguments to the input
+// schema properties of a list tool and returns props.
+func withPaginationProperties(props map[string]mcp.JSONSchema, noun string) map[string]mcp.JSONSchema {
+	props[argLimit] = mcp.JSONSchema{
+		Type:        "integer",
+		Description: fmt.Sprintf("Maximum number of %s to return. Default: all", noun),
+		Minimum:     float64Ptr(1),
+	}
+	props[argCursor] = cursorProperty()
+	return props
+}
+
+// cursorProperty describes the cursor argument of a list tool.
+func cursorProperty() mcp.JSONSchema {
+	return mcp.JSONSchema{
+		Type:        "string",
+		Description: "The next_cursor of a previous call with the same arguments, to get the next page",
+	}
+}
+
+// withPageOutputProperties adds total_count and next_cursor to the output
+// schema properties of a list tool and returns props.
+func withPageOutputProperties(props map[string]mcp.JSONSchema, noun string) map[string]mcp.JSONSchema {
+	props["total_count"] = mcp.JSONSchema{
+		Type:        "integer",
+		Description: fmt.Sprintf("Number of matching %s on all pages", noun),
+	}
+	props["next_cursor"] = mcp.JSONSchema{
+		Type:        "string",
+		Description: "Cursor of the next page; absent on the last page",
+	}
+	return props
+}
+
+// parsePageArgs extracts the limit and cursor arguments. A limit of 0 means no limit.
+func parsePageArgs(args map[string]any) (int, string) {
+	limit := 0
+	if limitVal, ok := args[argLimit].(float64); ok && limitVal > 0 {
+		limit = int(limitVal)
+	}
+	cursor, _ := args[argCursor].(string)
+	return limit, cursor
+}
+
+// paginate sorts items by key and returns the page selected by the limit and
+// cursor arguments along with the cursor of the next page.
+func paginate[T any](items []T, key func(T) string, args map[string]any) ([]T, string, error) {
+	slices.SortStableFunc(items, func(a, b T) int { return strings.Compare(key(a), key(b)) })
+	limit, cursor := parsePageArgs(args)
+	return mcp.PageAfter(items, key, cursor, limit)
+}
+
+// pageSummary describes a page of a listing of total items.
+func pageSummary(noun string, shown, total int) string {
+	if shown == total {
+		return fmt.Sprintf("Found %d %s", total, noun)
+	}
+	return fmt.Sprintf("Showing %d of %d %s", shown, total, noun)
+}
+
+// nextPageHint tells how to get the next page, if there is one.
+func nextPageHint(next string) string {
+	if next == "" {
+		return ""
+	}
+	return fmt.Sprintf("\nMore results: call again with the same arguments and cursor %q", next)
+}
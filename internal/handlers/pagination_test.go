@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+)
+
+func TestHandleGetStates_Pagination(t *testing.T) {
+	t.Parallel()
+
+	h := &EntityHandlers{}
+	client := &UniversalMockClient{
+		GetStatesFn: func(_ context.Context) ([]homeassistant.Entity, error) {
+			// Unsorted, as Home Assistant returns them
+			return []homeassistant.Entity{
+				{EntityID: "switch.kitchen", State: "on"},
+				{EntityID: "light.hall", State: "off"},
+				{EntityID: "sensor.temperature", State: "21.5"},
+				{EntityID: "light.bedroom", State: "on"},
+				{EntityID: "binary_sensor.door", State: "off"},
+			}, nil
+		},
+	}
+
+	var got []string
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		args := map[string]any{"limit": float64(2)}
+		if cursor != "" {
+			args["cursor"] = cursor
+		}
+		result, err := h.handleGetStates(context.Background(), client, args)
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetStates(%v) = %+v, %v", args, result, err)
+		}
+		out := result.StructuredContent.(statesOutput)
+		if out.TotalCount != 5 {
+			t.Errorf("page %d: total_count = %d, want 5", page, out.TotalCount)
+		}
+		var entities []compactEntityState
+		if err := json.Unmarshal(out.Entities, &entities); err != nil {
+			t.Fatalf("unmarshaling entities: %v", err)
+		}
+		for _, e := range entities {
+			got = append(got, e.EntityID)
+		}
+		if cursor = out.NextCursor; cursor == "" {
+			break
+		}
+		assertContainsAll(t, result.Content[0].Text, []string{"Showing 2 of 5 entities", cursor})
+	}
+
+	want := []string{"binary_sensor.door", "light.bedroom", "light.hall", "sensor.temperature", "switch.kitchen"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("paged entity IDs mismatch (-want +got):\n%s", diff)
+	}
+
+	result, err := h.handleGetStates(context.Background(), client, map[string]any{"cursor": "not a cursor"})
+	if err != nil || !result.IsError {
+		t.Errorf("handleGetStates() with invalid cursor = %+v, %v, want an error result", result, err)
+	}
+}
+
+func TestHandleGetHistory_Pagination(t *testing.T) {
+	t.Parallel()
+
+	var windows [][2]time.Time
+	h := &EntityHandlers{}
+	client := &UniversalMockClient{
+		GetHistoryFn: func(_ context.Context, _ string, start, end time.Time) ([][]homeassistant.HistoryEntry, error) {
+			windows = append(windows, [2]time.Time{start, end})
+			entries := make([]homeassistant.HistoryEntry, 5)
+			for i := range entries {
+				entries[i] = homeassistant.HistoryEntry{State: string(rune('a' + i)), LastChanged: float64(1700000000 + i)}
+			}
+			return [][]homeassistant.HistoryEntry{entries}, nil
+		},
+	}
+
+	var pages [][]string
+	args := map[string]any{"entity_id": "sensor.temperature", "limit": float64(2)}
+	for len(pages) < 5 {
+		result, err := h.handleGetHistory(context.Background(), client, args)
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetHistory(%v) = %+v, %v", args, result, err)
+		}
+		out := result.StructuredContent.(historyOutput)
+		var entries []compactHistoryEntry
+		if err := json.Unmarshal(out.Entries, &entries); err != nil {
+			t.Fatalf("unmarshaling entries: %v", err)
+		}
+		var states []string
+		for _, e := range entries {
+			states = append(states, e.State)
+		}
+		pages = append(pages, states)
+		if out.NextCursor == "" {
+			break
+		}
+		args["cursor"] = out.NextCursor
+	}
+
+	// Newest entries first, each page oldest first
+	want := [][]string{{"d", "e"}, {"b", "c"}, {"a"}}
+	if diff := cmp.Diff(want, pages); diff != "" {
+		t.Errorf("history pages mismatch (-want +got):\n%s", diff)
+	}
+	for i, w := range windows[1:] {
+		if !w[0].Equal(windows[0][0]) || !w[1].Equal(windows[0][1]) {
+			t.Errorf("page %d queried %v, want the first page's range %v", i+2, w, windows[0])
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Package handlers provides MCP tool handlers for Home Assistant operations.
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/zorak1103/ha-mcp/internal/mcp"
+)
+
+// callServiceTool is the name of the generic service call tool, which
+// ToolPolicy.DenyServices and ConfirmPolicy.Services apply to.
+const callServiceTool = "call_service"
+
+// genericServiceDomain is the domain of services such as
+// homeassistant.turn_off that act on entities of any domain.
+const genericServiceDomain = "homeassistant"
+
+// opaqueTargetKeys are the service data keys that target entities whose
+// domains are not known without asking Home Assistant.
+var opaqueTargetKeys = []string{"device_id", "area_id", "floor_id", "label_id"}
+
+// genericServiceEquivalents maps the generic homeassistant services to the
+// services they amount to for entities of domains without turn_on and
+// turn_off services. Home Assistant opens covers on turn_on, for example.
+var genericServiceEquivalents = map[string]map[string][]string{
+	"cover": {
+		"turn_on":  {"open_cover"},
+		"turn_off": {"close_cover"},
+		"toggle":   {"open_cover", "close_cover"},
+	},
+	"lock": {
+		"turn_on":  {"unlock"},
+		"turn_off": {"lock"},
+		"toggle":   {"unlock", "lock"},
+	},
+}
+
+// ToolPolicy restricts the tools exposed by the server. It implements
+// mcp.ToolFilter. Patterns use path.Match glob syntax.
+type ToolPolicy struct {
+	// ReadOnly exposes only tools annotated as read-only.
+	ReadOnly bool
+	// Allow lists the tool name patterns to expose. Empty exposes every tool.
+	Allow []string
+	// Deny lists the tool name patterns to hide. It takes precedence over Allow.
+	Deny []string
+	// DenyServices lists the services call_service refuses to call, either as
+	// a bare domain ("lock") or as a "domain.service" pattern ("lock.*").
+	// Generic homeassistant services are checked against the domains of the
+	// entities they target, and refused for targets whose domains are unknown.
+	DenyServices []string
+}
+
+// AllowTool reports whether the policy exposes the tool.
+func (p *ToolPolicy) AllowTool(tool mcp.Tool) bool {
+	if p.ReadOnly && (tool.Annotations == nil || !tool.Annotations.ReadOnlyHint) {
+		return false
+	}
+	if matchAny(p.Deny, tool.Name) {
+		return false
+	}
+	return len(p.Allow) == 0 || matchAny(p.Allow, tool.Name)
+}
+
+// AllowCall returns an error if call_service is asked to call a denied service.
+func (p *ToolPolicy) AllowCall(tool mcp.Tool, args map[string]any) error {
+	if tool.Name != callServiceTool || len(p.DenyServices) == 0 {
+		return nil
+	}
+
+	for _, name := range targetedServices(args) {
+		if matchService(p.DenyServices, name) {
+			return fmt.Errorf("service %s is denied by configuration", name)
+		}
+	}
+	if hasOpaqueTarget(args) {
+		return fmt.Errorf("service %s is denied for device, area, label, and all-entity targets while "+
+			"services are denied by configuration; target entities by entity_id instead", serviceName(args))
+	}
+	return nil
+}
+
+// ConfirmPolicy selects the tool calls that need the user's confirmation
+// before they run. It implements mcp.ConfirmationPolicy. Patterns use
+// path.Match glob syntax.
+type ConfirmPolicy struct {
+	// Tools lists the tool name patterns whose calls need confirmation.
+	Tools []string
+	// Services lists the services call_service needs confirmation for, either
+	// as a bare domain ("lock") or as a "domain.service" pattern ("lock.unlock").
+	// Generic homeassistant services are checked against the domains of the
+	// entities they target, and always need confirmation for targets whose
+	// domains are unknown.
+	Services []string
+}
+
+// ConfirmationMessage returns the question to ask the user before the call,
+// or "" if the policy does not select it.
+func (p *ConfirmPolicy) ConfirmationMessage(tool mcp.Tool, args map[string]any) string {
+	if tool.Name == callServiceTool && p.needsServiceConfirmation(args) {
+		data, _ := args["data"].(map[string]any)
+		return fmt.Sprintf("Call service %s%s?", serviceName(args), describeArguments(data))
+	}
+	if matchAny(p.Tools, tool.Name) {
+		return fmt.Sprintf("Run %s%s?", tool.Name, describeArguments(args))
+	}
+	return ""
+}
+
+// ConfirmsTool reports whether the policy may select calls of the tool.
+func (p *ConfirmPolicy) ConfirmsTool(tool mcp.Tool) bool {
+	return (tool.Name == callServiceTool && len(p.Services) > 0) || matchAny(p.Tools, tool.Name)
+}
+
+// needsServiceConfirmation reports whether a call_service call targets a
+// service selected by p.Services.
+func (p *ConfirmPolicy) needsServiceConfirmation(args map[string]any) bool {
+	if len(p.Services) == 0 {
+		return false
+	}
+	for _, name := range targetedServices(args) {
+		if matchService(p.Services, name) {
+			return true
+		}
+	}
+	return hasOpaqueTarget(args)
+}
+
+// describeArguments formats call arguments for a confirmation question.
+func describeArguments(args map[string]any) string {
+	if len(args) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	return " with " + string(data)
+}
+
+// serviceName returns the lower-case "domain.service" name of a call_service call.
+func serviceName(args map[string]any) string {
+	domain, _ := args["domain"].(string)
+	service, _ := args["service"].(string)
+	return strings.ToLower(domain + "." + service)
+}
+
+// targetedServices returns the lower-case "domain.service" names a
+// call_service call amounts to: the called service, and the same service in
+// the domain of each targeted entity. homeassistant.turn_off on
+// lock.front_door thus also counts as lock.turn_off, and as lock.lock from
+// genericServiceEquivalents.
+func targetedServices(args map[string]any) []string {
+	name := serviceName(args)
+	names := []string{name}
+	_, service, _ := strings.Cut(name, ".")
+	for _, entityID := range targetEntityIDs(args) {
+		domain, _, ok := strings.Cut(entityID, ".")
+		if !ok {
+			continue
+		}
+		names = append(names, domain+"."+service)
+		for _, equivalent := range genericServiceEquivalents[domain][service] {
+			names = append(names, domain+"."+equivalent)
+		}
+	}
+	return names
+}
+
+// targetEntityIDs returns the lower-case entity IDs a call_service call
+// targets, given as a string, a comma-separated string, or a list.
+func targetEntityIDs(args map[string]any) []string {
+	data, _ := args["data"].(map[string]any)
+	var values []string
+	switch v := data["entity_id"].(type) {
+	case string:
+		values = strings.Split(v, ",")
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		if id := strings.ToLower(strings.TrimSpace(value)); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// hasOpaqueTarget reports whether a call of a generic homeassistant service
+// targets devices, areas, labels, or all entities, so that the domains it
+// acts on cannot be told from the call alone.
+func hasOpaqueTarget(args map[string]any) bool {
+	if domain, _, _ := strings.Cut(serviceName(args), "."); domain != genericServiceDomain {
+		return false
+	}
+	data, _ := args["data"].(map[string]any)
+	for _, key := range opaqueTargetKeys {
+		if _, ok := data[key]; ok {
+			return true
+		}
+	}
+	return slices.Contains(targetEntityIDs(args), "all")
+}
+
+// matchService reports whether a "domain.service" name matches any of the
+// patterns. A pattern without a dot matches every service of a domain.
+func matchService(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, ".") {
+			pattern += ".*"
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny reports whether name matches any of the glob patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
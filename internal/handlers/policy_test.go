@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/zorak1103/ha-mcp/internal/mcp"
+)
+
+func TestToolPolicy_AllowTool(t *testing.T) {
+	t.Parallel()
+
+	readTool := mcp.Tool{Name: "get_state", Annotations: readOnlyAnnotations("Get State")}
+	writeTool := mcp.Tool{Name: "delete_automation", Annotations: destructiveAnnotations("Delete Automation")}
+	bareTool := mcp.Tool{Name: "get_bare"}
+
+	tests := []struct {
+		name   string
+		policy ToolPolicy
+		tool   mcp.Tool
+		want   bool
+	}{
+		{name: "no restrictions", tool: writeTool, want: true},
+		{name: "read only keeps read tool", policy: ToolPolicy{ReadOnly: true}, tool: readTool, want: true},
+		{name: "read only drops write tool", policy: ToolPolicy{ReadOnly: true}, tool: writeTool, want: false},
+		{name: "read only drops unannotated tool", policy: ToolPolicy{ReadOnly: true}, tool: bareTool, want: false},
+		{name: "allow match", policy: ToolPolicy{Allow: []string{"get_*"}}, tool: readTool, want: true},
+		{name: "allow miss", policy: ToolPolicy{Allow: []string{"get_*"}}, tool: writeTool, want: false},
+		{name: "deny match", policy: ToolPolicy{Deny: []string{"delete_*"}}, tool: writeTool, want: false},
+		{
+			name:   "deny wins over allow",
+			policy: ToolPolicy{Allow: []string{"*"}, Deny: []string{"get_state"}},
+			tool:   readTool,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.policy.AllowTool(tt.tool); got != tt.want {
+				t.Errorf("AllowTool(%s) = %v, want %v", tt.tool.Name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolPolicy_AllowCall(t *testing.T) {
+	t.Parallel()
+
+	policy := ToolPolicy{DenyServices: []string{"lock", "alarm_control_panel.alarm_disarm"}}
+	callService := mcp.Tool{Name: callServiceTool}
+
+	tests := []struct {
+		name    string
+		tool    mcp.Tool
+		args    map[string]any
+		wantErr bool
+	}{
+		{name: "allowed domain", tool: callService, args: map[string]any{"domain": "light", "service": "turn_on"}},
+		{name: "denied domain", tool: callService, args: map[string]any{"domain": "lock", "service": "unlock"}, wantErr: true},
+		{name: "denied case-insensitive", tool: callService, args: map[string]any{"domain": "Lock", "service": "unlock"}, wantErr: true},
+		{
+			name:    "denied service",
+			tool:    callService,
+			args:    map[string]any{"domain": "alarm_control_panel", "service": "alarm_disarm"},
+			wantErr: true,
+		},
+		{
+			name: "other service of partly denied domain",
+			tool: callService,
+			args: map[string]any{"domain": "alarm_control_panel", "service": "alarm_arm_away"},
+		},
+		{name: "other tool", tool: mcp.Tool{Name: "get_state"}, args: map[string]any{"domain": "lock"}},
+		{
+			name:    "generic service on denied entity",
+			tool:    callService,
+			args:    genericCall("turn_off", map[string]any{"entity_id": "lock.front_door"}),
+			wantErr: true,
+		},
+		{
+			name:    "generic service on list with denied entity",
+			tool:    callService,
+			args:    genericCall("toggle", map[string]any{"entity_id": []any{"light.hall", "Lock.Front_Door"}}),
+			wantErr: true,
+		},
+		{
+			name:    "generic service on comma-separated denied entity",
+			tool:    callService,
+			args:    genericCall("toggle", map[string]any{"entity_id": "light.hall, lock.front_door"}),
+			wantErr: true,
+		},
+		{
+			name: "generic service on allowed entity",
+			tool: callService,
+			args: genericCall("turn_off", map[string]any{"entity_id": "light.hall"}),
+		},
+		{
+			name:    "generic service on area",
+			tool:    callService,
+			args:    genericCall("turn_off", map[string]any{"area_id": "garage"}),
+			wantErr: true,
+		},
+		{
+			name:    "generic service on all entities",
+			tool:    callService,
+			args:    genericCall("turn_off", map[string]any{"entity_id": "all"}),
+			wantErr: true,
+		},
+		{
+			name: "domain service on area",
+			tool: callService,
+			args: map[string]any{"domain": "light", "service": "turn_off", "data": map[string]any{"area_id": "garage"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := policy.AllowCall(tt.tool, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AllowCall() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterAllTools_ReadOnlyPolicy(t *testing.T) {
+	t.Parallel()
+
+	registry := mcp.NewRegistry()
+	registry.SetToolFilter(&ToolPolicy{ReadOnly: true})
+	RegisterAllTools(registry)
+
+	tools := registry.ListTools()
+	if len(tools) == 0 {
+		t.Fatal("read-only policy left no tools")
+	}
+	for _, tool := range tools {
+		if !tool.Annotations.ReadOnlyHint {
+			t.Errorf("tool %s is exposed in read-only mode but is not read-only", tool.Name)
+		}
+	}
+	if _, ok := registry.GetTool(callServiceTool); ok {
+		t.Errorf("%s is exposed in read-only mode", callServiceTool)
+	}
+}
+
+func TestConfirmPolicy_ConfirmationMessage(t *testing.T) {
+	t.Parallel()
+
+	policy := ConfirmPolicy{
+		Tools:    []string{"delete_*"},
+		Services: []string{"lock.unlock", "cover.open_*", "cover.toggle", "cover.set_cover_position", "alarm_control_panel"},
+	}
+	callService := mcp.Tool{Name: callServiceTool}
+
+	tests := []struct {
+		name string
+		tool mcp.Tool
+		args map[string]any
+		want string
+	}{
+		{
+			name: "matching tool",
+			tool: mcp.Tool{Name: "delete_automation"},
+			args: map[string]any{"automation_id": "night"},
+			want: `Run delete_automation with {"automation_id":"night"}?`,
+		},
+		{name: "other tool", tool: mcp.Tool{Name: "create_automation"}, args: map[string]any{"automation_id": "night"}},
+		{
+			name: "matching service",
+			tool: callService,
+			args: map[string]any{"domain": "lock", "service": "unlock", "data": map[string]any{"entity_id": "lock.front_door"}},
+			want: `Call service lock.unlock with {"entity_id":"lock.front_door"}?`,
+		},
+		{
+			name: "service glob",
+			tool: callService,
+			args: map[string]any{"domain": "cover", "service": "open_cover_tilt"},
+			want: "Call service cover.open_cover_tilt?",
+		},
+		{
+			name: "bare domain",
+			tool: callService,
+			args: map[string]any{"domain": "alarm_control_panel", "service": "alarm_disarm"},
+			want: "Call service alarm_control_panel.alarm_disarm?",
+		},
+		{name: "other service", tool: callService, args: map[string]any{"domain": "lock", "service": "lock"}},
+		{
+			name: "cover toggle",
+			tool: callService,
+			args: map[string]any{"domain": "cover", "service": "toggle", "data": map[string]any{"entity_id": "cover.garage_door"}},
+			want: `Call service cover.toggle with {"entity_id":"cover.garage_door"}?`,
+		},
+		{
+			name: "cover position",
+			tool: callService,
+			args: map[string]any{"domain": "cover", "service": "set_cover_position"},
+			want: "Call service cover.set_cover_position?",
+		},
+		{
+			name: "generic turn_on of a lock",
+			tool: callService,
+			args: genericCall("turn_on", map[string]any{"entity_id": "lock.front_door"}),
+			want: `Call service homeassistant.turn_on with {"entity_id":"lock.front_door"}?`,
+		},
+		{
+			name: "generic toggle of a lock",
+			tool: callService,
+			args: genericCall("toggle", map[string]any{"entity_id": []any{"light.hall", "lock.front_door"}}),
+			want: `Call service homeassistant.toggle with {"entity_id":["light.hall","lock.front_door"]}?`,
+		},
+		{
+			name: "generic turn_on of a cover",
+			tool: callService,
+			args: genericCall("turn_on", map[string]any{"entity_id": "cover.garage_door"}),
+			want: `Call service homeassistant.turn_on with {"entity_id":"cover.garage_door"}?`,
+		},
+		{
+			name: "generic toggle of a cover",
+			tool: callService,
+			args: genericCall("toggle", map[string]any{"entity_id": "Cover.Garage_Door"}),
+			want: `Call service homeassistant.toggle with {"entity_id":"Cover.Garage_Door"}?`,
+		},
+		{
+			name: "generic call on an area",
+			tool: callService,
+			args: genericCall("turn_on", map[string]any{"area_id": "garage"}),
+			want: `Call service homeassistant.turn_on with {"area_id":"garage"}?`,
+		},
+		{name: "generic turn_off of a lock", tool: callService, args: genericCall("turn_off", map[string]any{"entity_id": "lock.front_door"})},
+		{name: "generic turn_on of a light", tool: callService, args: genericCall("turn_on", map[string]any{"entity_id": "light.hall"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := policy.ConfirmationMessage(tt.tool, tt.args); got != tt.want {
+				t.Errorf("ConfirmationMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmPolicy_ConfirmationMessageWithoutServices(t *testing.T) {
+	t.Parallel()
+
+	// Without confirm services, generic calls on areas need no confirmation
+	policy := ConfirmPolicy{Tools: []string{"delete_*"}}
+	args := genericCall("turn_on", map[string]any{"area_id": "garage"})
+	if got := policy.ConfirmationMessage(mcp.Tool{Name: callServiceTool}, args); got != "" {
+		t.Errorf("ConfirmationMessage() = %q, want \"\"", got)
+	}
+}
+
+func TestConfirmPolicy_ConfirmsTool(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy ConfirmPolicy
+		tool   string
+		want   bool
+	}{
+		{name: "matching tool", policy: ConfirmPolicy{Tools: []string{"delete_*"}}, tool: "delete_scene", want: true},
+		{name: "other tool", policy: ConfirmPolicy{Tools: []string{"delete_*"}}, tool: "create_scene"},
+		{name: "call_service with services", policy: ConfirmPolicy{Services: []string{"lock"}}, tool: callServiceTool, want: true},
+		{name: "call_service without services", policy: ConfirmPolicy{Tools: []string{"delete_*"}}, tool: callServiceTool},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.policy.ConfirmsTool(mcp.Tool{Name: tt.tool}); got != tt.want {
+				t.Errorf("ConfirmsTool(%s) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+// genericCall returns call_service arguments for a homeassistant service.
+func genericCall(service string, data map[string]any) map[string]any {
+	return map[string]any{"domain": "homeassistant", "service": service, "data": data}
+}
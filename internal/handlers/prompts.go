@@ -0,0 +1,406 @@
+// Package handlers provides MCP tool handlers for Home Assistant operations.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/mcp"
+)
+
+// stateUnavailable is the state Home Assistant reports for entities whose
+// integration or device cannot be reached.
+const stateUnavailable = "unavailable"
+
+// PromptHandlers provides MCP prompts that embed live Home Assistant data.
+type PromptHandlers struct {
+	analysis *AnalysisHandlers
+}
+
+// NewPromptHandlers creates a new PromptHandlers instance.
+func NewPromptHandlers() *PromptHandlers {
+	return &PromptHandlers{analysis: NewAnalysisHandlers()}
+}
+
+// RegisterPrompts registers all prompts with the registry.
+func (h *PromptHandlers) RegisterPrompts(registry *mcp.Registry) {
+	registry.RegisterPrompt(h.troubleshootEntityPrompt(), h.handleTroubleshootEntity)
+	registry.RegisterPrompt(h.designAreaAutomationPrompt(), h.handleDesignAreaAutomation)
+	registry.RegisterPrompt(h.reviewUnavailableDevicesPrompt(), h.handleReviewUnavailableDevices)
+}
+
+func (h *PromptHandlers) troubleshootEntityPrompt() mcp.Prompt {
+	return mcp.Prompt{
+		Name:        "troubleshoot_entity",
+		Description: "Troubleshoot an entity using its current state, recent history, and every automation, script, scene, and group that references it.",
+		Arguments: []mcp.PromptArgument{
+			{
+				Name:        "entity_id",
+				Description: "The entity to troubleshoot (e.g., 'light.living_room')",
+				Required:    true,
+			},
+		},
+	}
+}
+
+func (h *PromptHandlers) designAreaAutomationPrompt() mcp.Prompt {
+	return mcp.Prompt{
+		Name:        "design_area_automation",
+		Description: "Design an automation for an area, based on the entities in the area and the triggers Home Assistant offers for it.",
+		Arguments: []mcp.PromptArgument{
+			{
+				Name:        "area_id",
+				Description: "The area to automate (e.g., 'living_room')",
+				Required:    true,
+			},
+			{
+				Name:        "goal",
+				Description: "What the automation should achieve (e.g., 'turn on the lights when someone enters after sunset')",
+			},
+		},
+	}
+}
+
+func (h *PromptHandlers) reviewUnavailableDevicesPrompt() mcp.Prompt {
+	return mcp.Prompt{
+		Name:        "review_unavailable_devices",
+		Description: "Review all unavailable entities grouped by device and suggest causes and fixes.",
+		Arguments: []mcp.PromptArgument{
+			{
+				Name:        "domain",
+				Description: "Only include entities of this domain (e.g., 'sensor')",
+			},
+		},
+	}
+}
+
+func (h *PromptHandlers) handleTroubleshootEntity(ctx context.Context, client homeassistant.Client, args map[string]string) (*mcp.PromptsGetResult, error) {
+	entityID := args["entity_id"]
+
+	analysis, err := h.analysis.buildEntityAnalysis(ctx, client, entityID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(analysis, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("formatting analysis: %w", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Help me troubleshoot the Home Assistant entity %s.\n\n", entityID)
+	sb.WriteString("Here is its current analysis, including its state, attributes, recent history, ")
+	sb.WriteString("and everything that references it:\n\n")
+	writeJSONBlock(&sb, data)
+	sb.WriteString("\nExplain what the entity is doing and whether its state and history look healthy. ")
+	sb.WriteString("Point out automations, scripts, or scenes that could be causing unexpected behavior, ")
+	sb.WriteString("and suggest concrete next steps to diagnose or fix the problem.")
+
+	return &mcp.PromptsGetResult{
+		Description: fmt.Sprintf("Troubleshoot %s", entityID),
+		Messages:    []mcp.PromptMessage{mcp.NewUserPromptMessage(sb.String())},
+	}, nil
+}
+
+// AreaEntity summarizes an entity of an area.
+type AreaEntity struct {
+	EntityID     string `json:"entity_id"`
+	FriendlyName string `json:"friendly_name,omitempty"`
+	State        string `json:"state,omitempty"`
+	Device       string `json:"device,omitempty"`
+}
+
+// AreaAutomationContext is the live data embedded in the design_area_automation prompt.
+type AreaAutomationContext struct {
+	AreaID   string       `json:"area_id"`
+	Name     string       `json:"name"`
+	Entities []AreaEntity `json:"entities"`
+	Triggers []string     `json:"available_triggers"`
+}
+
+func (h *PromptHandlers) handleDesignAreaAutomation(ctx context.Context, client homeassistant.Client, args map[string]string) (*mcp.PromptsGetResult, error) {
+	areaCtx, err := h.buildAreaAutomationContext(ctx, client, args["area_id"])
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(areaCtx, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("formatting area context: %w", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Help me design a Home Assistant automation for the area %q.\n\n", areaCtx.Name)
+	if goal := strings.TrimSpace(args["goal"]); goal != "" {
+		fmt.Fprintf(&sb, "Goal: %s\n\n", goal)
+	}
+	sb.WriteString("These are the entities in the area and the triggers Home Assistant offers for it:\n\n")
+	writeJSONBlock(&sb, data)
+	sb.WriteString("\nPropose an automation using only these entities and trigger types. ")
+	sb.WriteString("Explain the trigger, conditions, and actions you chose, then give the complete configuration ")
+	sb.WriteString("in the format accepted by the create_automation tool. Ask before creating it.")
+
+	return &mcp.PromptsGetResult{
+		Description: fmt.Sprintf("Design an automation for %s", areaCtx.Name),
+		Messages:    []mcp.PromptMessage{mcp.NewUserPromptMessage(sb.String())},
+	}, nil
+}
+
+// buildAreaAutomationContext collects the entities of an area and the triggers
+// available for it.
+func (h *PromptHandlers) buildAreaAutomationContext(ctx context.Context, client homeassistant.Client, areaID string) (*AreaAutomationContext, error) {
+	area, err := findArea(ctx, client, areaID)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := collectAreaEntities(ctx, client, areaID)
+	if err != nil {
+		return nil, err
+	}
+
+	triggers, err := client.GetTriggersForTarget(ctx, homeassistant.Target{AreaID: []string{areaID}}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting triggers for area: %w", err)
+	}
+
+	return &AreaAutomationContext{
+		AreaID:   areaID,
+		Name:     area.Name,
+		Entities: entities,
+		Triggers: triggers,
+	}, nil
+}
+
+// findArea looks up an area in the area registry.
+func findArea(ctx context.Context, client homeassistant.Client, areaID string) (*homeassistant.AreaRegistryEntry, error) {
+	areas, err := client.GetAreaRegistry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting area registry: %w", err)
+	}
+
+	for i := range areas {
+		if areas[i].AreaID == areaID {
+			return &areas[i], nil
+		}
+	}
+	return nil, fmt.Errorf("area not found: %s", areaID)
+}
+
+// collectAreaEntities returns the enabled entities of an area, sorted by entity ID.
+// Entities without an area of their own inherit their device's area.
+func collectAreaEntities(ctx context.Context, client homeassistant.Client, areaID string) ([]AreaEntity, error) {
+	entries, err := client.GetEntityRegistry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting entity registry: %w", err)
+	}
+
+	devices, err := client.GetDeviceRegistry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting device registry: %w", err)
+	}
+
+	deviceByID := make(map[string]homeassistant.DeviceRegistryEntry, len(devices))
+	for _, device := range devices {
+		deviceByID[device.ID] = device
+	}
+
+	states, err := client.GetStates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting states: %w", err)
+	}
+
+	stateByID := make(map[string]homeassistant.Entity, len(states))
+	for _, state := range states {
+		stateByID[state.EntityID] = state
+	}
+
+	entities := []AreaEntity{}
+	for _, entry := range entries {
+		if entry.DisabledBy != "" {
+			continue
+		}
+
+		device, hasDevice := deviceByID[entry.DeviceID]
+		entityArea := entry.AreaID
+		if entityArea == "" && hasDevice {
+			entityArea = device.AreaID
+		}
+		if entityArea != areaID {
+			continue
+		}
+
+		entity := AreaEntity{EntityID: entry.EntityID}
+		if hasDevice {
+			entity.Device = deviceName(device)
+		}
+		if state, ok := stateByID[entry.EntityID]; ok {
+			entity.State = state.State
+			entity.FriendlyName, _ = state.Attributes["friendly_name"].(string)
+		}
+		entities = append(entities, entity)
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].EntityID < entities[j].EntityID
+	})
+
+	return entities, nil
+}
+
+// UnavailableDevice groups the unavailable entities of a single device.
+type UnavailableDevice struct {
+	DeviceID     string   `json:"device_id,omitempty"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	AreaID       string   `json:"area_id,omitempty"`
+	Entities     []string `json:"entities"`
+	Since        string   `json:"unavailable_since,omitempty"`
+}
+
+func (h *PromptHandlers) handleReviewUnavailableDevices(ctx context.Context, client homeassistant.Client, args map[string]string) (*mcp.PromptsGetResult, error) {
+	devices, err := h.buildUnavailableDevices(ctx, client, strings.TrimSpace(args["domain"]))
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	if len(devices) == 0 {
+		sb.WriteString("Home Assistant currently reports no unavailable entities. ")
+		sb.WriteString("Confirm this and mention anything worth checking periodically to keep it that way.")
+	} else {
+		data, err := json.MarshalIndent(devices, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("formatting unavailable devices: %w", err)
+		}
+
+		sb.WriteString("Review the unavailable devices in my Home Assistant installation.\n\n")
+		sb.WriteString("These entities are unavailable, grouped by device:\n\n")
+		writeJSONBlock(&sb, data)
+		sb.WriteString("\nFor each device, suggest the most likely cause (for example a dead battery, ")
+		sb.WriteString("a network problem, or a failed integration) and how to fix it. ")
+		sb.WriteString("Point out devices that share an integration or area, since they may share a cause, ")
+		sb.WriteString("and list devices that look abandoned and could be removed.")
+	}
+
+	return &mcp.PromptsGetResult{
+		Description: "Review unavailable devices",
+		Messages:    []mcp.PromptMessage{mcp.NewUserPromptMessage(sb.String())},
+	}, nil
+}
+
+// buildUnavailableDevices groups unavailable entities by device. Entities
+// without a device are grouped under their own entity ID.
+func (h *PromptHandlers) buildUnavailableDevices(ctx context.Context, client homeassistant.Client, domain string) ([]UnavailableDevice, error) {
+	states, err := client.GetStates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting states: %w", err)
+	}
+
+	var unavailable []homeassistant.Entity
+	for _, state := range states {
+		if state.State != stateUnavailable {
+			continue
+		}
+		if domain != "" && !strings.HasPrefix(state.EntityID, domain+".") {
+			continue
+		}
+		unavailable = append(unavailable, state)
+	}
+	if len(unavailable) == 0 {
+		return []UnavailableDevice{}, nil
+	}
+
+	entries, err := client.GetEntityRegistry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting entity registry: %w", err)
+	}
+
+	deviceRegistry, err := client.GetDeviceRegistry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting device registry: %w", err)
+	}
+
+	deviceOf := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.DeviceID != "" {
+			deviceOf[entry.EntityID] = entry.DeviceID
+		}
+	}
+
+	deviceByID := make(map[string]homeassistant.DeviceRegistryEntry, len(deviceRegistry))
+	for _, device := range deviceRegistry {
+		deviceByID[device.ID] = device
+	}
+
+	grouped := make(map[string]*UnavailableDevice)
+	for _, state := range unavailable {
+		key := state.EntityID
+		group := &UnavailableDevice{Name: state.EntityID}
+		if deviceID, ok := deviceOf[state.EntityID]; ok {
+			key = deviceID
+			group = &UnavailableDevice{DeviceID: deviceID, Name: deviceID}
+			if device, ok := deviceByID[deviceID]; ok {
+				group.Name = deviceName(device)
+				group.Manufacturer = device.Manufacturer
+				group.Model = string(device.Model)
+				group.AreaID = device.AreaID
+			}
+		}
+
+		if existing, ok := grouped[key]; ok {
+			group = existing
+		} else {
+			grouped[key] = group
+		}
+
+		group.Entities = append(group.Entities, state.EntityID)
+		if !state.LastChanged.IsZero() {
+			since := state.LastChanged.UTC().Format(time.RFC3339)
+			if group.Since == "" || since < group.Since {
+				group.Since = since
+			}
+		}
+	}
+
+	result := make([]UnavailableDevice, 0, len(grouped))
+	for _, group := range grouped {
+		sort.Strings(group.Entities)
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// deviceName returns the name a user sees for a device.
+func deviceName(device homeassistant.DeviceRegistryEntry) string {
+	if device.NameByUser != "" {
+		return device.NameByUser
+	}
+	if device.Name != "" {
+		return device.Name
+	}
+	return device.ID
+}
+
+// writeJSONBlock writes data as a fenced JSON code block.
+func writeJSONBlock(sb *strings.Builder, data []byte) {
+	sb.WriteString("```json\n")
+	sb.Write(data)
+	sb.WriteString("\n```\n")
+}
+
+// RegisterPrompts registers all prompts with the registry.
+func RegisterPrompts(registry *mcp.Registry) {
+	h := NewPromptHandlers()
+	h.RegisterPrompts(registry)
+}
@@ -0,0 +1,234 @@
+// Package handlers provides MCP tool handlers for Home Assistant operations.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/mcp"
+)
+
+func TestRegisterPrompts(t *testing.T) {
+	t.Parallel()
+
+	registry := mcp.NewRegistry()
+	RegisterPrompts(registry)
+
+	for _, name := range []string{"troubleshoot_entity", "design_area_automation", "review_unavailable_devices"} {
+		if _, _, ok := registry.GetPrompt(name); !ok {
+			t.Errorf("prompt %q not registered", name)
+		}
+	}
+}
+
+func TestPromptHandlers_TroubleshootEntity(t *testing.T) {
+	t.Parallel()
+
+	h := NewPromptHandlers()
+	client := &UniversalMockClient{
+		GetStateFn: func(_ context.Context, entityID string) (*homeassistant.Entity, error) {
+			return &homeassistant.Entity{EntityID: entityID, State: "on"}, nil
+		},
+	}
+
+	result, err := h.handleTroubleshootEntity(context.Background(), client, map[string]string{"entity_id": "light.kitchen"})
+	if err != nil {
+		t.Fatalf("handleTroubleshootEntity() error = %v", err)
+	}
+	if len(result.Messages) != 1 {
+		t.Fatalf("len(Messages) = %d, want 1", len(result.Messages))
+	}
+
+	text := result.Messages[0].Content.Text
+	for _, want := range []string{"light.kitchen", `"state": "on"`, "```json"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("prompt text missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestPromptHandlers_TroubleshootEntity_NotFound(t *testing.T) {
+	t.Parallel()
+
+	h := NewPromptHandlers()
+	client := &UniversalMockClient{
+		GetStateFn: func(_ context.Context, _ string) (*homeassistant.Entity, error) {
+			return nil, errors.New("entity not found")
+		},
+	}
+
+	if _, err := h.handleTroubleshootEntity(context.Background(), client, map[string]string{"entity_id": "light.nope"}); err == nil {
+		t.Error("handleTroubleshootEntity() error = nil, want error")
+	}
+}
+
+func TestPromptHandlers_BuildAreaAutomationContext(t *testing.T) {
+	t.Parallel()
+
+	var gotTarget homeassistant.Target
+	client := &UniversalMockClient{
+		GetAreaRegistryFn: func(_ context.Context) ([]homeassistant.AreaRegistryEntry, error) {
+			return []homeassistant.AreaRegistryEntry{
+				{AreaID: "kitchen", Name: "Kitchen"},
+				{AreaID: "office", Name: "Office"},
+			}, nil
+		},
+		GetEntityRegistryFn: func(_ context.Context) ([]homeassistant.EntityRegistryEntry, error) {
+			return []homeassistant.EntityRegistryEntry{
+				{EntityID: "light.kitchen", AreaID: "kitchen"},
+				{EntityID: "sensor.motion", DeviceID: "dev1"},
+				{EntityID: "sensor.override", DeviceID: "dev1", AreaID: "office"},
+				{EntityID: "switch.disabled", AreaID: "kitchen", DisabledBy: "user"},
+				{EntityID: "light.office", AreaID: "office"},
+			}, nil
+		},
+		GetDeviceRegistryFn: func(_ context.Context) ([]homeassistant.DeviceRegistryEntry, error) {
+			return []homeassistant.DeviceRegistryEntry{
+				{ID: "dev1", Name: "Motion Sensor", NameByUser: "Kitchen Motion", AreaID: "kitchen"},
+			}, nil
+		},
+		GetStatesFn: func(_ context.Context) ([]homeassistant.Entity, error) {
+			return []homeassistant.Entity{
+				{EntityID: "light.kitchen", State: "off", Attributes: map[string]any{"friendly_name": "Kitchen Light"}},
+				{EntityID: "sensor.motion", State: "on"},
+			}, nil
+		},
+		GetTriggersForTargetFn: func(_ context.Context, target homeassistant.Target, _ *bool) ([]string, error) {
+			gotTarget = target
+			return []string{"state", "device"}, nil
+		},
+	}
+
+	got, err := NewPromptHandlers().buildAreaAutomationContext(context.Background(), client, "kitchen")
+	if err != nil {
+		t.Fatalf("buildAreaAutomationContext() error = %v", err)
+	}
+
+	want := &AreaAutomationContext{
+		AreaID: "kitchen",
+		Name:   "Kitchen",
+		Entities: []AreaEntity{
+			{EntityID: "light.kitchen", FriendlyName: "Kitchen Light", State: "off"},
+			{EntityID: "sensor.motion", State: "on", Device: "Kitchen Motion"},
+		},
+		Triggers: []string{"state", "device"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("buildAreaAutomationContext() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(homeassistant.Target{AreaID: []string{"kitchen"}}, gotTarget); diff != "" {
+		t.Errorf("GetTriggersForTarget() target mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPromptHandlers_DesignAreaAutomation_UnknownArea(t *testing.T) {
+	t.Parallel()
+
+	h := NewPromptHandlers()
+	_, err := h.handleDesignAreaAutomation(context.Background(), &UniversalMockClient{}, map[string]string{"area_id": "garage"})
+	if err == nil || !strings.Contains(err.Error(), "area not found") {
+		t.Errorf("handleDesignAreaAutomation() error = %v, want area not found", err)
+	}
+}
+
+func TestPromptHandlers_BuildUnavailableDevices(t *testing.T) {
+	t.Parallel()
+
+	early := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	client := &UniversalMockClient{
+		GetStatesFn: func(_ context.Context) ([]homeassistant.Entity, error) {
+			return []homeassistant.Entity{
+				{EntityID: "sensor.plug_power", State: "unavailable", LastChanged: late},
+				{EntityID: "switch.plug", State: "unavailable", LastChanged: early},
+				{EntityID: "sensor.orphan", State: "unavailable"},
+				{EntityID: "light.ok", State: "on"},
+			}, nil
+		},
+		GetEntityRegistryFn: func(_ context.Context) ([]homeassistant.EntityRegistryEntry, error) {
+			return []homeassistant.EntityRegistryEntry{
+				{EntityID: "sensor.plug_power", DeviceID: "plug"},
+				{EntityID: "switch.plug", DeviceID: "plug"},
+			}, nil
+		},
+		GetDeviceRegistryFn: func(_ context.Context) ([]homeassistant.DeviceRegistryEntry, error) {
+			return []homeassistant.DeviceRegistryEntry{
+				{ID: "plug", Name: "Smart Plug", Manufacturer: "Acme", Model: "P1", AreaID: "office"},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name   string
+		domain string
+		want   []UnavailableDevice
+	}{
+		{
+			name: "groups by device",
+			want: []UnavailableDevice{
+				{
+					DeviceID:     "plug",
+					Name:         "Smart Plug",
+					Manufacturer: "Acme",
+					Model:        "P1",
+					AreaID:       "office",
+					Entities:     []string{"sensor.plug_power", "switch.plug"},
+					Since:        early.Format(time.RFC3339),
+				},
+				{Name: "sensor.orphan", Entities: []string{"sensor.orphan"}},
+			},
+		},
+		{
+			name:   "filters by domain",
+			domain: "switch",
+			want: []UnavailableDevice{
+				{
+					DeviceID:     "plug",
+					Name:         "Smart Plug",
+					Manufacturer: "Acme",
+					Model:        "P1",
+					AreaID:       "office",
+					Entities:     []string{"switch.plug"},
+					Since:        early.Format(time.RFC3339),
+				},
+			},
+		},
+		{
+			name:   "nothing unavailable",
+			domain: "light",
+			want:   []UnavailableDevice{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewPromptHandlers().buildUnavailableDevices(context.Background(), client, tt.domain)
+			if err != nil {
+				t.Fatalf("buildUnavailableDevices() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("buildUnavailableDevices() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPromptHandlers_ReviewUnavailableDevices_NoneUnavailable(t *testing.T) {
+	t.Parallel()
+
+	result, err := NewPromptHandlers().handleReviewUnavailableDevices(context.Background(), &UniversalMockClient{}, map[string]string{})
+	if err != nil {
+		t.Fatalf("handleReviewUnavailableDevices() error = %v", err)
+	}
+	if len(result.Messages) != 1 || !strings.Contains(result.Messages[0].Content.Text, "no unavailable entities") {
+		t.Errorf("Messages = %+v, want a no-unavailable message", result.Messages)
+	}
+}
@@ -132,47 +132,99 @@ func RegisterLovelaceTools(registry *mcp.Registry) {
 
 // RegisterAnalysisTools is defined in analysis.go
 
-// RegisterAllTools registers all available tool handlers with the registry.
+// RegisterAllTools registers all available tool handlers with the registry,
+// grouped into toolsets, followed by the toolset meta tools.
 // All handlers use the WebSocket API for communication with Home Assistant.
 func RegisterAllTools(registry *mcp.Registry) {
-	// Core entity and automation handlers
-	RegisterEntityTools(registry)
-	RegisterAutomationTools(registry)
-	RegisterScriptTools(registry)
-	RegisterSceneTools(registry)
-
-	// Helper tools (generic list_helpers)
-	RegisterHelperTools(registry)
+	// Core entity tools, the generic list_helpers, and statistics
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetCore,
+		Description: "Entity states, history, statistics, domains, and the generic helper list",
+	}, func(r *mcp.Registry) {
+		RegisterEntityTools(r)
+		RegisterHelperTools(r)
+		RegisterStatisticsTools(r)
+	})
+
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetAutomations,
+		Description: "List, inspect, create, update, delete, toggle, and trigger automations",
+	}, RegisterAutomationTools)
+
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetScriptsScenes,
+		Description: "Scripts, scenes, and generic service calls",
+	}, func(r *mcp.Registry) {
+		RegisterScriptTools(r)
+		RegisterSceneTools(r)
+	})
 
 	// Input helper handlers (create, delete, actions)
-	RegisterInputBooleanTools(registry)
-	RegisterInputNumberTools(registry)
-	RegisterInputTextTools(registry)
-	RegisterInputSelectTools(registry)
-	RegisterInputDatetimeTools(registry)
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetInputHelpers,
+		Description: "input_boolean, input_number, input_text, input_select, and input_datetime helpers",
+	}, func(r *mcp.Registry) {
+		RegisterInputBooleanTools(r)
+		RegisterInputNumberTools(r)
+		RegisterInputTextTools(r)
+		RegisterInputSelectTools(r)
+		RegisterInputDatetimeTools(r)
+	})
 
 	// Tier 1 helper handlers (input_button, counter, timer)
-	RegisterInputButtonTools(registry)
-	RegisterCounterTools(registry)
-	RegisterTimerTools(registry)
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetHelpersTier1,
+		Description: "input_button, counter, and timer helpers",
+	}, func(r *mcp.Registry) {
+		RegisterInputButtonTools(r)
+		RegisterCounterTools(r)
+		RegisterTimerTools(r)
+	})
 
 	// Tier 2 helper handlers (schedule, group)
-	RegisterScheduleTools(registry)
-	RegisterGroupTools(registry)
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetHelpersTier2,
+		Description: "schedule and group helpers",
+	}, func(r *mcp.Registry) {
+		RegisterScheduleTools(r)
+		RegisterGroupTools(r)
+	})
 
 	// Tier 3 helper handlers (template, threshold, derivative, integral)
-	RegisterTemplateHelperTools(registry)
-	RegisterThresholdTools(registry)
-	RegisterDerivativeTools(registry)
-	RegisterIntegralTools(registry)
-
-	// Registry, media, and advanced handlers
-	RegisterRegistryTools(registry)
-	RegisterMediaTools(registry)
-	RegisterStatisticsTools(registry)
-	RegisterLovelaceTools(registry)
-	RegisterTargetTools(registry)
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetHelpersTier3,
+		Description: "template, threshold, derivative, and integral helpers",
+	}, func(r *mcp.Registry) {
+		RegisterTemplateHelperTools(r)
+		RegisterThresholdTools(r)
+		RegisterDerivativeTools(r)
+		RegisterIntegralTools(r)
+	})
+
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetRegistries,
+		Description: "Entity, device, and area registries",
+	}, RegisterRegistryTools)
+
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetMedia,
+		Description: "Media browsing, camera streams, signed media paths, and Lovelace dashboards",
+	}, func(r *mcp.Registry) {
+		RegisterMediaTools(r)
+		RegisterLovelaceTools(r)
+	})
+
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetTargets,
+		Description: "Triggers, conditions, and services available for a target, and target expansion",
+	}, RegisterTargetTools)
 
 	// Analysis tools for entity dependency tracking
-	RegisterAnalysisTools(registry)
+	registry.RegisterToolset(mcp.Toolset{
+		Name:        ToolsetAnalysis,
+		Description: "Entity analysis and dependency tracking across automations and scripts",
+	}, RegisterAnalysisTools)
+
+	// Meta tools, always enabled
+	RegisterToolsetTools(registry)
 }
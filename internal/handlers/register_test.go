@@ -1,8 +1,16 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
 	"github.com/zorak1103/ha-mcp/internal/mcp"
 )
 
@@ -368,3 +376,145 @@ func TestRegisterAllTools_AllToolsHaveInputSchema(t *testing.T) {
 		}
 	}
 }
+
+func TestRegisterAllTools_AllToolsHaveAnnotations(t *testing.T) {
+	t.Parallel()
+
+	registry := mcp.NewRegistry()
+	RegisterAllTools(registry)
+
+	for _, tool := range registry.ListTools() {
+		a := tool.Annotations
+		if a == nil || a.Title == "" {
+			t.Errorf("Tool %q has no annotations title", tool.Name)
+			continue
+		}
+
+		if a.ReadOnlyHint && a.DestructiveHint {
+			t.Errorf("Tool %q is both read-only and destructive", tool.Name)
+		}
+		if strings.HasPrefix(tool.Name, "get_") || strings.HasPrefix(tool.Name, "list_") {
+			if !a.ReadOnlyHint {
+				t.Errorf("Tool %q should be read-only", tool.Name)
+			}
+		}
+		if strings.HasPrefix(tool.Name, "delete_") && !a.DestructiveHint {
+			t.Errorf("Tool %q should be destructive", tool.Name)
+		}
+		if strings.HasPrefix(tool.Name, "create_") && (a.ReadOnlyHint || a.DestructiveHint || a.IdempotentHint) {
+			t.Errorf("Tool %q should be additive and not idempotent", tool.Name)
+		}
+	}
+}
+
+func TestRegisterAllTools_StructuredContentMatchesOutputSchema(t *testing.T) {
+	t.Parallel()
+
+	client := &UniversalMockClient{
+		GetStatesFn: func(_ context.Context) ([]homeassistant.Entity, error) {
+			return []homeassistant.Entity{{EntityID: "light.kitchen", State: "on"}}, nil
+		},
+		GetStateFn: func(_ context.Context, entityID string) (*homeassistant.Entity, error) {
+			return &homeassistant.Entity{EntityID: entityID, State: "on"}, nil
+		},
+		GetHistoryFn: func(_ context.Context, entityID string, _, _ time.Time) ([][]homeassistant.HistoryEntry, error) {
+			return [][]homeassistant.HistoryEntry{{{EntityID: entityID, State: "on", LastChanged: 1700000000}}}, nil
+		},
+		GetStatisticsFn: func(_ context.Context, statIDs []string, _ string) ([]homeassistant.StatisticsResult, error) {
+			return []homeassistant.StatisticsResult{{StatisticID: statIDs[0], Start: 1700000000}}, nil
+		},
+		GetEntityRegistryFn: func(_ context.Context) ([]homeassistant.EntityRegistryEntry, error) {
+			return []homeassistant.EntityRegistryEntry{{EntityID: "light.kitchen"}}, nil
+		},
+		GetDeviceRegistryFn: func(_ context.Context) ([]homeassistant.DeviceRegistryEntry, error) {
+			return []homeassistant.DeviceRegistryEntry{{ID: "dev1"}}, nil
+		},
+		GetAreaRegistryFn: func(_ context.Context) ([]homeassistant.AreaRegistryEntry, error) {
+			return []homeassistant.AreaRegistryEntry{{AreaID: "kitchen", Name: "Kitchen"}}, nil
+		},
+	}
+
+	args := map[string]map[string]any{
+		"get_state":      {"entity_id": "light.kitchen"},
+		"get_history":    {"entity_id": "light.kitchen"},
+		"get_statistics": {"statistic_ids": []any{"sensor.energy"}},
+	}
+
+	registry := mcp.NewRegistry()
+	RegisterAllTools(registry)
+
+	var checked []string
+	for _, tool := range registry.ListTools() {
+		if tool.OutputSchema == nil {
+			continue
+		}
+		checked = append(checked, tool.Name)
+
+		handler, _ := registry.GetHandler(tool.Name)
+		result, err := handler(context.Background(), client, args[tool.Name])
+		if err != nil || result.IsError {
+			t.Errorf("%s: handler() = %+v, %v", tool.Name, result, err)
+			continue
+		}
+
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			t.Fatalf("%s: json.Marshal() error = %v", tool.Name, err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Errorf("%s: structured content is not an object: %s", tool.Name, data)
+			continue
+		}
+		for _, key := range tool.OutputSchema.Required {
+			if _, ok := got[key]; !ok {
+				t.Errorf("%s: structured content lacks required %q: %s", tool.Name, key, data)
+			}
+		}
+	}
+
+	sort.Strings(checked)
+	want := []string{
+		"get_history", "get_state", "get_states", "get_statistics",
+		"list_area_registry", "list_device_registry", "list_entity_registry",
+	}
+	if diff := cmp.Diff(want, checked); diff != "" {
+		t.Errorf("tools with output schema mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRegisterAllTools_ArgumentValidation(t *testing.T) {
+	t.Parallel()
+
+	registry := mcp.NewRegistry()
+	RegisterAllTools(registry)
+
+	tests := []struct {
+		tool        string
+		args        map[string]any
+		wantPointer string // empty if the arguments are valid
+	}{
+		{tool: "delete_counter", args: map[string]any{"entity_id": "counter.visits"}},
+		{tool: "delete_counter", args: map[string]any{"entity_id": "switch.visits"}, wantPointer: "/entity_id"},
+		{tool: "delete_template_helper", args: map[string]any{"entity_id": "binary_sensor.tpl"}},
+		{tool: "get_history", args: map[string]any{"entity_id": "sensor.t", "limit": float64(0)}, wantPointer: "/limit"},
+		{tool: "get_statistics", args: map[string]any{"statistic_ids": []any{"sensor.e"}, "period": "year"}, wantPointer: "/period"},
+		{tool: "get_statistics", args: map[string]any{"statistic_ids": []any{float64(1)}}, wantPointer: "/statistic_ids/0"},
+		{tool: "get_state", args: map[string]any{}, wantPointer: "/entity_id"},
+	}
+
+	for _, tt := range tests {
+		err := registry.ValidateArguments(tt.tool, tt.args)
+		if tt.wantPointer == "" {
+			if err != nil {
+				t.Errorf("%s(%v): unexpected error %v", tt.tool, tt.args, err)
+			}
+			continue
+		}
+
+		var verr *mcp.ValidationError
+		if !errors.As(err, &verr) || verr.Pointer != tt.wantPointer {
+			t.Errorf("%s(%v): error = %v, want pointer %s", tt.tool, tt.args, err, tt.wantPointer)
+		}
+	}
+}
@@ -31,10 +31,38 @@ func (h *RegistryHandlers) listEntityRegistryTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "list_entity_registry",
 		Description: "List entries in the Home Assistant entity registry. By default returns a compact list with only entity_id. Use filters to narrow down results and 'verbose' for full details. Note: Most entity info can also be obtained via get_states.",
+		Annotations: readOnlyAnnotations("List Entity Registry"),
+		OutputSchema: &mcp.JSONSchema{
+			Type: "object",
+			Properties: withPageOutputProperties(map[string]mcp.JSONSchema{
+				"count": {Type: "integer", Description: "Number of entries on this page"},
+				"entities": {
+					Type:        "array",
+					Description: "Matching entity registry entries in compact or verbose form, sorted by entity_id",
+					Items: &mcp.JSONSchema{
+						Type: "object",
+						Properties: map[string]mcp.JSONSchema{
+							"entity_id":       {Type: "string"},
+							"device_id":       {Type: "string"},
+							"area_id":         {Type: "string"},
+							"platform":        {Type: "string"},
+							"config_entry_id": {Type: "string"},
+							"disabled_by":     {Type: "string"},
+							"hidden_by":       {Type: "string"},
+							"name":            {Type: "string"},
+							"icon":            {Type: "string"},
+							"unique_id":       {Type: "string"},
+						},
+						Required: []string{"entity_id"},
+					},
+				},
+			}, "entries"),
+			Required: []string{"count", "entities"},
+		},
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
-			Description: "Filter and output options for entity registry",
-			Properties: map[string]mcp.JSONSchema{
+			Description: "Filter, paging, and output options for entity registry",
+			Properties: withPaginationProperties(map[string]mcp.JSONSchema{
 				"domain": {
 					Type:        "string",
 					Description: "Filter by domain (e.g., 'light', 'switch', 'sensor')",
@@ -59,11 +87,19 @@ func (h *RegistryHandlers) listEntityRegistryTool() mcp.Tool {
 					Type:        "boolean",
 					Description: "If true, include disabled entities. Default: false",
 				},
-			},
+			}, "entries"),
 		},
 	}
 }
 
+// entityRegistryOutput is the structured content of list_entity_registry.
+type entityRegistryOutput struct {
+	Count      int             `json:"count"`
+	TotalCount int             `json:"total_count"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Entities   json.RawMessage `json:"entities"`
+}
+
 // compactEntityEntry represents a minimal entity registry entry for compact output.
 type compactEntityEntry struct {
 	EntityID string `json:"entity_id"`
@@ -203,8 +239,18 @@ func (h *RegistryHandlers) handleListEntityRegistry(
 	filter.buildDeviceIDsInArea(ctx, client)
 	filtered := filter.filterEntityRegistry(entries)
 
+	page, next, err := paginate(filtered, func(e homeassistant.EntityRegistryEntry) string { return e.EntityID }, args)
+	if err != nil {
+		return &mcp.ToolsCallResult{
+			Content: []mcp.ContentBlock{
+				mcp.NewTextContent(fmt.Sprintf("Error paging entity registry: %v", err)),
+			},
+			IsError: true,
+		}, nil
+	}
+
 	verbose, _ := args["verbose"].(bool)
-	output, err := formatEntityRegistryOutput(filtered, verbose)
+	output, err := formatEntityRegistryOutput(page, verbose)
 	if err != nil {
 		return &mcp.ToolsCallResult{
 			Content: []mcp.ContentBlock{
@@ -214,15 +260,22 @@ func (h *RegistryHandlers) handleListEntityRegistry(
 		}, nil
 	}
 
-	summary := fmt.Sprintf("Found %d entities", len(filtered))
+	summary := pageSummary("entities", len(page), len(filtered))
 	if !verbose {
 		summary += VerboseHint
 	}
+	summary += nextPageHint(next)
 
 	return &mcp.ToolsCallResult{
 		Content: []mcp.ContentBlock{
 			mcp.NewTextContent(summary + "\n\n" + output),
 		},
+		StructuredContent: entityRegistryOutput{
+			Count:      len(page),
+			TotalCount: len(filtered),
+			NextCursor: next,
+			Entities:   json.RawMessage(output),
+		},
 	}, nil
 }
 
@@ -241,10 +294,33 @@ func (h *RegistryHandlers) listDeviceRegistryTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "list_device_registry",
 		Description: "List entries in the Home Assistant device registry. By default returns a compact list with id, name, manufacturer, model, and area_id. Use filters to narrow down results and 'verbose' for full details.",
+		Annotations: readOnlyAnnotations("List Device Registry"),
+		OutputSchema: &mcp.JSONSchema{
+			Type: "object",
+			Properties: withPageOutputProperties(map[string]mcp.JSONSchema{
+				"count": {Type: "integer", Description: "Number of devices on this page"},
+				"devices": {
+					Type:        "array",
+					Description: "Matching device registry entries in compact or verbose form, sorted by id",
+					Items: &mcp.JSONSchema{
+						Type: "object",
+						Properties: map[string]mcp.JSONSchema{
+							"id":           {Type: "string"},
+							"name":         {Type: "string"},
+							"manufacturer": {Type: "string"},
+							"model":        {Type: "string"},
+							"area_id":      {Type: "string"},
+						},
+						Required: []string{"id"},
+					},
+				},
+			}, "devices"),
+			Required: []string{"count", "devices"},
+		},
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
-			Description: "Filter and output options for device registry",
-			Properties: map[string]mcp.JSONSchema{
+			Description: "Filter, paging, and output options for device registry",
+			Properties: withPaginationProperties(map[string]mcp.JSONSchema{
 				"area_id": {
 					Type:        "string",
 					Description: "Filter by area ID to get all devices in a specific area",
@@ -265,11 +341,19 @@ func (h *RegistryHandlers) listDeviceRegistryTool() mcp.Tool {
 					Type:        "boolean",
 					Description: "If true, include disabled devices. Default: false",
 				},
-			},
+			}, "devices"),
 		},
 	}
 }
 
+// deviceRegistryOutput is the structured content of list_device_registry.
+type deviceRegistryOutput struct {
+	Count      int             `json:"count"`
+	TotalCount int             `json:"total_count"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Devices    json.RawMessage `json:"devices"`
+}
+
 // compactDeviceEntry represents a minimal device registry entry for compact output.
 type compactDeviceEntry struct {
 	ID           string `json:"id"`
@@ -364,8 +448,16 @@ func (h *RegistryHandlers) handleListDeviceRegistry(
 	filter := parseDeviceRegistryFilter(args)
 	filtered := filterDeviceRegistry(entries, filter)
 
+	page, next, err := paginate(filtered, func(e homeassistant.DeviceRegistryEntry) string { return e.ID }, args)
+	if err != nil {
+		return &mcp.ToolsCallResult{
+			Content: []mcp.ContentBlock{mcp.NewTextContent(fmt.Sprintf("Error paging device registry: %v", err))},
+			IsError: true,
+		}, nil
+	}
+
 	verbose, _ := args["verbose"].(bool)
-	output, err := formatDeviceRegistryOutput(filtered, verbose)
+	output, err := formatDeviceRegistryOutput(page, verbose)
 	if err != nil {
 		return &mcp.ToolsCallResult{
 			Content: []mcp.ContentBlock{mcp.NewTextContent(fmt.Sprintf("Error formatting response: %v", err))},
@@ -373,13 +465,20 @@ func (h *RegistryHandlers) handleListDeviceRegistry(
 		}, nil
 	}
 
-	summary := fmt.Sprintf("Found %d devices", len(filtered))
+	summary := pageSummary("devices", len(page), len(filtered))
 	if !verbose {
 		summary += VerboseHint
 	}
+	summary += nextPageHint(next)
 
 	return &mcp.ToolsCallResult{
 		Content: []mcp.ContentBlock{mcp.NewTextContent(summary + "\n\n" + string(output))},
+		StructuredContent: deviceRegistryOutput{
+			Count:      len(page),
+			TotalCount: len(filtered),
+			NextCursor: next,
+			Devices:    output,
+		},
 	}, nil
 }
 
@@ -388,6 +487,27 @@ func (h *RegistryHandlers) listAreaRegistryTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "list_area_registry",
 		Description: "List all entries in the Home Assistant area registry. Returns information about defined areas including their names, pictures, and aliases.",
+		Annotations: readOnlyAnnotations("List Area Registry"),
+		OutputSchema: &mcp.JSONSchema{
+			Type: "object",
+			Properties: map[string]mcp.JSONSchema{
+				"count": {Type: "integer", Description: "Number of areas"},
+				"areas": {
+					Type: "array",
+					Items: &mcp.JSONSchema{
+						Type: "object",
+						Properties: map[string]mcp.JSONSchema{
+							"area_id": {Type: "string"},
+							"name":    {Type: "string"},
+							"picture": {Type: "string"},
+							"aliases": {Type: "array", Items: &mcp.JSONSchema{Type: "string"}},
+						},
+						Required: []string{"area_id", "name"},
+					},
+				},
+			},
+			Required: []string{"count", "areas"},
+		},
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Properties:  map[string]mcp.JSONSchema{},
@@ -396,6 +516,12 @@ func (h *RegistryHandlers) listAreaRegistryTool() mcp.Tool {
 	}
 }
 
+// areaRegistryOutput is the structured content of list_area_registry.
+type areaRegistryOutput struct {
+	Count int                               `json:"count"`
+	Areas []homeassistant.AreaRegistryEntry `json:"areas"`
+}
+
 // handleListAreaRegistry handles requests to list area registry entries.
 func (h *RegistryHandlers) handleListAreaRegistry(
 	ctx context.Context,
@@ -422,9 +548,14 @@ func (h *RegistryHandlers) handleListAreaRegistry(
 		}, nil
 	}
 
+	if entries == nil {
+		entries = []homeassistant.AreaRegistryEntry{}
+	}
+
 	return &mcp.ToolsCallResult{
 		Content: []mcp.ContentBlock{
 			mcp.NewTextContent(string(output)),
 		},
+		StructuredContent: areaRegistryOutput{Count: len(entries), Areas: entries},
 	}, nil
 }
@@ -0,0 +1,274 @@
+// Package handlers provides MCP tool handlers for Home Assistant operations.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/mcp"
+)
+
+// URI templates of the Home Assistant resources.
+const (
+	entityResourceTemplate     = mcp.EntityResourceTemplate
+	automationResourceTemplate = "hass://automation/{id}"
+	scriptResourceTemplate     = "hass://script/{id}"
+	areaResourceTemplate       = "hass://area/{area_id}"
+	lovelaceResourceTemplate   = "hass://lovelace/{dashboard}"
+)
+
+// defaultDashboard is the {dashboard} value that selects the default Lovelace dashboard.
+const defaultDashboard = "default"
+
+// mimeTypeJSON is the MIME type of all Home Assistant resources.
+const mimeTypeJSON = "application/json"
+
+// ResourceHandlers provides read-only MCP resources for Home Assistant objects.
+type ResourceHandlers struct{}
+
+// NewResourceHandlers creates a new ResourceHandlers instance.
+func NewResourceHandlers() *ResourceHandlers {
+	return &ResourceHandlers{}
+}
+
+// RegisterResources registers all resource templates with the registry.
+// Entities are not enumerated in resources/list because there are usually
+// too many; they are reachable through their template.
+func (h *ResourceHandlers) RegisterResources(registry *mcp.Registry) {
+	registry.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: entityResourceTemplate,
+		Name:        "Entity state",
+		Description: "Current state and attributes of an entity",
+		MimeType:    mimeTypeJSON,
+	}, h.readEntity, nil)
+
+	registry.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: automationResourceTemplate,
+		Name:        "Automation",
+		Description: "Full configuration of an automation, including triggers, conditions, and actions",
+		MimeType:    mimeTypeJSON,
+	}, h.readAutomation, h.listAutomations)
+
+	registry.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: scriptResourceTemplate,
+		Name:        "Script",
+		Description: "State and full configuration of a script",
+		MimeType:    mimeTypeJSON,
+	}, h.readScript, h.listScripts)
+
+	registry.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: areaResourceTemplate,
+		Name:        "Area",
+		Description: "An area with its entities and their current states",
+		MimeType:    mimeTypeJSON,
+	}, h.readArea, h.listAreas)
+
+	registry.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: lovelaceResourceTemplate,
+		Name:        "Lovelace dashboard",
+		Description: "Configuration of a Lovelace dashboard by URL path; 'default' selects the default dashboard",
+		MimeType:    mimeTypeJSON,
+	}, h.readDashboard, h.listDashboards)
+}
+
+func (h *ResourceHandlers) readEntity(ctx context.Context, client homeassistant.Client, uri string) (*mcp.ResourcesReadResult, error) {
+	vars, ok := mcp.MatchURITemplate(entityResourceTemplate, uri)
+	if !ok {
+		return nil, fmt.Errorf("invalid entity resource URI: %s", uri)
+	}
+
+	state, err := client.GetState(ctx, vars["entity_id"])
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(uri, state)
+}
+
+func (h *ResourceHandlers) readAutomation(ctx context.Context, client homeassistant.Client, uri string) (*mcp.ResourcesReadResult, error) {
+	vars, ok := mcp.MatchURITemplate(automationResourceTemplate, uri)
+	if !ok {
+		return nil, fmt.Errorf("invalid automation resource URI: %s", uri)
+	}
+
+	automation, err := client.GetAutomation(ctx, vars["id"])
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(uri, automation)
+}
+
+func (h *ResourceHandlers) listAutomations(ctx context.Context, client homeassistant.Client) ([]mcp.Resource, error) {
+	automations, err := client.ListAutomations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing automations: %w", err)
+	}
+
+	resources := make([]mcp.Resource, 0, len(automations))
+	for _, a := range automations {
+		resources = append(resources, mcp.Resource{
+			URI:         resourceURI(automationResourceTemplate, strings.TrimPrefix(a.EntityID, "automation.")),
+			Name:        nameOrID(a.FriendlyName, a.EntityID),
+			Description: "Automation " + a.EntityID,
+			MimeType:    mimeTypeJSON,
+		})
+	}
+	return resources, nil
+}
+
+func (h *ResourceHandlers) readScript(ctx context.Context, client homeassistant.Client, uri string) (*mcp.ResourcesReadResult, error) {
+	vars, ok := mcp.MatchURITemplate(scriptResourceTemplate, uri)
+	if !ok {
+		return nil, fmt.Errorf("invalid script resource URI: %s", uri)
+	}
+
+	script, err := client.GetScript(ctx, vars["id"])
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(uri, script)
+}
+
+func (h *ResourceHandlers) listScripts(ctx context.Context, client homeassistant.Client) ([]mcp.Resource, error) {
+	scripts, err := client.ListScripts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing scripts: %w", err)
+	}
+
+	resources := make([]mcp.Resource, 0, len(scripts))
+	for _, s := range scripts {
+		friendlyName, _ := s.Attributes["friendly_name"].(string)
+		resources = append(resources, mcp.Resource{
+			URI:         resourceURI(scriptResourceTemplate, strings.TrimPrefix(s.EntityID, "script.")),
+			Name:        nameOrID(friendlyName, s.EntityID),
+			Description: "Script " + s.EntityID,
+			MimeType:    mimeTypeJSON,
+		})
+	}
+	return resources, nil
+}
+
+// AreaResource is the content of an hass://area/{area_id} resource.
+type AreaResource struct {
+	homeassistant.AreaRegistryEntry
+	Entities []AreaEntity `json:"entities"`
+}
+
+func (h *ResourceHandlers) readArea(ctx context.Context, client homeassistant.Client, uri string) (*mcp.ResourcesReadResult, error) {
+	vars, ok := mcp.MatchURITemplate(areaResourceTemplate, uri)
+	if !ok {
+		return nil, fmt.Errorf("invalid area resource URI: %s", uri)
+	}
+
+	area, err := findArea(ctx, client, vars["area_id"])
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := collectAreaEntities(ctx, client, area.AreaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonResource(uri, AreaResource{AreaRegistryEntry: *area, Entities: entities})
+}
+
+func (h *ResourceHandlers) listAreas(ctx context.Context, client homeassistant.Client) ([]mcp.Resource, error) {
+	areas, err := client.GetAreaRegistry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing areas: %w", err)
+	}
+
+	resources := make([]mcp.Resource, 0, len(areas))
+	for _, a := range areas {
+		resources = append(resources, mcp.Resource{
+			URI:         resourceURI(areaResourceTemplate, a.AreaID),
+			Name:        nameOrID(a.Name, a.AreaID),
+			Description: "Area " + a.AreaID,
+			MimeType:    mimeTypeJSON,
+		})
+	}
+	return resources, nil
+}
+
+func (h *ResourceHandlers) readDashboard(ctx context.Context, client homeassistant.Client, uri string) (*mcp.ResourcesReadResult, error) {
+	vars, ok := mcp.MatchURITemplate(lovelaceResourceTemplate, uri)
+	if !ok {
+		return nil, fmt.Errorf("invalid dashboard resource URI: %s", uri)
+	}
+
+	urlPath := vars["dashboard"]
+	if urlPath == defaultDashboard {
+		urlPath = ""
+	}
+
+	config, err := client.GetDashboardConfig(ctx, urlPath)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(uri, config)
+}
+
+func (h *ResourceHandlers) listDashboards(ctx context.Context, client homeassistant.Client) ([]mcp.Resource, error) {
+	dashboards, err := client.ListDashboards(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing dashboards: %w", err)
+	}
+
+	resources := make([]mcp.Resource, 0, len(dashboards)+1)
+	resources = append(resources, mcp.Resource{
+		URI:         resourceURI(lovelaceResourceTemplate, defaultDashboard),
+		Name:        "Overview",
+		Description: "Default Lovelace dashboard",
+		MimeType:    mimeTypeJSON,
+	})
+	for _, d := range dashboards {
+		resources = append(resources, mcp.Resource{
+			URI:         resourceURI(lovelaceResourceTemplate, d.URLPath),
+			Name:        nameOrID(d.Title, d.URLPath),
+			Description: "Lovelace dashboard " + d.URLPath,
+			MimeType:    mimeTypeJSON,
+		})
+	}
+	return resources, nil
+}
+
+// resourceURI fills the single variable of a URI template with an escaped value.
+func resourceURI(template, value string) string {
+	start := strings.Index(template, "{")
+	end := strings.Index(template, "}")
+	return template[:start] + url.PathEscape(value) + template[end+1:]
+}
+
+// nameOrID returns name, or id if name is empty.
+func nameOrID(name, id string) string {
+	if name != "" {
+		return name
+	}
+	return id
+}
+
+// jsonResource returns v as the JSON content of the resource at uri.
+func jsonResource(uri string, v any) (*mcp.ResourcesReadResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("formatting resource: %w", err)
+	}
+
+	return &mcp.ResourcesReadResult{
+		Contents: []mcp.ResourceContent{{
+			URI:      uri,
+			MimeType: mimeTypeJSON,
+			Text:     string(data),
+		}},
+	}, nil
+}
+
+// RegisterResources registers all resource templates with the registry.
+func RegisterResources(registry *mcp.Registry) {
+	h := NewResourceHandlers()
+	h.RegisterResources(registry)
+}
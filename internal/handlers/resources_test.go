@@ -0,0 +1,189 @@
+// Package handlers provides MCP tool handlers for Home Assistant operations.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/mcp"
+)
+
+func TestRegisterResources(t *testing.T) {
+	t.Parallel()
+
+	registry := mcp.NewRegistry()
+	RegisterResources(registry)
+
+	if got := registry.ResourceTemplateCount(); got != 5 {
+		t.Errorf("ResourceTemplateCount() = %d, want 5", got)
+	}
+
+	for _, uri := range []string{
+		"hass://entity/light.kitchen",
+		"hass://automation/morning",
+		"hass://script/bedtime",
+		"hass://area/kitchen",
+		"hass://lovelace/default",
+	} {
+		if _, ok := registry.GetResourceHandler(uri); !ok {
+			t.Errorf("no handler for %s", uri)
+		}
+	}
+}
+
+func TestResourceHandlers_Read(t *testing.T) {
+	t.Parallel()
+
+	client := &UniversalMockClient{
+		GetStateFn: func(_ context.Context, entityID string) (*homeassistant.Entity, error) {
+			if entityID != "light.kitchen" {
+				return nil, errors.New("entity not found")
+			}
+			return &homeassistant.Entity{EntityID: entityID, State: "on"}, nil
+		},
+		GetAutomationFn: func(_ context.Context, automationID string) (*homeassistant.Automation, error) {
+			return &homeassistant.Automation{
+				EntityID: "automation." + automationID,
+				Config:   &homeassistant.AutomationConfig{Alias: "Morning"},
+			}, nil
+		},
+		GetAreaRegistryFn: func(_ context.Context) ([]homeassistant.AreaRegistryEntry, error) {
+			return []homeassistant.AreaRegistryEntry{{AreaID: "kitchen", Name: "Kitchen"}}, nil
+		},
+		GetEntityRegistryFn: func(_ context.Context) ([]homeassistant.EntityRegistryEntry, error) {
+			return []homeassistant.EntityRegistryEntry{{EntityID: "light.kitchen", AreaID: "kitchen"}}, nil
+		},
+		GetDashboardConfigFn: func(_ context.Context, urlPath string) (map[string]any, error) {
+			return map[string]any{"url_path": urlPath}, nil
+		},
+	}
+
+	registry := mcp.NewRegistry()
+	RegisterResources(registry)
+
+	tests := []struct {
+		uri      string
+		wantErr  bool
+		wantJSON map[string]any
+	}{
+		{
+			uri:      "hass://entity/light.kitchen",
+			wantJSON: map[string]any{"entity_id": "light.kitchen", "state": "on"},
+		},
+		{
+			uri:     "hass://entity/light.missing",
+			wantErr: true,
+		},
+		{
+			uri:      "hass://automation/morning",
+			wantJSON: map[string]any{"entity_id": "automation.morning"},
+		},
+		{
+			uri:      "hass://area/kitchen",
+			wantJSON: map[string]any{"area_id": "kitchen", "name": "Kitchen"},
+		},
+		{
+			uri:     "hass://area/garage",
+			wantErr: true,
+		},
+		{
+			uri:      "hass://lovelace/default",
+			wantJSON: map[string]any{"url_path": ""},
+		},
+		{
+			uri:      "hass://lovelace/dashboard-energy",
+			wantJSON: map[string]any{"url_path": "dashboard-energy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			t.Parallel()
+
+			handler, ok := registry.GetResourceHandler(tt.uri)
+			if !ok {
+				t.Fatalf("GetResourceHandler(%q) not found", tt.uri)
+			}
+
+			result, err := handler(context.Background(), client, tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(result.Contents) != 1 {
+				t.Fatalf("len(Contents) = %d, want 1", len(result.Contents))
+			}
+			content := result.Contents[0]
+			if content.URI != tt.uri || content.MimeType != "application/json" {
+				t.Errorf("content URI = %q, MimeType = %q", content.URI, content.MimeType)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal([]byte(content.Text), &got); err != nil {
+				t.Fatalf("content is not JSON: %v", err)
+			}
+			for key, want := range tt.wantJSON {
+				if got[key] != want {
+					t.Errorf("%s = %v, want %v", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestResourceHandlers_List(t *testing.T) {
+	t.Parallel()
+
+	client := &UniversalMockClient{
+		ListAutomationsFn: func(_ context.Context) ([]homeassistant.Automation, error) {
+			return []homeassistant.Automation{{EntityID: "automation.morning", FriendlyName: "Morning"}}, nil
+		},
+		ListScriptsFn: func(_ context.Context) ([]homeassistant.Entity, error) {
+			return []homeassistant.Entity{{EntityID: "script.bedtime"}}, nil
+		},
+		GetAreaRegistryFn: func(_ context.Context) ([]homeassistant.AreaRegistryEntry, error) {
+			return []homeassistant.AreaRegistryEntry{{AreaID: "living room", Name: "Living Room"}}, nil
+		},
+		ListDashboardsFn: func(_ context.Context) ([]homeassistant.Dashboard, error) {
+			return []homeassistant.Dashboard{{URLPath: "dashboard-energy", Title: "Energy"}}, nil
+		},
+	}
+
+	h := NewResourceHandlers()
+	listers := []mcp.ResourceLister{h.listAutomations, h.listScripts, h.listAreas, h.listDashboards}
+
+	var got []string
+	for _, lister := range listers {
+		resources, err := lister(context.Background(), client)
+		if err != nil {
+			t.Fatalf("lister error = %v", err)
+		}
+		for _, r := range resources {
+			got = append(got, r.URI+" "+r.Name)
+		}
+	}
+
+	want := []string{
+		"hass://automation/morning Morning",
+		"hass://script/bedtime script.bedtime",
+		"hass://area/living%20room Living Room",
+		"hass://lovelace/default Overview",
+		"hass://lovelace/dashboard-energy Energy",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("listed resources mismatch (-want +got):\n%s", diff)
+	}
+
+	// Escaped IDs must round-trip through their template
+	vars, ok := mcp.MatchURITemplate(areaResourceTemplate, "hass://area/living%20room")
+	if !ok || vars["area_id"] != "living room" {
+		t.Errorf("MatchURITemplate() = %v, %v, want area_id %q", vars, ok, "living room")
+	}
+}
@@ -45,6 +45,7 @@ func (h *SceneHandlers) listScenesTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "list_scenes",
 		Description: "List all scenes in Home Assistant. Use filters to narrow down results.",
+		Annotations: readOnlyAnnotations("List Scenes"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Filter options for scenes list",
@@ -66,6 +67,7 @@ func (h *SceneHandlers) getSceneTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_scene",
 		Description: "Get details of a specific scene",
+		Annotations: readOnlyAnnotations("Get Scene"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -83,6 +85,7 @@ func (h *SceneHandlers) createSceneTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_scene",
 		Description: "Create a new scene in Home Assistant",
+		Annotations: createAnnotations("Create Scene"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -112,6 +115,7 @@ func (h *SceneHandlers) updateSceneTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "update_scene",
 		Description: "Update an existing scene in Home Assistant",
+		Annotations: destructiveAnnotations("Update Scene"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -141,6 +145,7 @@ func (h *SceneHandlers) deleteSceneTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_scene",
 		Description: "Delete a scene from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Scene"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -158,6 +163,7 @@ func (h *SceneHandlers) activateSceneTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "activate_scene",
 		Description: "Activate a scene in Home Assistant",
+		Annotations: actionAnnotations("Activate Scene", true),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
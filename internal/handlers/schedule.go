@@ -48,6 +48,7 @@ func (h *ScheduleHandlers) getScheduleDetailsTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_schedule_details",
 		Description: "Get detailed information about a schedule helper including all time blocks for each day of the week. Shows when the schedule is active.",
+		Annotations: readOnlyAnnotations("Get Schedule Details"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Schedule entity ID to get details for",
@@ -55,6 +56,7 @@ func (h *ScheduleHandlers) getScheduleDetailsTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the schedule (e.g., schedule.work_hours)",
+					Pattern:     `^schedule\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -88,6 +90,7 @@ func (h *ScheduleHandlers) createScheduleTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_schedule",
 		Description: "Create a new schedule helper in Home Assistant. A schedule defines time blocks for each day of the week. Useful for automation conditions based on time schedules.",
+		Annotations: createAnnotations("Create Schedule"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Schedule configuration",
@@ -121,6 +124,7 @@ func (h *ScheduleHandlers) deleteScheduleTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_schedule",
 		Description: "Delete a schedule helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Schedule"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Schedule entity ID to delete",
@@ -128,6 +132,7 @@ func (h *ScheduleHandlers) deleteScheduleTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the schedule (e.g., schedule.work_hours)",
+					Pattern:     `^schedule\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -139,6 +144,7 @@ func (h *ScheduleHandlers) reloadScheduleTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "reload_schedule",
 		Description: "Reload all schedule helpers from configuration. Use this after manually editing schedule configuration files.",
+		Annotations: actionAnnotations("Reload Schedules", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "No parameters required",
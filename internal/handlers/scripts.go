@@ -46,6 +46,7 @@ func (h *ScriptHandlers) listScriptsTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "list_scripts",
 		Description: "List all scripts in Home Assistant",
+		Annotations: readOnlyAnnotations("List Scripts"),
 		InputSchema: mcp.JSONSchema{
 			Type:       "object",
 			Properties: map[string]mcp.JSONSchema{},
@@ -57,6 +58,7 @@ func (h *ScriptHandlers) getScriptTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_script",
 		Description: "Get details of a specific script",
+		Annotations: readOnlyAnnotations("Get Script"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -74,6 +76,7 @@ func (h *ScriptHandlers) createScriptTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_script",
 		Description: "Create a new script in Home Assistant",
+		Annotations: createAnnotations("Create Script"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -121,6 +124,7 @@ func (h *ScriptHandlers) updateScriptTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "update_script",
 		Description: "Update an existing script in Home Assistant",
+		Annotations: destructiveAnnotations("Update Script"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -167,6 +171,7 @@ func (h *ScriptHandlers) deleteScriptTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_script",
 		Description: "Delete a script from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Script"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -184,6 +189,7 @@ func (h *ScriptHandlers) executeScriptTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "execute_script",
 		Description: "Execute a script in Home Assistant",
+		Annotations: serviceAnnotations("Execute Script"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -205,6 +211,7 @@ func (h *ScriptHandlers) callServiceTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "call_service",
 		Description: "Call any Home Assistant service",
+		Annotations: serviceAnnotations("Call Service"),
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
@@ -28,16 +28,44 @@ func (h *StatisticsHandlers) getStatisticsTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_statistics",
 		Description: "Get historical statistics for entities (long-term data like energy consumption, temperature averages)",
+		Annotations: readOnlyAnnotations("Get Statistics"),
+		OutputSchema: &mcp.JSONSchema{
+			Type: "object",
+			Properties: map[string]mcp.JSONSchema{
+				"statistics": {
+					Type:        "array",
+					Description: "Statistic rows for all requested IDs, one per period",
+					Items: &mcp.JSONSchema{
+						Type: "object",
+						Properties: map[string]mcp.JSONSchema{
+							"statistic_id": {Type: "string"},
+							"start":        {Type: "number", Description: "Period start as Unix timestamp"},
+							"end":          {Type: "number", Description: "Period end as Unix timestamp"},
+							"mean":         {Type: "number"},
+							"min":          {Type: "number"},
+							"max":          {Type: "number"},
+							"sum":          {Type: "number"},
+							"state":        {Type: "number"},
+							"change":       {Type: "number"},
+						},
+						Required: []string{"statistic_id", "start"},
+					},
+				},
+			},
+			Required: []string{"statistics"},
+		},
 		InputSchema: mcp.JSONSchema{
 			Type: "object",
 			Properties: map[string]mcp.JSONSchema{
 				"statistic_ids": {
 					Type:        "array",
 					Description: "List of statistic IDs to retrieve (e.g., 'sensor.energy_consumption')",
+					Items:       &mcp.JSONSchema{Type: "string"},
 				},
 				"period": {
 					Type:        "string",
 					Description: "Statistics period granularity: 5minute, hour, day, week, or month (default: hour)",
+					Enum:        []string{"5minute", "hour", "day", "week", "month"},
 				},
 			},
 			Required: []string{"statistic_ids"},
@@ -45,6 +73,11 @@ func (h *StatisticsHandlers) getStatisticsTool() mcp.Tool {
 	}
 }
 
+// statisticsOutput is the structured content of get_statistics.
+type statisticsOutput struct {
+	Statistics []homeassistant.StatisticsResult `json:"statistics"`
+}
+
 // handleGetStatistics retrieves historical statistics for specified entities.
 func (h *StatisticsHandlers) handleGetStatistics(
 	ctx context.Context,
@@ -97,10 +130,15 @@ func (h *StatisticsHandlers) handleGetStatistics(
 		return nil, fmt.Errorf("marshaling statistics result: %w", err)
 	}
 
+	if statistics == nil {
+		statistics = []homeassistant.StatisticsResult{}
+	}
+
 	return &mcp.ToolsCallResult{
 		Content: []mcp.ContentBlock{{
 			Type: "text",
 			Text: string(result),
 		}},
+		StructuredContent: statisticsOutput{Statistics: statistics},
 	}, nil
 }
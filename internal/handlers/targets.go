@@ -123,6 +123,7 @@ func (h *TargetHandlers) getTriggersForTargetTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_triggers_for_target",
 		Description: "Get all applicable automation triggers for the specified target. Returns trigger types that can be used in automations for the given entities, devices, areas, or labels.",
+		Annotations: readOnlyAnnotations("Get Triggers for Target"),
 		InputSchema: h.targetInputSchema(),
 	}
 }
@@ -175,6 +176,7 @@ func (h *TargetHandlers) getConditionsForTargetTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_conditions_for_target",
 		Description: "Get all applicable automation conditions for the specified target. Returns condition types that can be used in automations for the given entities, devices, areas, or labels.",
+		Annotations: readOnlyAnnotations("Get Conditions for Target"),
 		InputSchema: h.targetInputSchema(),
 	}
 }
@@ -227,6 +229,7 @@ func (h *TargetHandlers) getServicesForTargetTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "get_services_for_target",
 		Description: "Get all applicable services for the specified target. Returns services that can be called for the given entities, devices, areas, or labels.",
+		Annotations: readOnlyAnnotations("Get Services for Target"),
 		InputSchema: h.targetInputSchema(),
 	}
 }
@@ -279,6 +282,7 @@ func (h *TargetHandlers) extractFromTargetTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "extract_from_target",
 		Description: "Extract entities, devices, and areas from the specified target. Resolves all referenced entities, devices, and areas while also reporting any missing devices, areas, floors, or labels.",
+		Annotations: readOnlyAnnotations("Extract Entities from Target"),
 		InputSchema: h.targetInputSchema(),
 	}
 }
@@ -37,6 +37,7 @@ func (h *TemplateHelperHandlers) createTemplateSensorTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_template_sensor",
 		Description: "Create a new template sensor helper in Home Assistant. A template sensor calculates its state from a Jinja2 template.",
+		Annotations: createAnnotations("Create Template Sensor"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Template sensor configuration",
@@ -79,6 +80,7 @@ func (h *TemplateHelperHandlers) createTemplateBinarySensorTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_template_binary_sensor",
 		Description: "Create a new template binary sensor helper in Home Assistant. A template binary sensor determines its on/off state from a Jinja2 template.",
+		Annotations: createAnnotations("Create Template Binary Sensor"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Template binary sensor configuration",
@@ -121,6 +123,7 @@ func (h *TemplateHelperHandlers) deleteTemplateHelperTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_template_helper",
 		Description: "Delete a template helper (sensor or binary_sensor) from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Template Helper"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Template entity ID to delete",
@@ -128,6 +131,7 @@ func (h *TemplateHelperHandlers) deleteTemplateHelperTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the template helper (e.g., sensor.my_template or binary_sensor.my_template)",
+					Pattern:     `^(sensor|binary_sensor)\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -67,7 +67,9 @@ type UniversalMockClient struct {
 	BrowseMediaFn     func(ctx context.Context, mediaContentID string) (*homeassistant.MediaBrowseResult, error)
 
 	// Configuration operations
-	GetLovelaceConfigFn func(ctx context.Context) (map[string]any, error)
+	GetLovelaceConfigFn  func(ctx context.Context) (map[string]any, error)
+	GetDashboardConfigFn func(ctx context.Context, urlPath string) (map[string]any, error)
+	ListDashboardsFn     func(ctx context.Context) ([]homeassistant.Dashboard, error)
 
 	// Statistics operations
 	GetStatisticsFn func(ctx context.Context, statIDs []string, period string) ([]homeassistant.StatisticsResult, error)
@@ -326,6 +328,20 @@ func (m *UniversalMockClient) GetLovelaceConfig(ctx context.Context) (map[string
 	return map[string]any{}, nil
 }
 
+func (m *UniversalMockClient) GetDashboardConfig(ctx context.Context, urlPath string) (map[string]any, error) {
+	if m.GetDashboardConfigFn != nil {
+		return m.GetDashboardConfigFn(ctx, urlPath)
+	}
+	return map[string]any{}, nil
+}
+
+func (m *UniversalMockClient) ListDashboards(ctx context.Context) ([]homeassistant.Dashboard, error) {
+	if m.ListDashboardsFn != nil {
+		return m.ListDashboardsFn(ctx)
+	}
+	return []homeassistant.Dashboard{}, nil
+}
+
 // Statistics operations implementation
 
 func (m *UniversalMockClient) GetStatistics(ctx context.Context, statIDs []string, period string) ([]homeassistant.StatisticsResult, error) {
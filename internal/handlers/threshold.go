@@ -29,6 +29,7 @@ func (h *ThresholdHandlers) createThresholdTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_threshold",
 		Description: "Create a new threshold helper in Home Assistant. A threshold binary sensor turns on when a source sensor is above the upper threshold or below the lower threshold.",
+		Annotations: createAnnotations("Create Threshold Sensor"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Threshold configuration",
@@ -75,6 +76,7 @@ func (h *ThresholdHandlers) deleteThresholdTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_threshold",
 		Description: "Delete a threshold helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Threshold Sensor"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Threshold entity ID to delete",
@@ -82,6 +84,7 @@ func (h *ThresholdHandlers) deleteThresholdTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the threshold (e.g., binary_sensor.my_threshold)",
+					Pattern:     `^binary_sensor\.`,
 				},
 			},
 			Required: []string{"entity_id"},
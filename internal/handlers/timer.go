@@ -34,6 +34,7 @@ func (h *TimerHandlers) createTimerTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "create_timer",
 		Description: "Create a new timer helper in Home Assistant. A timer counts down from a duration and can trigger automations when finished.",
+		Annotations: createAnnotations("Create Timer"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Timer configuration",
@@ -68,6 +69,7 @@ func (h *TimerHandlers) deleteTimerTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_timer",
 		Description: "Delete a timer helper from Home Assistant",
+		Annotations: destructiveAnnotations("Delete Timer"),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Timer entity ID to delete",
@@ -75,6 +77,7 @@ func (h *TimerHandlers) deleteTimerTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the timer (e.g., timer.my_timer)",
+					Pattern:     `^timer\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -86,6 +89,7 @@ func (h *TimerHandlers) startTimerTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "start_timer",
 		Description: "Start a timer. If the timer is paused, it will resume. Optionally specify a duration to override the default.",
+		Annotations: actionAnnotations("Start Timer", false),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Timer entity ID and optional duration",
@@ -93,6 +97,7 @@ func (h *TimerHandlers) startTimerTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the timer (e.g., timer.my_timer)",
+					Pattern:     `^timer\.`,
 				},
 				"duration": {
 					Type:        "string",
@@ -108,6 +113,7 @@ func (h *TimerHandlers) pauseTimerTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "pause_timer",
 		Description: "Pause a running timer. The remaining time is preserved and can be resumed with start.",
+		Annotations: actionAnnotations("Pause Timer", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Timer entity ID to pause",
@@ -115,6 +121,7 @@ func (h *TimerHandlers) pauseTimerTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the timer (e.g., timer.my_timer)",
+					Pattern:     `^timer\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -126,6 +133,7 @@ func (h *TimerHandlers) cancelTimerTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "cancel_timer",
 		Description: "Cancel a running or paused timer. The timer is reset and no finish event is triggered.",
+		Annotations: actionAnnotations("Cancel Timer", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Timer entity ID to cancel",
@@ -133,6 +141,7 @@ func (h *TimerHandlers) cancelTimerTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the timer (e.g., timer.my_timer)",
+					Pattern:     `^timer\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -144,6 +153,7 @@ func (h *TimerHandlers) finishTimerTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "finish_timer",
 		Description: "Finish a running timer immediately. This triggers the timer.finished event as if the timer had naturally expired.",
+		Annotations: actionAnnotations("Finish Timer", true),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Timer entity ID to finish",
@@ -151,6 +161,7 @@ func (h *TimerHandlers) finishTimerTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the timer (e.g., timer.my_timer)",
+					Pattern:     `^timer\.`,
 				},
 			},
 			Required: []string{"entity_id"},
@@ -162,6 +173,7 @@ func (h *TimerHandlers) changeTimerTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "change_timer",
 		Description: "Change the duration of a running timer by adding or subtracting time.",
+		Annotations: actionAnnotations("Change Timer", false),
 		InputSchema: mcp.JSONSchema{
 			Type:        "object",
 			Description: "Timer entity ID and duration change",
@@ -169,6 +181,7 @@ func (h *TimerHandlers) changeTimerTool() mcp.Tool {
 				"entity_id": {
 					Type:        "string",
 					Description: "The full entity ID of the timer (e.g., timer.my_timer)",
+					Pattern:     `^timer\.`,
 				},
 				"duration": {
 					Type:        "string",
@@ -0,0 +1,132 @@
+// Package handlers provides MCP tool handlers for Home Assistant operations.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/mcp"
+)
+
+// Toolset names used by RegisterAllTools.
+const (
+	ToolsetCore          = "core"
+	ToolsetAutomations   = "automations"
+	ToolsetScriptsScenes = "scripts_scenes"
+	ToolsetInputHelpers  = "input_helpers"
+	ToolsetHelpersTier1  = "helpers_tier1"
+	ToolsetHelpersTier2  = "helpers_tier2"
+	ToolsetHelpersTier3  = "helpers_tier3"
+	ToolsetRegistries    = "registries"
+	ToolsetMedia         = "media"
+	ToolsetTargets       = "targets"
+	ToolsetAnalysis      = "analysis"
+)
+
+// ToolsetHandlers provides the meta tools that list and enable toolsets.
+type ToolsetHandlers struct {
+	registry *mcp.Registry
+}
+
+// NewToolsetHandlers creates a new ToolsetHandlers instance for the toolsets of registry.
+func NewToolsetHandlers(registry *mcp.Registry) *ToolsetHandlers {
+	return &ToolsetHandlers{registry: registry}
+}
+
+// RegisterTools registers the toolset meta tools with the registry. They
+// belong to no toolset, so they are always enabled.
+func (h *ToolsetHandlers) RegisterTools(registry *mcp.Registry) {
+	registry.RegisterTool(h.listToolsetsTool(), h.HandleListToolsets)
+	registry.RegisterTool(h.enableToolsetTool(), h.HandleEnableToolset)
+}
+
+func (h *ToolsetHandlers) listToolsetsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_toolsets",
+		Description: "List the available toolsets, their tools, and whether they are enabled in this session",
+		Annotations: readOnlyAnnotations("List Toolsets"),
+		InputSchema: mcp.JSONSchema{
+			Type:       "object",
+			Properties: map[string]mcp.JSONSchema{},
+		},
+	}
+}
+
+func (h *ToolsetHandlers) enableToolsetTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "enable_toolset",
+		Description: "Enable a toolset for the rest of this session, making its tools available. Use list_toolsets to see what each toolset contains.",
+		// Changes only the tools offered to this session, not Home Assistant
+		Annotations: readOnlyAnnotations("Enable Toolset"),
+		InputSchema: mcp.JSONSchema{
+			Type: "object",
+			Properties: map[string]mcp.JSONSchema{
+				"toolset": {
+					Type:        "string",
+					Description: "Name of the toolset to enable",
+					Enum:        h.registry.ToolsetNames(),
+				},
+			},
+			Required: []string{"toolset"},
+		},
+	}
+}
+
+// HandleListToolsets handles the list_toolsets tool call.
+func (h *ToolsetHandlers) HandleListToolsets(ctx context.Context, _ homeassistant.Client, _ map[string]any) (*mcp.ToolsCallResult, error) {
+	output, err := json.MarshalIndent(h.registry.Toolsets(ctx), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling toolsets: %w", err)
+	}
+
+	return &mcp.ToolsCallResult{
+		Content: []mcp.ContentBlock{mcp.NewTextContent(string(output))},
+	}, nil
+}
+
+// HandleEnableToolset handles the enable_toolset tool call.
+func (h *ToolsetHandlers) HandleEnableToolset(ctx context.Context, _ homeassistant.Client, args map[string]any) (*mcp.ToolsCallResult, error) {
+	name, ok := args["toolset"].(string)
+	if !ok || name == "" {
+		return &mcp.ToolsCallResult{
+			Content: []mcp.ContentBlock{mcp.NewTextContent("toolset is required")},
+			IsError: true,
+		}, nil
+	}
+
+	changed, err := h.registry.EnableToolset(ctx, name)
+	if err != nil {
+		return &mcp.ToolsCallResult{
+			Content: []mcp.ContentBlock{mcp.NewTextContent(fmt.Sprintf("Error enabling toolset: %v", err))},
+			IsError: true,
+		}, nil
+	}
+	if !changed {
+		return &mcp.ToolsCallResult{
+			Content: []mcp.ContentBlock{mcp.NewTextContent(fmt.Sprintf("Toolset %s is already enabled", name))},
+		}, nil
+	}
+
+	var tools []string
+	for _, ts := range h.registry.Toolsets(ctx) {
+		if ts.Name == name {
+			tools = ts.Tools
+		}
+	}
+
+	return &mcp.ToolsCallResult{
+		Content: []mcp.ContentBlock{mcp.NewTextContent(
+			fmt.Sprintf("Enabled toolset %s with %d tools: %s", name, len(tools), strings.Join(tools, ", ")),
+		)},
+	}, nil
+}
+
+// RegisterToolsetTools registers the toolset meta tools for the toolsets
+// already registered with the registry.
+func RegisterToolsetTools(registry *mcp.Registry) {
+	h := NewToolsetHandlers(registry)
+	h.RegisterTools(registry)
+}
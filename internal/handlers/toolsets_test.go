@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/mcp"
+)
+
+func TestRegisterAllTools_Toolsets(t *testing.T) {
+	t.Parallel()
+
+	registry := mcp.NewRegistry()
+	RegisterAllTools(registry)
+
+	want := []string{
+		ToolsetCore, ToolsetAutomations, ToolsetScriptsScenes, ToolsetInputHelpers,
+		ToolsetHelpersTier1, ToolsetHelpersTier2, ToolsetHelpersTier3,
+		ToolsetRegistries, ToolsetMedia, ToolsetTargets, ToolsetAnalysis,
+	}
+	if diff := cmp.Diff(want, registry.ToolsetNames()); diff != "" {
+		t.Errorf("ToolsetNames() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Every tool except the meta tools belongs to exactly one toolset
+	member := make(map[string]string)
+	for _, ts := range registry.Toolsets(context.Background()) {
+		if len(ts.Tools) == 0 {
+			t.Errorf("toolset %s has no tools", ts.Name)
+		}
+		for _, name := range ts.Tools {
+			if other, ok := member[name]; ok {
+				t.Errorf("tool %s is in toolsets %s and %s", name, other, ts.Name)
+			}
+			member[name] = ts.Name
+		}
+	}
+	for _, tool := range registry.ListTools() {
+		_, inToolset := member[tool.Name]
+		isMeta := tool.Name == "list_toolsets" || tool.Name == "enable_toolset"
+		if inToolset == isMeta {
+			t.Errorf("tool %s: in toolset = %v, meta tool = %v", tool.Name, inToolset, isMeta)
+		}
+	}
+
+	tool, ok := registry.GetTool("enable_toolset")
+	if !ok {
+		t.Fatal("enable_toolset is not registered")
+	}
+	if diff := cmp.Diff(want, tool.InputSchema.Properties["toolset"].Enum); diff != "" {
+		t.Errorf("enable_toolset enum mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToolsetHandlers(t *testing.T) {
+	t.Parallel()
+
+	registry := mcp.NewRegistry()
+	RegisterAllTools(registry)
+	h := NewToolsetHandlers(registry)
+	ctx := context.Background()
+
+	result, err := h.HandleListToolsets(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("HandleListToolsets() error = %v", err)
+	}
+	var infos []mcp.ToolsetInfo
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &infos); err != nil {
+		t.Fatalf("unmarshaling list_toolsets result: %v", err)
+	}
+	if len(infos) != len(registry.ToolsetNames()) {
+		t.Errorf("list_toolsets returned %d toolsets, want %d", len(infos), len(registry.ToolsetNames()))
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+	}{
+		{name: "missing toolset", args: map[string]any{}},
+		{name: "no session", args: map[string]any{"toolset": ToolsetMedia}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := h.HandleEnableToolset(ctx, nil, tt.args)
+			if err != nil {
+				t.Fatalf("HandleEnableToolset() error = %v", err)
+			}
+			if !result.IsError {
+				t.Errorf("HandleEnableToolset() IsError = false, want true")
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/metrics"
 )
 
 // Service name constants used across multiple methods.
@@ -68,6 +70,8 @@ type Client interface {
 
 	// Configuration operations
 	GetLovelaceConfig(ctx context.Context) (map[string]any, error)
+	GetDashboardConfig(ctx context.Context, urlPath string) (map[string]any, error)
+	ListDashboards(ctx context.Context) ([]Dashboard, error)
 
 	// Statistics operations
 	GetStatistics(ctx context.Context, statIDs []string, period string) ([]StatisticsResult, error)
@@ -82,6 +86,28 @@ type Client interface {
 	GetScheduleConfig(ctx context.Context, scheduleID string) (map[string]any, error)
 }
 
+// StateSubscriber is implemented by clients that can stream entity state changes.
+type StateSubscriber interface {
+	// SubscribeStateChanges calls handler for every state_changed event until
+	// the returned unsubscribe function is called. The handler must not block.
+	SubscribeStateChanges(ctx context.Context, handler func(StateChangedEvent)) (unsubscribe func(context.Context) error, err error)
+}
+
+// MetricsReporter is implemented by clients that can report metrics of their
+// connection to Home Assistant.
+type MetricsReporter interface {
+	// RegisterMetrics registers the client's metrics with reg.
+	RegisterMetrics(reg *metrics.Registry)
+}
+
+// StateCacher is implemented by clients that can serve entity states from
+// an in-memory cache.
+type StateCacher interface {
+	// EnableStateCache loads all entity states and from then on serves them
+	// from memory, kept current by state change events.
+	EnableStateCache(ctx context.Context) error
+}
+
 // APIError represents an error response from the Home Assistant API.
 type APIError struct {
 	StatusCode int
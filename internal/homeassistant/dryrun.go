@@ -0,0 +1,114 @@
+// Package homeassistant provides dry runs of mutating client operations.
+package homeassistant
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// Transports reported in DryRunRequest.
+const (
+	DryRunWebSocket = "websocket"
+	DryRunREST      = "rest"
+)
+
+// DryRunRequest is a request that a mutating operation would have sent to
+// Home Assistant.
+type DryRunRequest struct {
+	// Transport is DryRunWebSocket or DryRunREST.
+	Transport string `json:"transport"`
+	// Message is the WebSocket command. Its id is assigned when it is sent.
+	Message map[string]any `json:"message,omitempty"`
+	// Method and URL describe the REST request, which has no body.
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+	// Affected is the target of a service call resolved by ExtractFromTarget.
+	Affected *ExtractFromTargetResult `json:"affected,omitempty"`
+}
+
+// DryRun collects the requests of mutating operations run with its context.
+// Read-only requests are still sent, so that operations which look up
+// existing configuration record the request they would really send.
+type DryRun struct {
+	mu       sync.Mutex
+	requests []DryRunRequest
+}
+
+// dryRunContextKey is the context key for the dry run of an operation.
+type dryRunContextKey struct{}
+
+// WithDryRun returns a context in which client operations that would change
+// Home Assistant record their request in the returned DryRun instead of
+// sending it. They report success without a result.
+func WithDryRun(ctx context.Context) (context.Context, *DryRun) {
+	d := &DryRun{}
+	return context.WithValue(ctx, dryRunContextKey{}, d), d
+}
+
+// dryRunFromContext returns the dry run of ctx, or nil if requests are sent.
+func dryRunFromContext(ctx context.Context) *DryRun {
+	d, _ := ctx.Value(dryRunContextKey{}).(*DryRun)
+	return d
+}
+
+// Requests returns the recorded requests in the order they would have been sent.
+func (d *DryRun) Requests() []DryRunRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DryRunRequest(nil), d.requests...)
+}
+
+// record adds a request to the dry run.
+func (d *DryRun) record(req DryRunRequest) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, req)
+}
+
+// wsMessage returns the WebSocket message of a command without its id.
+func wsMessage(msgType string, payload map[string]any) map[string]any {
+	m := maps.Clone(payload)
+	if m == nil {
+		m = make(map[string]any, 1)
+	}
+	m["type"] = msgType
+	return m
+}
+
+// serviceTarget extracts the target of a service call from its service data.
+// It reports false if the data names no entity, device, area, or label.
+func serviceTarget(data map[string]any) (Target, bool) {
+	target := Target{
+		EntityID: stringList(data["entity_id"]),
+		DeviceID: stringList(data["device_id"]),
+		AreaID:   stringList(data["area_id"]),
+		LabelID:  stringList(data["label_id"]),
+	}
+	ok := len(target.EntityID) > 0 || len(target.DeviceID) > 0 || len(target.AreaID) > 0 || len(target.LabelID) > 0
+	return target, ok
+}
+
+// stringList converts a service data value that is a string or a list of
+// strings to a slice.
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
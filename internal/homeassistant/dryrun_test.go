@@ -0,0 +1,145 @@
+package homeassistant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWSClientImpl_DryRun(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var sent []string
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		cmdType, _ := cmd["type"].(string)
+		mu.Lock()
+		sent = append(sent, cmdType)
+		mu.Unlock()
+		if cmdType == "extract_from_target" {
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true, "result": map[string]any{
+				"referenced_entities": []string{"light.kitchen", "light.hall"},
+				"referenced_areas":    []string{"downstairs"},
+			}})
+		}
+	})
+	impl := NewWSClientImpl(connectFakeClient(t, fs))
+	ctx, dryRun := WithDryRun(context.Background())
+
+	if err := impl.CreateAutomation(ctx, AutomationConfig{ID: "night", Alias: "Night", Mode: "single"}); err != nil {
+		t.Fatalf("CreateAutomation() error = %v", err)
+	}
+	entities, err := impl.CallService(ctx, "light", "turn_off", map[string]any{"area_id": "downstairs"})
+	if err != nil {
+		t.Fatalf("CallService() error = %v", err)
+	}
+	if len(entities) != 0 {
+		t.Errorf("CallService() returned %d entities, want 0", len(entities))
+	}
+	if err := impl.DeleteHelper(ctx, "input_boolean.guest_mode"); err != nil {
+		t.Fatalf("DeleteHelper() error = %v", err)
+	}
+
+	want := []DryRunRequest{
+		{
+			Transport: DryRunWebSocket,
+			Message: map[string]any{
+				"type": "config/automation/create", "automation_id": "night", "alias": "Night", "mode": "single",
+			},
+		},
+		{
+			Transport: DryRunWebSocket,
+			Message: map[string]any{
+				"type": "call_service", "domain": "light", "service": "turn_off",
+				"service_data": map[string]any{"area_id": "downstairs"},
+			},
+			Affected: &ExtractFromTargetResult{
+				ReferencedEntities: []string{"light.kitchen", "light.hall"},
+				ReferencedAreas:    []string{"downstairs"},
+			},
+		},
+		{
+			Transport: DryRunWebSocket,
+			Message:   map[string]any{"type": "input_boolean/delete", "input_boolean_id": "guest_mode"},
+		},
+	}
+	if diff := cmp.Diff(want, dryRun.Requests()); diff != "" {
+		t.Errorf("Requests() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Only the read-only target lookup reached Home Assistant
+	mu.Lock()
+	defer mu.Unlock()
+	if diff := cmp.Diff([]string{"extract_from_target"}, sent); diff != "" {
+		t.Errorf("sent commands mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRESTClient_DryRun(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	client := NewRESTClient(server.URL, "test-token")
+	ctx, dryRun := WithDryRun(context.Background())
+
+	if err := client.DeleteScene(ctx, "movie_night"); err != nil {
+		t.Fatalf("DeleteScene() error = %v", err)
+	}
+
+	want := []DryRunRequest{{
+		Transport: DryRunREST,
+		Method:    http.MethodDelete,
+		URL:       server.URL + "/api/config/scene/config/movie_night",
+	}}
+	if diff := cmp.Diff(want, dryRun.Requests()); diff != "" {
+		t.Errorf("Requests() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServiceTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		data   map[string]any
+		want   Target
+		wantOK bool
+	}{
+		{name: "no data", data: nil},
+		{name: "no target", data: map[string]any{"brightness": 128}},
+		{
+			name:   "entity string",
+			data:   map[string]any{"entity_id": "light.kitchen"},
+			want:   Target{EntityID: []string{"light.kitchen"}},
+			wantOK: true,
+		},
+		{
+			name:   "lists",
+			data:   map[string]any{"entity_id": []any{"light.a", "light.b"}, "device_id": []string{"dev1"}, "label_id": "lbl"},
+			want:   Target{EntityID: []string{"light.a", "light.b"}, DeviceID: []string{"dev1"}, LabelID: []string{"lbl"}},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := serviceTarget(tt.data)
+			if ok != tt.wantOK {
+				t.Errorf("serviceTarget() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("serviceTarget() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
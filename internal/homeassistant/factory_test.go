@@ -263,6 +263,12 @@ func (m *mockNonCloserClient) BrowseMedia(_ context.Context, _ string) (*MediaBr
 func (m *mockNonCloserClient) GetLovelaceConfig(_ context.Context) (map[string]any, error) {
 	return map[string]any{}, nil
 }
+func (m *mockNonCloserClient) GetDashboardConfig(_ context.Context, _ string) (map[string]any, error) {
+	return map[string]any{}, nil
+}
+func (m *mockNonCloserClient) ListDashboards(_ context.Context) ([]Dashboard, error) {
+	return []Dashboard{}, nil
+}
 func (m *mockNonCloserClient) GetStatistics(_ context.Context, _ []string, _ string) ([]StatisticsResult, error) {
 	return []StatisticsResult{}, nil
 }
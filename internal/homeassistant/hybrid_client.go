@@ -4,6 +4,8 @@ package homeassistant
 import (
 	"context"
 	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/metrics"
 )
 
 // HybridClient combines WebSocket and REST API clients for Home Assistant.
@@ -220,6 +222,16 @@ func (c *HybridClient) GetLovelaceConfig(ctx context.Context) (map[string]any, e
 	return c.ws.GetLovelaceConfig(ctx)
 }
 
+// GetDashboardConfig retrieves the configuration of a Lovelace dashboard.
+func (c *HybridClient) GetDashboardConfig(ctx context.Context, urlPath string) (map[string]any, error) {
+	return c.ws.GetDashboardConfig(ctx, urlPath)
+}
+
+// ListDashboards lists the Lovelace dashboards.
+func (c *HybridClient) ListDashboards(ctx context.Context) ([]Dashboard, error) {
+	return c.ws.ListDashboards(ctx)
+}
+
 // =============================================================================
 // Statistics Operations (delegated to WebSocket)
 // =============================================================================
@@ -262,6 +274,39 @@ func (c *HybridClient) GetScheduleConfig(ctx context.Context, scheduleID string)
 	return c.ws.GetScheduleConfig(ctx, scheduleID)
 }
 
+// =============================================================================
+// Event Operations (delegated to WebSocket)
+// =============================================================================
+
+// Ensure HybridClient can stream state changes.
+var _ StateSubscriber = (*HybridClient)(nil)
+
+// SubscribeStateChanges subscribes to state_changed events.
+func (c *HybridClient) SubscribeStateChanges(ctx context.Context, handler func(StateChangedEvent)) (func(context.Context) error, error) {
+	return c.ws.SubscribeStateChanges(ctx, handler)
+}
+
+// Ensure HybridClient can cache states.
+var _ StateCacher = (*HybridClient)(nil)
+
+// EnableStateCache serves GetStates, GetState and ListHelpers from an
+// in-memory cache of the WebSocket connection.
+func (c *HybridClient) EnableStateCache(ctx context.Context) error {
+	return c.ws.ws.EnableStateCache(ctx)
+}
+
+// =============================================================================
+// Metrics (delegated to WebSocket)
+// =============================================================================
+
+// Ensure HybridClient can report metrics.
+var _ MetricsReporter = (*HybridClient)(nil)
+
+// RegisterMetrics registers the metrics of the WebSocket connection with reg.
+func (c *HybridClient) RegisterMetrics(reg *metrics.Registry) {
+	c.ws.ws.RegisterMetrics(reg)
+}
+
 // =============================================================================
 // HybridClientCloser - implements ClientCloser for proper cleanup
 // =============================================================================
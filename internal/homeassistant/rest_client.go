@@ -61,12 +61,26 @@ func NewRESTClientWithConfig(baseURL, token string, config RESTClientConfig) *RE
 	}
 }
 
+// recordREST records a REST request without a body in the dry run of ctx.
+// It reports whether there is a dry run, in which case the request must not be sent.
+func recordREST(ctx context.Context, method, url string) bool {
+	d := dryRunFromContext(ctx)
+	if d == nil {
+		return false
+	}
+	d.record(DryRunRequest{Transport: DryRunREST, Method: method, URL: url})
+	return true
+}
+
 // DeleteAutomation deletes an automation using the REST API.
 // The WebSocket API does not support automation deletion, so we use REST.
 // Endpoint: DELETE /api/config/automation/config/{automation_id}
 func (c *RESTClient) DeleteAutomation(ctx context.Context, automationID string) error {
 	// Build the URL for automation deletion
 	url := fmt.Sprintf("%s/api/config/automation/config/%s", c.baseURL, automationID)
+	if recordREST(ctx, http.MethodDelete, url) {
+		return nil
+	}
 
 	// Create the DELETE request
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, http.NoBody)
@@ -129,6 +143,9 @@ func (c *RESTClient) DeleteAutomation(ctx context.Context, automationID string)
 // Endpoint: DELETE /api/config/script/config/{script_id}
 func (c *RESTClient) DeleteScript(ctx context.Context, scriptID string) error {
 	url := fmt.Sprintf("%s/api/config/script/config/%s", c.baseURL, scriptID)
+	if recordREST(ctx, http.MethodDelete, url) {
+		return nil
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, http.NoBody)
 	if err != nil {
@@ -185,6 +202,9 @@ func (c *RESTClient) DeleteScript(ctx context.Context, scriptID string) error {
 // Endpoint: DELETE /api/config/scene/config/{scene_id}
 func (c *RESTClient) DeleteScene(ctx context.Context, sceneID string) error {
 	url := fmt.Sprintf("%s/api/config/scene/config/%s", c.baseURL, sceneID)
+	if recordREST(ctx, http.MethodDelete, url) {
+		return nil
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, http.NoBody)
 	if err != nil {
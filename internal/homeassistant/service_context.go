@@ -0,0 +1,23 @@
+// Package homeassistant reports the contexts of service calls to their callers.
+package homeassistant
+
+import "context"
+
+// serviceContextKey is the context key for the recorder of service call contexts.
+type serviceContextKey struct{}
+
+// WithServiceContextRecorder returns a context in which CallService passes the
+// id of the context that Home Assistant created for each service call to
+// record, whether or not the call returned changed entities.
+func WithServiceContextRecorder(ctx context.Context, record func(id string)) context.Context {
+	return context.WithValue(ctx, serviceContextKey{}, record)
+}
+
+// recordServiceContext passes id to the recorder of ctx, if any.
+func recordServiceContext(ctx context.Context, id string) {
+	record, _ := ctx.Value(serviceContextKey{}).(func(id string))
+	if record == nil || id == "" {
+		return
+	}
+	record(id)
+}
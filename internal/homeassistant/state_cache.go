@@ -0,0 +1,198 @@
+// Package homeassistant provides an in-memory cache of entity states.
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stateCache holds the states of all entities, loaded with get_states and kept
+// current by state_changed events.
+//
+// Events may be missed while the connection is down, so the cache only serves
+// states loaded on the current connection. After a reconnect it is stale until
+// the renewed subscription delivers EventResynced and the states are loaded
+// again, retrying until a load succeeds; meanwhile reads go to Home Assistant.
+type stateCache struct {
+	ws *WSClient
+
+	loadMu sync.Mutex // serializes loads
+
+	mu         sync.RWMutex
+	states     map[string]Entity
+	connection int64 // connection the states were loaded on; 0 = never loaded
+	loading    bool
+	queued     []StateChangedEvent // events that arrived during a load
+}
+
+// EnableStateCache loads the states of all entities and from then on serves
+// GetStates, GetState and ListHelpers from memory, kept current by
+// state_changed events. It must be called after Connect and at most once.
+func (c *WSClient) EnableStateCache(ctx context.Context) error {
+	cache := &stateCache{ws: c, loading: true}
+
+	// Subscribed first so that no change between the load and the
+	// subscription is missed
+	id, err := c.subscribeEvents(ctx, "state_changed", cache.handleEvent)
+	if err != nil {
+		return fmt.Errorf("enabling state cache: %w", err)
+	}
+	if err := cache.load(ctx); err != nil {
+		_ = c.Unsubscribe(ctx, id)
+		return fmt.Errorf("enabling state cache: %w", err)
+	}
+
+	c.states.Store(cache)
+	return nil
+}
+
+// cachedStates returns the state cache, or nil if it is not enabled.
+func (c *WSClient) cachedStates() *stateCache {
+	return c.states.Load()
+}
+
+// handleEvent applies a state_changed event, or reloads the states when the
+// subscription was renewed after a reconnect. It runs on the read loop, so
+// loading happens in the background.
+func (sc *stateCache) handleEvent(event WSEvent) {
+	if event.EventType == EventResynced {
+		go sc.reload(sc.ws.ctx)
+		return
+	}
+
+	changed, ok := parseStateChangedEvent(event)
+	if !ok {
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.loading {
+		sc.queued = append(sc.queued, changed)
+		return
+	}
+	sc.apply(changed)
+}
+
+// load replaces the cached states with the result of get_states. Events that
+// arrive meanwhile are applied afterwards, in order. If loading fails, the
+// cache stays stale.
+func (sc *stateCache) load(ctx context.Context) error {
+	sc.loadMu.Lock()
+	defer sc.loadMu.Unlock()
+
+	connection := sc.ws.connections.Load()
+	sc.mu.Lock()
+	sc.loading = true
+	sc.mu.Unlock()
+
+	entities, err := fetchStates(ctx, sc.ws)
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	queued := sc.queued
+	sc.loading = false
+	sc.queued = nil
+	if err != nil {
+		return err
+	}
+
+	sc.states = make(map[string]Entity, len(entities))
+	for _, entity := range entities {
+		sc.states[entity.EntityID] = entity
+	}
+	for _, changed := range queued {
+		sc.apply(changed)
+	}
+	sc.connection = connection
+	return nil
+}
+
+// reload loads the states after a resync. Failed loads are retried with the
+// reconnect backoff until one succeeds, ctx is done, or the connection is
+// replaced, whose own resync then reloads the states.
+func (sc *stateCache) reload(ctx context.Context) {
+	connection := sc.ws.connections.Load()
+	for attempt := 1; ; attempt++ {
+		if err := sc.load(ctx); err == nil {
+			return
+		}
+
+		timer := time.NewTimer(CalculateBackoff(attempt, sc.ws.config.ReconnectConfig))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		if sc.ws.connections.Load() != connection {
+			return
+		}
+	}
+}
+
+// apply updates the cache with a state change. The caller must hold mu.
+func (sc *stateCache) apply(changed StateChangedEvent) {
+	if sc.states == nil {
+		return
+	}
+	if changed.NewState == nil {
+		delete(sc.states, changed.EntityID)
+		return
+	}
+	sc.states[changed.EntityID] = *changed.NewState
+}
+
+// fresh reports whether the cached states were loaded on the current
+// connection. The caller must hold mu.
+func (sc *stateCache) fresh() bool {
+	return sc.connection != 0 && sc.connection == sc.ws.connections.Load() && sc.ws.IsConnected()
+}
+
+// all returns all cached states sorted by entity ID, and false if the cache
+// is stale. The slice is the caller's, but the Attributes maps are shared
+// with the cache and must not be modified.
+func (sc *stateCache) all() ([]Entity, bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	if !sc.fresh() {
+		return nil, false
+	}
+
+	entities := make([]Entity, 0, len(sc.states))
+	for _, entity := range sc.states {
+		entities = append(entities, entity)
+	}
+	slices.SortFunc(entities, func(a, b Entity) int { return strings.Compare(a.EntityID, b.EntityID) })
+	return entities, true
+}
+
+// get returns the cached state of an entity and whether it exists, and false
+// as fresh if the cache is stale.
+func (sc *stateCache) get(entityID string) (entity Entity, found, fresh bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	if !sc.fresh() {
+		return Entity{}, false, false
+	}
+	entity, found = sc.states[entityID]
+	return entity, found, true
+}
+
+// parseStateChangedEvent decodes the data of a state_changed event.
+func parseStateChangedEvent(event WSEvent) (StateChangedEvent, bool) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return StateChangedEvent{}, false
+	}
+	var changed StateChangedEvent
+	if err := json.Unmarshal(data, &changed); err != nil || changed.EntityID == "" {
+		return StateChangedEvent{}, false
+	}
+	return changed, true
+}
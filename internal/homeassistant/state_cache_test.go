@@ -0,0 +1,271 @@
+package homeassistant
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// stateServer is a fake Home Assistant that answers get_states with its
+// current states and counts the calls.
+type stateServer struct {
+	*fakeHAServer
+
+	getStates  atomic.Int32
+	failStates atomic.Int32 // number of get_states calls still to fail
+
+	mu         sync.Mutex
+	states     []map[string]any
+	subscribed float64 // ID of the latest state_changed subscription
+	resubs     chan struct{}
+}
+
+func newStateServer(t *testing.T, states ...map[string]any) *stateServer {
+	t.Helper()
+
+	ss := &stateServer{states: states, resubs: make(chan struct{}, 4)}
+	ss.fakeHAServer = newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		switch cmd["type"] {
+		case "get_states":
+			ss.getStates.Add(1)
+			if ss.failStates.Add(-1) >= 0 {
+				s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": false,
+					"error": map[string]any{"code": "unknown_error", "message": "Unknown error"}})
+				return
+			}
+			ss.mu.Lock()
+			states := ss.states
+			ss.mu.Unlock()
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true, "result": states})
+		case "subscribe_events":
+			ss.mu.Lock()
+			ss.subscribed = cmd["id"].(float64)
+			ss.mu.Unlock()
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+			ss.resubs <- struct{}{}
+		default:
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+		}
+	})
+	return ss
+}
+
+// changeState sends a state_changed event and makes the change visible to get_states.
+func (ss *stateServer) changeState(entityID string, newState map[string]any) {
+	ss.mu.Lock()
+	id := ss.subscribed
+	states := ss.states[:0:0]
+	for _, state := range ss.states {
+		if state["entity_id"] != entityID {
+			states = append(states, state)
+		}
+	}
+	if newState != nil {
+		states = append(states, newState)
+	}
+	ss.states = states
+	ss.mu.Unlock()
+
+	ss.reply(map[string]any{"id": id, "type": "event", "event": map[string]any{
+		"event_type": "state_changed",
+		"data":       map[string]any{"entity_id": entityID, "new_state": newState},
+	}})
+}
+
+// waitForState polls GetState until the entity has the given state.
+func waitForState(t *testing.T, impl *wsClientImpl, entityID, want string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		entity, err := impl.GetState(context.Background(), entityID)
+		if err == nil && entity.State == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetState(%s) = %+v, %v; want state %q", entityID, entity, err, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWSClient_StateCache(t *testing.T) {
+	t.Parallel()
+
+	ss := newStateServer(t,
+		map[string]any{"entity_id": "light.kitchen", "state": "off"},
+		map[string]any{"entity_id": "input_boolean.guest", "state": "on"},
+	)
+	client := connectFakeClient(t, ss.fakeHAServer)
+	impl := &wsClientImpl{ws: client}
+
+	if err := client.EnableStateCache(context.Background()); err != nil {
+		t.Fatalf("EnableStateCache() error = %v", err)
+	}
+
+	entities, err := impl.GetStates(context.Background())
+	if err != nil {
+		t.Fatalf("GetStates() error = %v", err)
+	}
+	var ids []string
+	for _, e := range entities {
+		ids = append(ids, e.EntityID)
+	}
+	if diff := cmp.Diff([]string{"input_boolean.guest", "light.kitchen"}, ids); diff != "" {
+		t.Errorf("GetStates() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Callers may reorder the slice they get without affecting the cache
+	entities[0], entities[1] = entities[1], entities[0]
+	if again, _ := impl.GetStates(context.Background()); again[0].EntityID != "input_boolean.guest" {
+		t.Errorf("GetStates() after reordering a result = %+v, want sorted states", again)
+	}
+
+	helpers, err := impl.ListHelpers(context.Background())
+	if err != nil || len(helpers) != 1 || helpers[0].EntityID != "input_boolean.guest" {
+		t.Errorf("ListHelpers() = %+v, %v; want input_boolean.guest", helpers, err)
+	}
+
+	ss.changeState("light.kitchen", map[string]any{"entity_id": "light.kitchen", "state": "on"})
+	waitForState(t, impl, "light.kitchen", "on")
+
+	ss.changeState("input_boolean.guest", nil)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, err := impl.GetState(context.Background(), "input_boolean.guest")
+		if err != nil && strings.Contains(err.Error(), "entity not found") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetState() of a removed entity error = %v, want entity not found", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := ss.getStates.Load(); got != 1 {
+		t.Errorf("get_states sent %d times, want 1 (at EnableStateCache)", got)
+	}
+}
+
+func TestWSClient_StateCacheAfterReconnect(t *testing.T) {
+	t.Parallel()
+
+	ss := newStateServer(t, map[string]any{"entity_id": "light.kitchen", "state": "off"})
+
+	config := DefaultWSClientConfig()
+	config.PingInterval = 0
+	config.ReconnectConfig.InitialDelay = 10 * time.Millisecond
+	client := NewWSClientWithConfig(ss.URL(), "test-token", config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	impl := &wsClientImpl{ws: client}
+
+	if err := client.EnableStateCache(context.Background()); err != nil {
+		t.Fatalf("EnableStateCache() error = %v", err)
+	}
+	<-ss.resubs
+
+	// A change while the connection is down is missed by the event stream
+	ss.dropConnection()
+	ss.mu.Lock()
+	ss.states = []map[string]any{{"entity_id": "light.kitchen", "state": "on"}}
+	ss.mu.Unlock()
+
+	select {
+	case <-ss.resubs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("state_changed subscription not renewed after reconnect")
+	}
+	waitForState(t, impl, "light.kitchen", "on")
+
+	// Reloaded after the resync, then served from memory again
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, _, fresh := client.cachedStates().get("light.kitchen"); fresh {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("state cache still stale after the resync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	calls := ss.getStates.Load()
+	if _, err := impl.GetStates(context.Background()); err != nil {
+		t.Fatalf("GetStates() error = %v", err)
+	}
+	if got := ss.getStates.Load(); got != calls {
+		t.Errorf("GetStates() sent get_states with a fresh cache")
+	}
+}
+
+func TestWSClient_StateCacheRetriesFailedReload(t *testing.T) {
+	t.Parallel()
+
+	ss := newStateServer(t, map[string]any{"entity_id": "light.kitchen", "state": "off"})
+
+	config := DefaultWSClientConfig()
+	config.PingInterval = 0
+	config.ReconnectConfig.InitialDelay = 10 * time.Millisecond
+	client := NewWSClientWithConfig(ss.URL(), "test-token", config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	if err := client.EnableStateCache(context.Background()); err != nil {
+		t.Fatalf("EnableStateCache() error = %v", err)
+	}
+	<-ss.resubs
+
+	// The first reload after the reconnect fails
+	ss.failStates.Store(1)
+	ss.dropConnection()
+	select {
+	case <-ss.resubs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("state_changed subscription not renewed after reconnect")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, _, fresh := client.cachedStates().get("light.kitchen"); fresh {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("state cache still stale after a failed reload")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := ss.getStates.Load(); got != 3 {
+		t.Errorf("get_states sent %d times, want 3 (load, failed reload, retry)", got)
+	}
+}
+
+func TestStateCache_StaleWhileDisconnected(t *testing.T) {
+	t.Parallel()
+
+	ss := newStateServer(t, map[string]any{"entity_id": "light.kitchen", "state": "off"})
+	client := connectFakeClient(t, ss.fakeHAServer)
+	impl := &wsClientImpl{ws: client}
+
+	if err := client.EnableStateCache(context.Background()); err != nil {
+		t.Fatalf("EnableStateCache() error = %v", err)
+	}
+
+	ss.dropConnection()
+	deadline := time.Now().Add(2 * time.Second)
+	for client.IsConnected() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Without a connection the cache may be out of date, so it is not used
+	if _, err := impl.GetState(context.Background(), "light.kitchen"); err == nil {
+		t.Error("GetState() served a possibly stale state while disconnected")
+	}
+}
@@ -87,6 +87,14 @@ type Entity struct {
 	Context     Context        `json:"context"`
 }
 
+// StateChangedEvent is the data of a Home Assistant state_changed event.
+// OldState is nil for new entities and NewState is nil for removed ones.
+type StateChangedEvent struct {
+	EntityID string  `json:"entity_id"`
+	OldState *Entity `json:"old_state"`
+	NewState *Entity `json:"new_state"`
+}
+
 // Context represents the context of a state change.
 type Context struct {
 	ID       string `json:"id"`
@@ -286,6 +294,17 @@ type AreaRegistryEntry struct {
 	Aliases []string `json:"aliases,omitempty"`
 }
 
+// Dashboard represents a Lovelace dashboard other than the default one.
+type Dashboard struct {
+	ID            string `json:"id"`
+	URLPath       string `json:"url_path"`
+	Title         string `json:"title,omitempty"`
+	Icon          string `json:"icon,omitempty"`
+	Mode          string `json:"mode,omitempty"` // "storage" or "yaml"
+	RequireAdmin  bool   `json:"require_admin,omitempty"`
+	ShowInSidebar bool   `json:"show_in_sidebar,omitempty"`
+}
+
 // StreamInfo represents camera stream information from Home Assistant.
 type StreamInfo struct {
 	URL string `json:"url"`
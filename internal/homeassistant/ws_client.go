@@ -18,6 +18,18 @@ import (
 // Large responses like get_states with many entities require this limit.
 const maxWSMessageSize = 16 * 1024 * 1024
 
+// Errors reported by commands.
+var (
+	// ErrNotConnected is returned for a command sent while there is no connection.
+	ErrNotConnected = errors.New("not connected")
+	// ErrConnectionClosed is returned when the connection is lost while a
+	// command waits for its response. The command may or may not have run.
+	ErrConnectionClosed = errors.New("connection closed while waiting for response")
+	// ErrCommandTimeout is returned when Home Assistant does not answer a
+	// command within WSClientConfig.CommandTimeout.
+	ErrCommandTimeout = errors.New("timed out waiting for response")
+)
+
 // WSClientConfig holds configuration options for WSClient.
 type WSClientConfig struct {
 	// ReconnectConfig configures automatic reconnection behavior.
@@ -34,6 +46,19 @@ type WSClientConfig struct {
 	PingTimeout time.Duration
 	// WriteTimeout is the timeout for write operations.
 	WriteTimeout time.Duration
+	// EventBufferSize is the number of events each Subscription buffers
+	// (0 = 256).
+	EventBufferSize int
+	// CommandTimeout bounds the wait for the response to a command, on top
+	// of the caller's context (0 = no limit).
+	CommandTimeout time.Duration
+	// CommandRetries is how often a read-only command is sent again when the
+	// connection is lost before it is answered. Commands that change state
+	// are never sent again.
+	CommandRetries int
+	// RetryBackoff is the wait before the first retry; it doubles with each
+	// further retry.
+	RetryBackoff time.Duration
 }
 
 // DefaultWSClientConfig returns the default WSClient configuration.
@@ -44,6 +69,10 @@ func DefaultWSClientConfig() WSClientConfig {
 		PingInterval:    30 * time.Second,
 		PingTimeout:     10 * time.Second,
 		WriteTimeout:    10 * time.Second,
+		EventBufferSize: defaultEventBufferSize,
+		CommandTimeout:  30 * time.Second,
+		CommandRetries:  2,
+		RetryBackoff:    1 * time.Second,
 	}
 }
 
@@ -55,6 +84,9 @@ type WSClient struct {
 	msgID     atomic.Int64
 	pendingMu sync.RWMutex
 	pending   map[int64]chan *WSResultMessage
+	eventMu   sync.RWMutex
+	events    map[int64]EventHandler
+	subs      map[int64]*subscription // by handle, guarded by eventMu
 	ctx       context.Context
 	cancel    context.CancelFunc
 	connected atomic.Bool
@@ -68,6 +100,11 @@ type WSClient struct {
 	// Health monitoring fields
 	pingCancel context.CancelFunc
 	lastPong   atomic.Value // time.Time
+
+	metrics atomic.Pointer[wsMetrics] // nil until RegisterMetrics
+
+	connections atomic.Int64               // successful connections so far
+	states      atomic.Pointer[stateCache] // nil until EnableStateCache
 }
 
 // NewWSClient creates a new WebSocket client for Home Assistant.
@@ -81,6 +118,8 @@ func NewWSClientWithConfig(baseURL, token string, config WSClientConfig) *WSClie
 		baseURL:      baseURL,
 		token:        token,
 		pending:      make(map[int64]chan *WSResultMessage),
+		events:       make(map[int64]EventHandler),
+		subs:         make(map[int64]*subscription),
 		config:       config,
 		reconnectMgr: NewReconnectManager(config.ReconnectConfig),
 	}
@@ -116,6 +155,7 @@ func (c *WSClient) Connect(ctx context.Context) error {
 	}
 
 	// Mark as connected and start read loop
+	c.connections.Add(1)
 	c.connected.Store(true)
 
 	// Reset reconnection manager on successful connection
@@ -293,6 +333,7 @@ func (c *WSClient) readLoop() {
 	defer func() {
 		c.connected.Store(false)
 		c.closePendingChannels()
+		c.endSubscriptions()
 	}()
 
 	for {
@@ -310,6 +351,10 @@ func (c *WSClient) readLoop() {
 				return
 			}
 
+			// Fail the commands still waiting for a response on the lost
+			// connection; the new one will never answer them
+			c.closePendingChannels()
+
 			// Notify disconnect callback
 			if c.config.OnDisconnect != nil {
 				c.config.OnDisconnect(err)
@@ -338,8 +383,10 @@ func (c *WSClient) readLoop() {
 		switch msgType {
 		case "result":
 			c.handleResultMessage(data)
-		case "event", "pong":
-			// Events and pong responses are handled by their respective subsystems
+		case "event":
+			c.handleEventMessage(data)
+		case "pong":
+			// Pong responses are handled by the health monitor
 		}
 	}
 }
@@ -394,6 +441,9 @@ func (c *WSClient) reconnect() error {
 			c.startHealthMonitor()
 		}
 
+		// Renew subscriptions once the read loop is back to read the results
+		go c.resubscribe(c.ctx)
+
 		// Notify reconnect callback
 		if c.config.OnReconnect != nil {
 			c.config.OnReconnect(attempts)
@@ -434,6 +484,7 @@ func (c *WSClient) connectInternal() error {
 	}
 
 	// Mark as connected
+	c.connections.Add(1)
 	c.connected.Store(true)
 
 	return nil
@@ -464,6 +515,22 @@ func (c *WSClient) handleResultMessage(data []byte) {
 	}
 }
 
+// handleEventMessage routes an event message to the handler of its subscription.
+func (c *WSClient) handleEventMessage(data []byte) {
+	var msg WSEventMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+
+	c.eventMu.RLock()
+	handler, ok := c.events[msg.ID]
+	c.eventMu.RUnlock()
+
+	if ok {
+		handler(msg.Event)
+	}
+}
+
 // closePendingChannels closes all pending response channels on disconnect.
 func (c *WSClient) closePendingChannels() {
 	c.pendingMu.Lock()
@@ -476,13 +543,25 @@ func (c *WSClient) closePendingChannels() {
 }
 
 // SendCommand sends a command to Home Assistant and waits for a response.
+// Canceling ctx abandons the wait for the response; the connection stays open.
+// The wait is limited to CommandTimeout. A read-only command is sent again up
+// to CommandRetries times if the connection is lost before it is answered.
 func (c *WSClient) SendCommand(ctx context.Context, msgType string, payload map[string]any) (*WSResultMessage, error) {
-	if !c.connected.Load() {
-		return nil, errors.New("not connected")
+	if !readOnlyCommands[msgType] {
+		return c.sendCommandWithID(ctx, c.msgID.Add(1), msgType, payload)
 	}
+	return c.sendWithRetry(ctx, msgType, payload)
+}
 
-	// Generate new message ID
-	id := c.msgID.Add(1)
+// sendCommandWithID sends a command with a message ID obtained from msgID and
+// waits for its response.
+func (c *WSClient) sendCommandWithID(ctx context.Context, id int64, msgType string, payload map[string]any) (*WSResultMessage, error) {
+	if !c.connected.Load() {
+		return nil, ErrNotConnected
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Create response channel
 	responseChan := make(chan *WSResultMessage, 1)
@@ -511,25 +590,56 @@ func (c *WSClient) SendCommand(ctx context.Context, msgType string, payload map[
 		return nil, fmt.Errorf("marshaling command: %w", err)
 	}
 
-	if err := c.conn.Write(ctx, websocket.MessageText, data); err != nil {
+	start := time.Now()
+	if err := c.writeMessage(data); err != nil {
 		return nil, fmt.Errorf("sending command: %w", err)
 	}
 
-	// Wait for response with timeout
+	return c.awaitResult(ctx, msgType, start, responseChan)
+}
+
+// awaitResult waits for the response to a command sent at start, at most
+// CommandTimeout.
+func (c *WSClient) awaitResult(
+	ctx context.Context,
+	msgType string,
+	start time.Time,
+	responseChan <-chan *WSResultMessage,
+) (*WSResultMessage, error) {
+	if c.config.CommandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, c.config.CommandTimeout, ErrCommandTimeout)
+		defer cancel()
+	}
+
 	select {
 	case result, ok := <-responseChan:
 		if !ok {
-			return nil, errors.New("connection closed while waiting for response")
+			return nil, ErrConnectionClosed
 		}
+		c.observeCommand(msgType, start)
 		if !result.Success && result.Error != nil {
 			return nil, fmt.Errorf("command failed: %s - %s", result.Error.Code, result.Error.Message)
 		}
 		return result, nil
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, context.Cause(ctx)
 	}
 }
 
+// writeMessage writes a text message to the connection. The write is bound to the
+// connection's lifetime and WriteTimeout rather than to a caller's context, because
+// canceling a write in progress closes the whole connection.
+func (c *WSClient) writeMessage(data []byte) error {
+	ctx := c.ctx
+	if c.config.WriteTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.WriteTimeout)
+		defer cancel()
+	}
+	return c.conn.Write(ctx, websocket.MessageText, data)
+}
+
 // SendSimpleCommand sends a command without additional payload.
 func (c *WSClient) SendSimpleCommand(ctx context.Context, msgType string) (*WSResultMessage, error) {
 	return c.SendCommand(ctx, msgType, nil)
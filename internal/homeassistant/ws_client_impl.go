@@ -34,9 +34,20 @@ var _ Client = (*wsClientImpl)(nil)
 // Core State Operations
 // =============================================================================
 
-// GetStates retrieves all entity states via WebSocket.
+// GetStates retrieves all entity states via WebSocket, or from the state
+// cache if it is enabled and fresh.
 func (c *wsClientImpl) GetStates(ctx context.Context) ([]Entity, error) {
-	result, err := c.ws.SendCommand(ctx, "get_states", nil)
+	if cache := c.ws.cachedStates(); cache != nil {
+		if entities, fresh := cache.all(); fresh {
+			return entities, nil
+		}
+	}
+	return fetchStates(ctx, c.ws)
+}
+
+// fetchStates retrieves all entity states with the get_states command.
+func fetchStates(ctx context.Context, ws *WSClient) ([]Entity, error) {
+	result, err := ws.SendCommand(ctx, "get_states", nil)
 	if err != nil {
 		return nil, fmt.Errorf("get_states command failed: %w", err)
 	}
@@ -51,6 +62,15 @@ func (c *wsClientImpl) GetStates(ctx context.Context) ([]Entity, error) {
 
 // GetState retrieves the state of a specific entity.
 func (c *wsClientImpl) GetState(ctx context.Context, entityID string) (*Entity, error) {
+	if cache := c.ws.cachedStates(); cache != nil {
+		if entity, found, fresh := cache.get(entityID); fresh {
+			if !found {
+				return nil, fmt.Errorf("entity not found: %s", entityID)
+			}
+			return &entity, nil
+		}
+	}
+
 	entities, err := c.GetStates(ctx)
 	if err != nil {
 		return nil, err
@@ -113,6 +133,10 @@ func (c *wsClientImpl) CallService(ctx context.Context, domain, service string,
 		params["service_data"] = data
 	}
 
+	if d := dryRunFromContext(ctx); d != nil {
+		return []Entity{}, c.recordServiceCall(ctx, d, params, data)
+	}
+
 	result, err := c.ws.SendCommand(ctx, "call_service", params)
 	if err != nil {
 		return nil, fmt.Errorf("call_service failed: %w", err)
@@ -120,19 +144,53 @@ func (c *wsClientImpl) CallService(ctx context.Context, domain, service string,
 
 	// call_service returns context and optionally changed entities
 	var response struct {
-		Context  Context  `json:"context"`
-		Response []Entity `json:"response,omitempty"`
+		Context  Context         `json:"context"`
+		Response json.RawMessage `json:"response,omitempty"`
+	}
+	if result.Result == nil {
+		return nil, nil
 	}
-	if result.Result != nil {
-		if err := json.Unmarshal(result.Result, &response); err != nil {
+	if err := json.Unmarshal(result.Result, &response); err != nil {
+		return []Entity{}, nil //nolint:nilerr // the call succeeded even if its result is not understood
+	}
+	recordServiceContext(ctx, response.Context.ID)
+
+	var entities []Entity
+	if len(response.Response) > 0 {
+		if err := json.Unmarshal(response.Response, &entities); err != nil {
 			// Some service calls (e.g., script.turn_on, automation.trigger) return only
-			// a context without entities. Unmarshal fails because the response structure
-			// differs. This is expected behavior, not an error.
+			// a context without entities, or a response of another structure.
+			// This is expected behavior, not an error.
 			return []Entity{}, nil //nolint:nilerr // service calls return context-only response without entities
 		}
 	}
 
-	return response.Response, nil
+	return entities, nil
+}
+
+// recordServiceCall records a service call in a dry run together with the
+// entities, devices, and areas it would affect.
+func (c *wsClientImpl) recordServiceCall(ctx context.Context, d *DryRun, params, data map[string]any) error {
+	req := DryRunRequest{Transport: DryRunWebSocket, Message: wsMessage("call_service", params)}
+	if target, ok := serviceTarget(data); ok {
+		affected, err := c.ExtractFromTarget(ctx, target, nil)
+		if err != nil {
+			return fmt.Errorf("resolving service target: %w", err)
+		}
+		req.Affected = affected
+	}
+	d.record(req)
+	return nil
+}
+
+// sendMutation sends a command that changes Home Assistant configuration.
+// In a dry run, the command is recorded instead and reported as successful.
+func (c *wsClientImpl) sendMutation(ctx context.Context, msgType string, payload map[string]any) (*WSResultMessage, error) {
+	if d := dryRunFromContext(ctx); d != nil {
+		d.record(DryRunRequest{Transport: DryRunWebSocket, Message: wsMessage(msgType, payload)})
+		return &WSResultMessage{Type: "result", Success: true}, nil
+	}
+	return c.ws.SendCommand(ctx, msgType, payload)
 }
 
 // =============================================================================
@@ -218,7 +276,7 @@ func (c *wsClientImpl) CreateAutomation(ctx context.Context, config AutomationCo
 		params["mode"] = config.Mode
 	}
 
-	_, err := c.ws.SendCommand(ctx, "config/automation/create", params)
+	_, err := c.sendMutation(ctx, "config/automation/create", params)
 	if err != nil {
 		return fmt.Errorf("create automation failed: %w", err)
 	}
@@ -250,7 +308,7 @@ func (c *wsClientImpl) UpdateAutomation(ctx context.Context, automationID string
 		params["mode"] = config.Mode
 	}
 
-	_, err := c.ws.SendCommand(ctx, "config/automation/update", params)
+	_, err := c.sendMutation(ctx, "config/automation/update", params)
 	if err != nil {
 		return fmt.Errorf("update automation failed: %w", err)
 	}
@@ -260,7 +318,7 @@ func (c *wsClientImpl) UpdateAutomation(ctx context.Context, automationID string
 
 // DeleteAutomation deletes an automation.
 func (c *wsClientImpl) DeleteAutomation(ctx context.Context, automationID string) error {
-	_, err := c.ws.SendCommand(ctx, "config/automation/delete", map[string]any{
+	_, err := c.sendMutation(ctx, "config/automation/delete", map[string]any{
 		"automation_id": automationID,
 	})
 	if err != nil {
@@ -333,7 +391,7 @@ func (c *wsClientImpl) CreateHelper(ctx context.Context, config HelperConfig) er
 		}
 	}
 
-	_, err := c.ws.SendCommand(ctx, cmdType, params)
+	_, err := c.sendMutation(ctx, cmdType, params)
 	if err != nil {
 		return fmt.Errorf("create helper failed: %w", err)
 	}
@@ -355,7 +413,7 @@ func (c *wsClientImpl) UpdateHelper(ctx context.Context, helperID string, config
 		}
 	}
 
-	_, err := c.ws.SendCommand(ctx, cmdType, params)
+	_, err := c.sendMutation(ctx, cmdType, params)
 	if err != nil {
 		return fmt.Errorf("update helper failed: %w", err)
 	}
@@ -375,7 +433,7 @@ func (c *wsClientImpl) DeleteHelper(ctx context.Context, helperID string) error
 	id := helperID[len(platform)+1:]
 	cmdType := fmt.Sprintf("%s/delete", platform)
 
-	_, err := c.ws.SendCommand(ctx, cmdType, map[string]any{
+	_, err := c.sendMutation(ctx, cmdType, map[string]any{
 		platform + "_id": id,
 	})
 	if err != nil {
@@ -525,7 +583,7 @@ func (c *wsClientImpl) CreateScript(ctx context.Context, scriptID string, config
 		params["fields"] = config.Fields
 	}
 
-	_, err := c.ws.SendCommand(ctx, "config/script/create", params)
+	_, err := c.sendMutation(ctx, "config/script/create", params)
 	if err != nil {
 		return fmt.Errorf("create script failed: %w", err)
 	}
@@ -557,7 +615,7 @@ func (c *wsClientImpl) UpdateScript(ctx context.Context, scriptID string, config
 		params["fields"] = config.Fields
 	}
 
-	_, err := c.ws.SendCommand(ctx, "config/script/update", params)
+	_, err := c.sendMutation(ctx, "config/script/update", params)
 	if err != nil {
 		return fmt.Errorf("update script failed: %w", err)
 	}
@@ -567,7 +625,7 @@ func (c *wsClientImpl) UpdateScript(ctx context.Context, scriptID string, config
 
 // DeleteScript deletes a script.
 func (c *wsClientImpl) DeleteScript(ctx context.Context, scriptID string) error {
-	_, err := c.ws.SendCommand(ctx, "config/script/delete", map[string]any{
+	_, err := c.sendMutation(ctx, "config/script/delete", map[string]any{
 		"script_id": scriptID,
 	})
 	if err != nil {
@@ -611,7 +669,7 @@ func (c *wsClientImpl) CreateScene(ctx context.Context, sceneID string, config S
 		params["entities"] = config.Entities
 	}
 
-	_, err := c.ws.SendCommand(ctx, "config/scene/create", params)
+	_, err := c.sendMutation(ctx, "config/scene/create", params)
 	if err != nil {
 		return fmt.Errorf("create scene failed: %w", err)
 	}
@@ -634,7 +692,7 @@ func (c *wsClientImpl) UpdateScene(ctx context.Context, sceneID string, config S
 		params["entities"] = config.Entities
 	}
 
-	_, err := c.ws.SendCommand(ctx, "config/scene/update", params)
+	_, err := c.sendMutation(ctx, "config/scene/update", params)
 	if err != nil {
 		return fmt.Errorf("update scene failed: %w", err)
 	}
@@ -644,7 +702,7 @@ func (c *wsClientImpl) UpdateScene(ctx context.Context, sceneID string, config S
 
 // DeleteScene deletes a scene.
 func (c *wsClientImpl) DeleteScene(ctx context.Context, sceneID string) error {
-	_, err := c.ws.SendCommand(ctx, "config/scene/delete", map[string]any{
+	_, err := c.sendMutation(ctx, "config/scene/delete", map[string]any{
 		"scene_id": sceneID,
 	})
 	if err != nil {
@@ -829,6 +887,65 @@ func (c *wsClientImpl) GetLovelaceConfig(ctx context.Context) (map[string]any, e
 	return config, nil
 }
 
+// GetDashboardConfig retrieves the configuration of the dashboard with the given URL path.
+// An empty URL path selects the default dashboard.
+func (c *wsClientImpl) GetDashboardConfig(ctx context.Context, urlPath string) (map[string]any, error) {
+	var params map[string]any
+	if urlPath != "" {
+		params = map[string]any{"url_path": urlPath}
+	}
+
+	result, err := c.ws.SendCommand(ctx, "lovelace/config", params)
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard config failed: %w", err)
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal(result.Result, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dashboard config: %w", err)
+	}
+
+	return config, nil
+}
+
+// ListDashboards lists the Lovelace dashboards. The default dashboard is not included.
+func (c *wsClientImpl) ListDashboards(ctx context.Context) ([]Dashboard, error) {
+	result, err := c.ws.SendCommand(ctx, "lovelace/dashboards/list", nil)
+	if err != nil {
+		return nil, fmt.Errorf("list dashboards failed: %w", err)
+	}
+
+	var dashboards []Dashboard
+	if err := json.Unmarshal(result.Result, &dashboards); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dashboards: %w", err)
+	}
+
+	return dashboards, nil
+}
+
+// =============================================================================
+// Event Operations (WebSocket-only)
+// =============================================================================
+
+// Ensure wsClientImpl can stream state changes.
+var _ StateSubscriber = (*wsClientImpl)(nil)
+
+// SubscribeStateChanges subscribes to state_changed events.
+func (c *wsClientImpl) SubscribeStateChanges(ctx context.Context, handler func(StateChangedEvent)) (func(context.Context) error, error) {
+	id, err := c.ws.subscribeEvents(ctx, "state_changed", func(event WSEvent) {
+		if changed, ok := parseStateChangedEvent(event); ok {
+			handler(changed)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		return c.ws.Unsubscribe(ctx, id)
+	}, nil
+}
+
 // =============================================================================
 // Statistics Operations (WebSocket-only)
 // =============================================================================
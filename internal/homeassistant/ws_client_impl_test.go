@@ -1089,3 +1089,187 @@ func TestHelperPrefixes(t *testing.T) {
 		}
 	}
 }
+
+func TestWSClientImpl_GetDashboardConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		urlPath     string
+		wantURLPath any
+	}{
+		{name: "default dashboard", urlPath: "", wantURLPath: nil},
+		{name: "named dashboard", urlPath: "dashboard-energy", wantURLPath: "dashboard-energy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotURLPath := make(chan any, 1)
+			fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+				if cmd["type"] == "lovelace/config" {
+					gotURLPath <- cmd["url_path"]
+					s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true, "result": map[string]any{"title": "Home"}})
+				}
+			})
+			impl := NewWSClientImpl(connectFakeClient(t, fs))
+
+			config, err := impl.GetDashboardConfig(context.Background(), tt.urlPath)
+			if err != nil {
+				t.Fatalf("GetDashboardConfig() error = %v", err)
+			}
+			if config["title"] != "Home" {
+				t.Errorf("title = %v, want Home", config["title"])
+			}
+			if got := <-gotURLPath; got != tt.wantURLPath {
+				t.Errorf("url_path = %v, want %v", got, tt.wantURLPath)
+			}
+		})
+	}
+}
+
+func TestWSClientImpl_ListDashboards(t *testing.T) {
+	t.Parallel()
+
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		if cmd["type"] == "lovelace/dashboards/list" {
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true, "result": []any{
+				map[string]any{"id": "energy", "url_path": "dashboard-energy", "title": "Energy", "mode": "storage"},
+			}})
+		}
+	})
+	impl := NewWSClientImpl(connectFakeClient(t, fs))
+
+	dashboards, err := impl.ListDashboards(context.Background())
+	if err != nil {
+		t.Fatalf("ListDashboards() error = %v", err)
+	}
+
+	want := []Dashboard{{ID: "energy", URLPath: "dashboard-energy", Title: "Energy", Mode: "storage"}}
+	if diff := cmp.Diff(want, dashboards); diff != "" {
+		t.Errorf("ListDashboards() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWSClientImpl_SubscribeStateChanges(t *testing.T) {
+	t.Parallel()
+
+	unsubscribed := make(chan any, 1)
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		switch cmd["type"] {
+		case "subscribe_events":
+			if cmd["event_type"] != "state_changed" {
+				t.Errorf("event_type = %v, want state_changed", cmd["event_type"])
+			}
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+			s.reply(map[string]any{"id": cmd["id"], "type": "event", "event": map[string]any{
+				"event_type": "state_changed",
+				"data": map[string]any{
+					"entity_id": "binary_sensor.door",
+					"old_state": map[string]any{"entity_id": "binary_sensor.door", "state": "off"},
+					"new_state": map[string]any{"entity_id": "binary_sensor.door", "state": "on"},
+				},
+			}})
+			// Events of other subscriptions are not delivered
+			s.reply(map[string]any{"id": 9999, "type": "event", "event": map[string]any{
+				"event_type": "state_changed",
+				"data":       map[string]any{"entity_id": "light.other"},
+			}})
+		case "unsubscribe_events":
+			unsubscribed <- cmd["subscription"]
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+		}
+	})
+	impl := &wsClientImpl{ws: connectFakeClient(t, fs)}
+
+	events := make(chan StateChangedEvent, 4)
+	unsubscribe, err := impl.SubscribeStateChanges(context.Background(), func(e StateChangedEvent) {
+		events <- e
+	})
+	if err != nil {
+		t.Fatalf("SubscribeStateChanges() error = %v", err)
+	}
+
+	select {
+	case e := <-events:
+		if e.EntityID != "binary_sensor.door" || e.OldState.State != "off" || e.NewState.State != "on" {
+			t.Errorf("event = %+v, want door off -> on", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no state_changed event received")
+	}
+
+	if err := unsubscribe(context.Background()); err != nil {
+		t.Fatalf("unsubscribe() error = %v", err)
+	}
+	if sub := <-unsubscribed; sub == nil {
+		t.Error("unsubscribe_events sent without subscription ID")
+	}
+
+	select {
+	case e := <-events:
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+}
+
+func TestWSClientImpl_CallServiceRecordsContext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		result any
+		want   []string
+	}{
+		{
+			name:   "context only",
+			result: map[string]any{"context": map[string]any{"id": "01HCTX"}},
+			want:   []string{"01HCTX"},
+		},
+		{
+			name: "context with changed entities",
+			result: map[string]any{
+				"context":  map[string]any{"id": "01HCTX"},
+				"response": []any{map[string]any{"entity_id": "light.kitchen", "state": "on"}},
+			},
+			want: []string{"01HCTX"},
+		},
+		{
+			name: "context with service response",
+			result: map[string]any{
+				"context":  map[string]any{"id": "01HCTX"},
+				"response": map[string]any{"weather.home": map[string]any{"forecast": []any{}}},
+			},
+			want: []string{"01HCTX"},
+		},
+		{
+			name:   "no context",
+			result: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+				if cmd["type"] == "call_service" {
+					s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true, "result": tt.result})
+				}
+			})
+			impl := NewWSClientImpl(connectFakeClient(t, fs))
+
+			var got []string
+			ctx := WithServiceContextRecorder(context.Background(), func(id string) {
+				got = append(got, id)
+			})
+			if _, err := impl.CallService(ctx, "light", "turn_on", map[string]any{"entity_id": "light.kitchen"}); err != nil {
+				t.Fatalf("CallService() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("recorded contexts mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package homeassistant
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -476,3 +477,65 @@ func TestWSClient_ConcurrentMsgIDIncrement(t *testing.T) {
 		t.Errorf("final msgID = %d, want 100", client.msgID.Load())
 	}
 }
+
+func TestWSClient_SendCommand(t *testing.T) {
+	t.Parallel()
+
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		if cmd["type"] == "get_states" {
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true, "result": []any{}})
+		}
+	})
+	client := connectFakeClient(t, fs)
+
+	result, err := client.SendCommand(context.Background(), "get_states", nil)
+	if err != nil {
+		t.Fatalf("SendCommand() error = %v", err)
+	}
+	if !result.Success {
+		t.Error("SendCommand() result not successful")
+	}
+}
+
+func TestWSClient_SendCommand_CancelKeepsConnection(t *testing.T) {
+	t.Parallel()
+
+	// The server answers pings but never answers slow commands
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		if cmd["type"] == "ping" {
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+		}
+	})
+	client := connectFakeClient(t, fs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := client.SendCommand(ctx, "history/history_during_period", nil)
+		errCh <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("SendCommand() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SendCommand() did not return after cancellation")
+	}
+
+	// An already canceled context must not touch the connection either
+	if _, err := client.SendCommand(ctx, "get_states", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("SendCommand() with canceled ctx error = %v, want context.Canceled", err)
+	}
+
+	if _, err := client.SendCommand(context.Background(), "ping", nil); err != nil {
+		t.Errorf("SendCommand() after cancellation error = %v, want connection still usable", err)
+	}
+	if !client.IsConnected() {
+		t.Error("client disconnected by cancellation")
+	}
+}
@@ -0,0 +1,123 @@
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// fakeHAServer is a minimal Home Assistant WebSocket API for client tests.
+// It performs the auth handshake and hands every later command to onCommand,
+// which may reply through the server's reply method.
+type fakeHAServer struct {
+	t         *testing.T
+	server    *httptest.Server
+	onCommand func(s *fakeHAServer, cmd map[string]any)
+
+	mu    sync.Mutex
+	conn  *websocket.Conn
+	conns int
+}
+
+// newFakeHAServer starts a fake Home Assistant server.
+func newFakeHAServer(t *testing.T, onCommand func(s *fakeHAServer, cmd map[string]any)) *fakeHAServer {
+	t.Helper()
+
+	fs := &fakeHAServer{t: t, onCommand: onCommand}
+	fs.server = httptest.NewServer(http.HandlerFunc(fs.handle))
+	t.Cleanup(fs.server.Close)
+	return fs
+}
+
+// URL returns the HTTP base URL of the fake server.
+func (fs *fakeHAServer) URL() string {
+	return fs.server.URL
+}
+
+// connections returns how many connections completed authentication.
+func (fs *fakeHAServer) connections() int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.conns
+}
+
+// reply writes msg to the current connection.
+func (fs *fakeHAServer) reply(msg any) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		fs.t.Errorf("marshaling reply: %v", err)
+		return
+	}
+
+	fs.mu.Lock()
+	conn := fs.conn
+	fs.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = conn.Write(ctx, websocket.MessageText, data)
+}
+
+// dropConnection closes the current connection from the server side.
+func (fs *fakeHAServer) dropConnection() {
+	fs.mu.Lock()
+	conn := fs.conn
+	fs.mu.Unlock()
+	_ = conn.Close(websocket.StatusGoingAway, "test drop")
+}
+
+func (fs *fakeHAServer) handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.CloseNow() }()
+
+	ctx := r.Context()
+	_ = conn.Write(ctx, websocket.MessageText, []byte(`{"type":"auth_required"}`))
+	if _, _, err := conn.Read(ctx); err != nil {
+		return
+	}
+	_ = conn.Write(ctx, websocket.MessageText, []byte(`{"type":"auth_ok"}`))
+
+	fs.mu.Lock()
+	fs.conn = conn
+	fs.conns++
+	fs.mu.Unlock()
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		var cmd map[string]any
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			continue
+		}
+		if fs.onCommand != nil {
+			fs.onCommand(fs, cmd)
+		}
+	}
+}
+
+// connectFakeClient connects a WSClient without reconnection or health checks to fs.
+func connectFakeClient(t *testing.T, fs *fakeHAServer) *WSClient {
+	t.Helper()
+
+	config := DefaultWSClientConfig()
+	config.AutoReconnect = false
+	config.PingInterval = 0
+
+	client := NewWSClientWithConfig(fs.URL(), "test-token", config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
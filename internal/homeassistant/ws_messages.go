@@ -55,13 +55,15 @@ type WSEventMessage struct {
 	Event WSEvent `json:"event"`
 }
 
-// WSEvent contains event data.
+// WSEvent contains event data. Events of trigger subscriptions carry the
+// trigger variables in Variables instead of an event type and data.
 type WSEvent struct {
 	EventType string         `json:"event_type"`
 	Data      map[string]any `json:"data"`
 	Origin    string         `json:"origin"`
 	TimeFired string         `json:"time_fired"`
 	Context   Context        `json:"context"`
+	Variables map[string]any `json:"variables,omitempty"`
 }
 
 // WSCommand represents a command to send to Home Assistant.
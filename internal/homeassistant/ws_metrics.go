@@ -0,0 +1,65 @@
+// Package homeassistant provides metrics of the WebSocket connection.
+package homeassistant
+
+import (
+	"math"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/metrics"
+)
+
+// wsMetrics holds the metrics a WSClient records as commands complete.
+type wsMetrics struct {
+	commandDuration *metrics.HistogramVec
+}
+
+// RegisterMetrics registers the metrics of the connection with reg: the
+// latency of commands per command type, the number of requests waiting for a
+// response, the current reconnection attempt, whether the client is connected,
+// and the seconds since the last pong. A registry takes the metrics of one
+// client only.
+func (c *WSClient) RegisterMetrics(reg *metrics.Registry) {
+	c.metrics.Store(&wsMetrics{
+		commandDuration: reg.NewHistogramVec("ha_mcp_ws_command_duration_seconds",
+			"Time from sending a WebSocket command to Home Assistant until its result arrived.",
+			metrics.DefaultBuckets, "type"),
+	})
+
+	reg.NewGaugeFunc("ha_mcp_ws_pending_requests",
+		"WebSocket commands waiting for a result.",
+		func() float64 { return float64(c.pendingCount()) })
+	reg.NewGaugeFunc("ha_mcp_ws_reconnect_attempts",
+		"Reconnection attempts since the connection was lost; 0 while connected.",
+		func() float64 { return float64(c.reconnectMgr.GetAttempts()) })
+	reg.NewGaugeFunc("ha_mcp_ws_connected",
+		"Whether the WebSocket connection to Home Assistant is up (1) or down (0).",
+		func() float64 {
+			if c.IsConnected() {
+				return 1
+			}
+			return 0
+		})
+	reg.NewGaugeFunc("ha_mcp_ws_last_pong_age_seconds",
+		"Seconds since the last pong from Home Assistant; NaN if health monitoring is disabled.",
+		func() float64 {
+			lastPong := c.GetLastPongTime()
+			if lastPong.IsZero() {
+				return math.NaN()
+			}
+			return time.Since(lastPong).Seconds()
+		})
+}
+
+// pendingCount returns the number of commands waiting for a result.
+func (c *WSClient) pendingCount() int {
+	c.pendingMu.RLock()
+	defer c.pendingMu.RUnlock()
+	return len(c.pending)
+}
+
+// observeCommand records the latency of a command that got a result.
+func (c *WSClient) observeCommand(msgType string, start time.Time) {
+	if m := c.metrics.Load(); m != nil {
+		m.commandDuration.Observe(time.Since(start).Seconds(), msgType)
+	}
+}
@@ -0,0 +1,56 @@
+package homeassistant
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/metrics"
+)
+
+func TestWSClient_RegisterMetrics(t *testing.T) {
+	t.Parallel()
+
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true, "result": []any{}})
+	})
+	client := connectFakeClient(t, fs)
+
+	reg := metrics.NewRegistry()
+	client.RegisterMetrics(reg)
+
+	for range 2 {
+		if _, err := client.SendCommand(context.Background(), "get_states", nil); err != nil {
+			t.Fatalf("SendCommand() error = %v", err)
+		}
+	}
+
+	var sb strings.Builder
+	if err := reg.WriteText(&sb); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	for _, want := range []string{
+		`ha_mcp_ws_command_duration_seconds_count{type="get_states"} 2`,
+		"ha_mcp_ws_pending_requests 0",
+		"ha_mcp_ws_reconnect_attempts 0",
+		"ha_mcp_ws_connected 1",
+		"ha_mcp_ws_last_pong_age_seconds NaN",
+	} {
+		if !strings.Contains(sb.String(), want+"\n") {
+			t.Errorf("metrics do not contain %q:\n%s", want, sb.String())
+		}
+	}
+
+	// The read loop marks the connection down once it has stopped
+	_ = client.Close()
+	deadline := time.Now().Add(time.Second)
+	for client.IsConnected() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	sb.Reset()
+	_ = reg.WriteText(&sb)
+	if !strings.Contains(sb.String(), "ha_mcp_ws_connected 0\n") {
+		t.Errorf("metrics after Close do not report the connection down:\n%s", sb.String())
+	}
+}
@@ -0,0 +1,62 @@
+// Package homeassistant provides retries of read-only WebSocket commands.
+package homeassistant
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// readOnlyCommands are the commands that only read from Home Assistant. They
+// can be sent again safely when the connection is lost before they are
+// answered, whereas any other command may already have taken effect.
+var readOnlyCommands = map[string]bool{
+	"get_states":                        true,
+	"get_config":                        true,
+	"get_services":                      true,
+	"config/area_registry/list":         true,
+	"config/device_registry/list":       true,
+	"config/entity_registry/list":       true,
+	"history/history_during_period":     true,
+	"recorder/statistics_during_period": true,
+	"automation/config":                 true,
+	"script/config":                     true,
+	"lovelace/config":                   true,
+	"lovelace/dashboards/list":          true,
+	"schedule/list":                     true,
+	"media_source/browse_media":         true,
+	"extract_from_target":               true,
+	"get_services_for_target":           true,
+	"get_triggers_for_target":           true,
+	"get_conditions_for_target":         true,
+}
+
+// sendWithRetry sends a read-only command and sends it again under a new
+// message ID, after a doubling backoff, when the connection is lost before it
+// is answered. Once the connection was lost, a retry that finds the client
+// still reconnecting counts as a failed attempt too.
+func (c *WSClient) sendWithRetry(ctx context.Context, msgType string, payload map[string]any) (*WSResultMessage, error) {
+	backoff := c.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := c.sendCommandWithID(ctx, c.msgID.Add(1), msgType, payload)
+		if err == nil || attempt >= c.config.CommandRetries || !isConnectionLoss(err, attempt) {
+			return result, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, context.Cause(ctx)
+		}
+		backoff *= 2
+	}
+}
+
+// isConnectionLoss reports whether a command attempt failed because the
+// connection was lost. A first attempt that finds no connection is not
+// retried, since the connection may be gone for good.
+func isConnectionLoss(err error, attempt int) bool {
+	return errors.Is(err, ErrConnectionClosed) || (attempt > 0 && errors.Is(err, ErrNotConnected))
+}
@@ -0,0 +1,133 @@
+package homeassistant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWSClient_CommandTimeout(t *testing.T) {
+	t.Parallel()
+
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		if cmd["type"] == "get_config" {
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+		}
+		// Anything else is never answered
+	})
+
+	config := DefaultWSClientConfig()
+	config.AutoReconnect = false
+	config.PingInterval = 0
+	config.CommandTimeout = 50 * time.Millisecond
+	client := NewWSClientWithConfig(fs.URL(), "test-token", config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	_, err := client.SendCommand(context.Background(), "get_states", nil)
+	if !errors.Is(err, ErrCommandTimeout) {
+		t.Fatalf("SendCommand() error = %v, want ErrCommandTimeout", err)
+	}
+
+	// A timed out command leaves the connection usable
+	if _, err := client.SendCommand(context.Background(), "get_config", nil); err != nil {
+		t.Errorf("SendCommand() after a timeout error = %v", err)
+	}
+
+	// The caller's own cancellation is reported as such
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.SendCommand(ctx, "get_states", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("SendCommand() with a canceled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWSClient_RetryAfterConnectionLoss(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		msgType   string
+		wantErr   error
+		wantSends int
+	}{
+		{name: "read-only command is retried", msgType: "get_states", wantSends: 2},
+		{name: "history is retried", msgType: "history/history_during_period", wantSends: 2},
+		{name: "mutation is not retried", msgType: "call_service", wantErr: ErrConnectionClosed, wantSends: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			sends := 0
+			fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+				if cmd["type"] != tt.msgType {
+					return
+				}
+				mu.Lock()
+				sends++
+				first := sends == 1
+				mu.Unlock()
+
+				// The first attempt is lost with the connection
+				if first {
+					s.dropConnection()
+					return
+				}
+				s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+			})
+
+			config := DefaultWSClientConfig()
+			config.PingInterval = 0
+			config.ReconnectConfig.InitialDelay = 10 * time.Millisecond
+			config.RetryBackoff = 50 * time.Millisecond
+			config.CommandRetries = 3
+			client := NewWSClientWithConfig(fs.URL(), "test-token", config)
+			if err := client.Connect(context.Background()); err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			t.Cleanup(func() { _ = client.Close() })
+
+			_, err := client.SendCommand(context.Background(), tt.msgType, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SendCommand() error = %v, want %v", err, tt.wantErr)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if sends != tt.wantSends {
+				t.Errorf("%s sent %d times, want %d", tt.msgType, sends, tt.wantSends)
+			}
+		})
+	}
+}
+
+func TestWSClient_RetriesExhausted(t *testing.T) {
+	t.Parallel()
+
+	fs := newFakeHAServer(t, func(s *fakeHAServer, _ map[string]any) {
+		s.dropConnection()
+	})
+
+	config := DefaultWSClientConfig()
+	config.PingInterval = 0
+	config.ReconnectConfig.InitialDelay = 10 * time.Millisecond
+	config.RetryBackoff = 50 * time.Millisecond
+	config.CommandRetries = 1
+	client := NewWSClientWithConfig(fs.URL(), "test-token", config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	// The last attempt fails like the first, or finds the client reconnecting
+	_, err := client.SendCommand(context.Background(), "get_states", nil)
+	if !errors.Is(err, ErrConnectionClosed) && !errors.Is(err, ErrNotConnected) {
+		t.Errorf("SendCommand() error = %v, want ErrConnectionClosed or ErrNotConnected", err)
+	}
+}
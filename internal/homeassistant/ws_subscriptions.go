@@ -0,0 +1,325 @@
+// Package homeassistant provides event and trigger subscriptions over the WebSocket API.
+package homeassistant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultEventBufferSize is the number of events a subscription buffers when
+// WSClientConfig.EventBufferSize is not set.
+const defaultEventBufferSize = 256
+
+// EventResynced is the event type of the synthetic event delivered to every
+// subscription once it has been renewed after a reconnect. Events may have
+// been missed while the connection was down, so consumers should refresh any
+// state derived from earlier events.
+const EventResynced = "resynced"
+
+// Errors reported by subscriptions.
+var (
+	// ErrUnknownSubscription is returned by Unsubscribe for an ID that does
+	// not belong to an active subscription.
+	ErrUnknownSubscription = errors.New("unknown subscription")
+	// ErrSubscriptionLost is the reason a subscription ended when the
+	// connection to Home Assistant was lost for good or Home Assistant
+	// refused to renew it after a reconnect.
+	ErrSubscriptionLost = errors.New("subscription lost with the connection")
+)
+
+// EventHandler receives the events of an event subscription. It is called from
+// the read loop, so it must not block.
+type EventHandler func(event WSEvent)
+
+// subscription is an entry of the registry of active subscriptions, which
+// are renewed on every new connection.
+type subscription struct {
+	msgType string
+	payload map[string]any
+	handler EventHandler
+	end     func(error) // ends a Subscription; nil for handler subscriptions
+
+	// serverID is the ID Home Assistant knows the subscription by on the
+	// current connection. It differs from the handle after a reconnect.
+	serverID int64
+}
+
+// Subscription delivers the events of an event or trigger subscription.
+//
+// Events are buffered so that a slow reader never holds up the connection.
+// When the buffer is full, the oldest buffered event is discarded to make room
+// for the new one, and Dropped counts the discarded events. After a reconnect
+// the subscription is renewed and delivers an EventResynced event. The Events
+// channel is closed when the subscription ends; Err then tells why.
+type Subscription struct {
+	id      int64
+	events  chan WSEvent
+	dropped atomic.Int64
+
+	mu     sync.Mutex
+	closed bool
+	err    error
+}
+
+// ID returns the subscription ID to pass to Unsubscribe. It stays the same
+// across reconnects.
+func (s *Subscription) ID() int64 {
+	return s.id
+}
+
+// Events returns the channel the events are delivered on.
+func (s *Subscription) Events() <-chan WSEvent {
+	return s.events
+}
+
+// Dropped returns the number of events discarded because the buffer was full.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Err returns why the subscription ended: nil after Unsubscribe, or
+// ErrSubscriptionLost if the connection was lost. It returns nil while the
+// subscription is active.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// deliver buffers an event, discarding the oldest buffered event if the
+// buffer is full. It never blocks, since it runs on the read loop.
+func (s *Subscription) deliver(event WSEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.events <- event:
+		return
+	default:
+	}
+	select {
+	case <-s.events:
+		s.dropped.Add(1)
+	default:
+	}
+	select {
+	case s.events <- event:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// end closes the Events channel with the given reason. Later calls have no effect.
+func (s *Subscription) end(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.err = err
+	close(s.events)
+}
+
+// SubscribeEvents subscribes to Home Assistant events of the given type, or
+// to all events if eventType is empty.
+func (c *WSClient) SubscribeEvents(ctx context.Context, eventType string) (*Subscription, error) {
+	sub, err := c.startSubscription(ctx, "subscribe_events", eventsPayload(eventType))
+	if err != nil {
+		return nil, fmt.Errorf("subscribing to events: %w", err)
+	}
+	return sub, nil
+}
+
+// SubscribeTrigger subscribes to a Home Assistant trigger, given in the
+// automation trigger format as a single trigger or a list. An event is
+// delivered every time the trigger fires; its Variables hold the trigger
+// variables.
+func (c *WSClient) SubscribeTrigger(ctx context.Context, trigger any) (*Subscription, error) {
+	sub, err := c.startSubscription(ctx, "subscribe_trigger", map[string]any{"trigger": trigger})
+	if err != nil {
+		return nil, fmt.Errorf("subscribing to trigger: %w", err)
+	}
+	return sub, nil
+}
+
+// startSubscription starts a subscription whose events go to a new Subscription.
+func (c *WSClient) startSubscription(ctx context.Context, msgType string, payload map[string]any) (*Subscription, error) {
+	bufferSize := c.config.EventBufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultEventBufferSize
+	}
+	sub := &Subscription{events: make(chan WSEvent, bufferSize)}
+
+	id, err := c.subscribe(ctx, &subscription{msgType: msgType, payload: payload, handler: sub.deliver, end: sub.end})
+	if err != nil {
+		return nil, err
+	}
+	sub.id = id
+	return sub, nil
+}
+
+// subscribeEvents subscribes to Home Assistant events of the given type, or to
+// all events if eventType is empty, and returns the subscription ID. Events
+// go to handler, which also receives an EventResynced event after a reconnect.
+func (c *WSClient) subscribeEvents(ctx context.Context, eventType string, handler EventHandler) (int64, error) {
+	id, err := c.subscribe(ctx, &subscription{msgType: "subscribe_events", payload: eventsPayload(eventType), handler: handler})
+	if err != nil {
+		return 0, fmt.Errorf("subscribing to events: %w", err)
+	}
+	return id, nil
+}
+
+// eventsPayload returns the payload of a subscribe_events command.
+func eventsPayload(eventType string) map[string]any {
+	if eventType == "" {
+		return nil
+	}
+	return map[string]any{"event_type": eventType}
+}
+
+// subscribe sends the command that starts sub, adds sub to the registry and
+// returns its ID, which is the handle of the subscription from then on. The
+// handler is registered before the command is sent so that no event is missed.
+func (c *WSClient) subscribe(ctx context.Context, sub *subscription) (int64, error) {
+	id := c.msgID.Add(1)
+	sub.serverID = id
+
+	c.eventMu.Lock()
+	c.events[id] = sub.handler
+	c.subs[id] = sub
+	c.eventMu.Unlock()
+
+	if _, err := c.sendCommandWithID(ctx, id, sub.msgType, sub.payload); err != nil {
+		c.eventMu.Lock()
+		delete(c.events, id)
+		delete(c.subs, id)
+		c.eventMu.Unlock()
+		return 0, err
+	}
+	return id, nil
+}
+
+// Unsubscribe ends a subscription started with SubscribeEvents or
+// SubscribeTrigger and closes its Events channel. The subscription ends even if
+// Home Assistant cannot be told, so no further events are delivered.
+func (c *WSClient) Unsubscribe(ctx context.Context, id int64) error {
+	// serverID changes when renew runs during a reconnect, so it is read
+	// under the same lock that removes the subscription from renewal
+	c.eventMu.Lock()
+	sub, ok := c.subs[id]
+	var serverID int64
+	if ok {
+		serverID = sub.serverID
+		delete(c.subs, id)
+		delete(c.events, serverID)
+	}
+	c.eventMu.Unlock()
+	if !ok {
+		return ErrUnknownSubscription
+	}
+
+	if sub.end != nil {
+		sub.end(nil)
+	}
+	if _, err := c.SendCommand(ctx, "unsubscribe_events", map[string]any{"subscription": serverID}); err != nil {
+		return fmt.Errorf("unsubscribing from events: %w", err)
+	}
+	return nil
+}
+
+// resubscribe renews the registered subscriptions on a new connection under
+// new message IDs and delivers an EventResynced event to each one renewed.
+// A subscription Home Assistant refuses is ended with ErrSubscriptionLost. If
+// the connection is lost again meanwhile, the rest is left to the next
+// reconnect. It must not run on the read loop, which has to read the results.
+func (c *WSClient) resubscribe(ctx context.Context) {
+	c.eventMu.Lock()
+	handles := make([]int64, 0, len(c.subs))
+	for handle := range c.subs {
+		handles = append(handles, handle)
+	}
+	c.eventMu.Unlock()
+
+	for _, handle := range handles {
+		if !c.connected.Load() {
+			return
+		}
+		if sub, err := c.renew(ctx, handle); err == nil {
+			sub.handler(WSEvent{EventType: EventResynced})
+		} else if sub != nil && c.connected.Load() {
+			c.dropSubscription(handle, sub)
+		}
+	}
+}
+
+// renew sends the command of a registered subscription again under a new
+// message ID and routes its events to the handler. It returns the
+// subscription, or nil if it was ended meanwhile. A subscription ended while
+// the command was in flight is ended on the server as well, since Unsubscribe
+// may have reached Home Assistant before it.
+func (c *WSClient) renew(ctx context.Context, handle int64) (*subscription, error) {
+	id := c.msgID.Add(1)
+
+	c.eventMu.Lock()
+	sub, ok := c.subs[handle]
+	if !ok {
+		c.eventMu.Unlock()
+		return nil, ErrUnknownSubscription
+	}
+	delete(c.events, sub.serverID)
+	sub.serverID = id
+	c.events[id] = sub.handler
+	c.eventMu.Unlock()
+
+	if _, err := c.sendCommandWithID(ctx, id, sub.msgType, sub.payload); err != nil {
+		return sub, err
+	}
+
+	c.eventMu.Lock()
+	active := c.subs[handle] == sub
+	c.eventMu.Unlock()
+	if !active {
+		_, _ = c.SendCommand(ctx, "unsubscribe_events", map[string]any{"subscription": id})
+		return nil, ErrUnknownSubscription
+	}
+	return sub, nil
+}
+
+// dropSubscription removes a subscription Home Assistant refused to renew.
+func (c *WSClient) dropSubscription(handle int64, sub *subscription) {
+	c.eventMu.Lock()
+	if c.subs[handle] == sub {
+		delete(c.subs, handle)
+		delete(c.events, sub.serverID)
+	}
+	c.eventMu.Unlock()
+
+	if sub.end != nil {
+		sub.end(ErrSubscriptionLost)
+	}
+}
+
+// endSubscriptions ends all subscriptions with ErrSubscriptionLost once the
+// connection is lost for good.
+func (c *WSClient) endSubscriptions() {
+	c.eventMu.Lock()
+	subs := c.subs
+	c.subs = make(map[int64]*subscription)
+	for _, sub := range subs {
+		delete(c.events, sub.serverID)
+	}
+	c.eventMu.Unlock()
+
+	for _, sub := range subs {
+		if sub.end != nil {
+			sub.end(ErrSubscriptionLost)
+		}
+	}
+}
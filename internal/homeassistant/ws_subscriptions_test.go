@@ -0,0 +1,300 @@
+package homeassistant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// nextEvent returns the next event of sub, failing the test if none arrives.
+func nextEvent(t *testing.T, sub *Subscription) (WSEvent, bool) {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events():
+		return event, ok
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+		return WSEvent{}, false
+	}
+}
+
+func TestWSClient_SubscribeEvents(t *testing.T) {
+	t.Parallel()
+
+	unsubscribed := make(chan any, 1)
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		switch cmd["type"] {
+		case "subscribe_events":
+			if cmd["event_type"] != "automation_triggered" {
+				t.Errorf("event_type = %v, want automation_triggered", cmd["event_type"])
+			}
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+			s.reply(map[string]any{"id": cmd["id"], "type": "event", "event": map[string]any{
+				"event_type": "automation_triggered",
+				"data":       map[string]any{"entity_id": "automation.night"},
+			}})
+		case "unsubscribe_events":
+			unsubscribed <- cmd["subscription"]
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+		}
+	})
+	client := connectFakeClient(t, fs)
+
+	sub, err := client.SubscribeEvents(context.Background(), "automation_triggered")
+	if err != nil {
+		t.Fatalf("SubscribeEvents() error = %v", err)
+	}
+
+	event, _ := nextEvent(t, sub)
+	want := WSEvent{EventType: "automation_triggered", Data: map[string]any{"entity_id": "automation.night"}}
+	if diff := cmp.Diff(want, event); diff != "" {
+		t.Errorf("event mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := client.Unsubscribe(context.Background(), sub.ID()); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	if got := <-unsubscribed; got != float64(sub.ID()) {
+		t.Errorf("unsubscribe_events subscription = %v, want %d", got, sub.ID())
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("Events channel still open after Unsubscribe")
+	}
+	if err := sub.Err(); err != nil {
+		t.Errorf("Err() after Unsubscribe = %v, want nil", err)
+	}
+	if err := client.Unsubscribe(context.Background(), sub.ID()); !errors.Is(err, ErrUnknownSubscription) {
+		t.Errorf("second Unsubscribe() error = %v, want ErrUnknownSubscription", err)
+	}
+}
+
+func TestWSClient_SubscribeTrigger(t *testing.T) {
+	t.Parallel()
+
+	trigger := map[string]any{"platform": "state", "entity_id": "binary_sensor.door", "to": "on"}
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		if cmd["type"] != "subscribe_trigger" {
+			return
+		}
+		if diff := cmp.Diff(trigger, cmd["trigger"]); diff != "" {
+			t.Errorf("trigger mismatch (-want +got):\n%s", diff)
+		}
+		s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+		s.reply(map[string]any{"id": cmd["id"], "type": "event", "event": map[string]any{
+			"variables": map[string]any{"trigger": map[string]any{"platform": "state", "entity_id": "binary_sensor.door"}},
+			"context":   map[string]any{"id": "ctx1"},
+		}})
+	})
+	client := connectFakeClient(t, fs)
+
+	sub, err := client.SubscribeTrigger(context.Background(), trigger)
+	if err != nil {
+		t.Fatalf("SubscribeTrigger() error = %v", err)
+	}
+
+	event, _ := nextEvent(t, sub)
+	want := WSEvent{
+		Context:   Context{ID: "ctx1"},
+		Variables: map[string]any{"trigger": map[string]any{"platform": "state", "entity_id": "binary_sensor.door"}},
+	}
+	if diff := cmp.Diff(want, event); diff != "" {
+		t.Errorf("event mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWSClient_SubscriptionLost(t *testing.T) {
+	t.Parallel()
+
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+	})
+	client := connectFakeClient(t, fs)
+
+	sub, err := client.SubscribeEvents(context.Background(), "")
+	if err != nil {
+		t.Fatalf("SubscribeEvents() error = %v", err)
+	}
+
+	fs.dropConnection()
+	if _, ok := nextEvent(t, sub); ok {
+		t.Fatal("Events channel still open after the connection was lost")
+	}
+	if err := sub.Err(); !errors.Is(err, ErrSubscriptionLost) {
+		t.Errorf("Err() = %v, want ErrSubscriptionLost", err)
+	}
+}
+
+func TestSubscription_DropsOldestWhenFull(t *testing.T) {
+	t.Parallel()
+
+	sub := &Subscription{events: make(chan WSEvent, 2)}
+	for _, eventType := range []string{"first", "second", "third", "fourth"} {
+		sub.deliver(WSEvent{EventType: eventType})
+	}
+
+	var got []string
+	for range 2 {
+		got = append(got, (<-sub.Events()).EventType)
+	}
+	if diff := cmp.Diff([]string{"third", "fourth"}, got); diff != "" {
+		t.Errorf("buffered events mismatch (-want +got):\n%s", diff)
+	}
+	if sub.Dropped() != 2 {
+		t.Errorf("Dropped() = %d, want 2", sub.Dropped())
+	}
+
+	sub.end(nil)
+	sub.deliver(WSEvent{EventType: "late"}) // must not panic on the closed channel
+}
+
+func TestWSClient_ResubscribeAfterReconnect(t *testing.T) {
+	t.Parallel()
+
+	subscribed := make(chan float64, 4)
+	unsubscribed := make(chan any, 1)
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		switch cmd["type"] {
+		case "subscribe_events":
+			subscribed <- cmd["id"].(float64)
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+		case "unsubscribe_events":
+			unsubscribed <- cmd["subscription"]
+			s.reply(map[string]any{"id": cmd["id"], "type": "result", "success": true})
+		}
+	})
+
+	config := DefaultWSClientConfig()
+	config.PingInterval = 0
+	config.ReconnectConfig.InitialDelay = 10 * time.Millisecond
+	client := NewWSClientWithConfig(fs.URL(), "test-token", config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	sub, err := client.SubscribeEvents(context.Background(), "state_changed")
+	if err != nil {
+		t.Fatalf("SubscribeEvents() error = %v", err)
+	}
+	firstID := <-subscribed
+
+	fs.dropConnection()
+
+	var renewedID float64
+	select {
+	case renewedID = <-subscribed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription not renewed after reconnect")
+	}
+	if renewedID == firstID {
+		t.Errorf("renewed subscription reuses message ID %v", firstID)
+	}
+	if event, _ := nextEvent(t, sub); event.EventType != EventResynced {
+		t.Errorf("event after reconnect = %+v, want %s", event, EventResynced)
+	}
+
+	// Events under the new ID reach the original handle
+	fs.reply(map[string]any{"id": renewedID, "type": "event", "event": map[string]any{"event_type": "state_changed"}})
+	if event, _ := nextEvent(t, sub); event.EventType != "state_changed" {
+		t.Errorf("event = %+v, want state_changed", event)
+	}
+	if sub.ID() != int64(firstID) {
+		t.Errorf("ID() = %d, want the original %v", sub.ID(), firstID)
+	}
+
+	if err := client.Unsubscribe(context.Background(), sub.ID()); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	if got := <-unsubscribed; got != renewedID {
+		t.Errorf("unsubscribe_events subscription = %v, want the renewed %v", got, renewedID)
+	}
+}
+
+func TestWSClient_UnsubscribeDuringReconnect(t *testing.T) {
+	t.Parallel()
+
+	// The fake server holds back the renewal of the subscription, so that
+	// Unsubscribe overtakes it, as it can when both are in flight
+	var mu sync.Mutex
+	active := make(map[float64]bool)
+	renewed := make(chan float64, 1)
+	fs := newFakeHAServer(t, func(s *fakeHAServer, cmd map[string]any) {
+		id := cmd["id"].(float64)
+		switch cmd["type"] {
+		case "subscribe_events":
+			if s.connections() > 1 {
+				renewed <- id
+				return
+			}
+			mu.Lock()
+			active[id] = true
+			mu.Unlock()
+			s.reply(map[string]any{"id": id, "type": "result", "success": true})
+		case "unsubscribe_events":
+			serverID := cmd["subscription"].(float64)
+			mu.Lock()
+			found := active[serverID]
+			delete(active, serverID)
+			mu.Unlock()
+			if !found {
+				s.reply(map[string]any{"id": id, "type": "result", "success": false,
+					"error": map[string]any{"code": "not_found", "message": "Subscription not found."}})
+				return
+			}
+			s.reply(map[string]any{"id": id, "type": "result", "success": true})
+		}
+	})
+
+	config := DefaultWSClientConfig()
+	config.PingInterval = 0
+	config.ReconnectConfig.InitialDelay = 10 * time.Millisecond
+	client := NewWSClientWithConfig(fs.URL(), "test-token", config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	sub, err := client.SubscribeEvents(context.Background(), "state_changed")
+	if err != nil {
+		t.Fatalf("SubscribeEvents() error = %v", err)
+	}
+
+	fs.dropConnection()
+	var renewedID float64
+	select {
+	case renewedID = <-renewed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription not renewed after reconnect")
+	}
+
+	// Home Assistant does not know the renewed ID yet, so it refuses the unsubscribe
+	if err := client.Unsubscribe(context.Background(), sub.ID()); err == nil {
+		t.Error("Unsubscribe() error = nil, want the refusal of Home Assistant")
+	}
+	mu.Lock()
+	active[renewedID] = true
+	mu.Unlock()
+	fs.reply(map[string]any{"id": renewedID, "type": "result", "success": true})
+
+	// The renewed subscription is ended once it is confirmed
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		leaked := active[renewedID]
+		mu.Unlock()
+		if !leaked {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("renewed subscription left active on the server")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, ok := nextEvent(t, sub); ok {
+		t.Error("Events channel still open after Unsubscribe")
+	}
+}
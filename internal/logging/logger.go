@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -64,23 +65,55 @@ func LevelString(level slog.Level) string {
 // Logger wraps slog.Logger with convenience methods including TRACE level.
 type Logger struct {
 	*slog.Logger
-	level slog.Level
+	level slog.Leveler
+	hook  *hookSlot
+}
+
+// RecordHook is called with every record the logger writes, after it was written,
+// and with records below the log level down to the hook level.
+type RecordHook func(ctx context.Context, r slog.Record)
+
+// hookSlot holds the record hook and its level, shared by a logger and its handler.
+type hookSlot struct {
+	fn    atomic.Pointer[RecordHook]
+	level slog.LevelVar
+}
+
+// enabled reports whether a hook is set that wants records at the given level.
+func (s *hookSlot) enabled(level slog.Level) bool {
+	return s.fn.Load() != nil && level >= s.level.Level()
 }
 
 // cleanHandler implements slog.Handler with a simplified log format:
 // "YYYY-MM-DD HH:MM:SS LEVEL message key=value key=value..."
 type cleanHandler struct {
-	level slog.Level
+	level slog.Leveler
 	out   io.Writer
+	hook  *hookSlot
 }
 
 // Enabled reports whether the handler handles records at the given level.
 func (h *cleanHandler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level() || (h.hook != nil && h.hook.enabled(level))
 }
 
-// Handle formats and writes the log record.
-func (h *cleanHandler) Handle(_ context.Context, r slog.Record) error {
+// Handle formats and writes the log record if it is at or above the log level,
+// then passes it to the record hook, if any.
+func (h *cleanHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	if r.Level >= h.level.Level() {
+		err = h.write(r)
+	}
+	if h.hook != nil {
+		if fn := h.hook.fn.Load(); fn != nil {
+			(*fn)(ctx, r)
+		}
+	}
+	return err
+}
+
+// write formats and writes the log record.
+func (h *cleanHandler) write(r slog.Record) error {
 	// Format: "2026-01-03 20:36:42 INFO Home Assistant URL url=http://..."
 	timeStr := r.Time.Format(time.DateOnly + " " + time.TimeOnly)
 	levelStr := LevelString(r.Level)
@@ -117,15 +150,28 @@ func (h *cleanHandler) WithGroup(_ string) slog.Handler {
 	return h
 }
 
-// New creates a new Logger with the specified level.
+// New creates a new Logger with the specified level that writes to stdout.
 func New(level slog.Level) *Logger {
+	return NewWithWriter(level, os.Stdout)
+}
+
+// NewWithWriter creates a new Logger with the specified level that writes to out.
+// The stdio transport uses this to keep log output on stderr, away from the protocol stream.
+func NewWithWriter(level slog.Level, out io.Writer) *Logger {
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(level)
+	hook := &hookSlot{}
+	hook.level.Set(LevelOff)
+
 	handler := &cleanHandler{
-		level: level,
-		out:   os.Stdout,
+		level: levelVar,
+		out:   out,
+		hook:  hook,
 	}
 	return &Logger{
 		Logger: slog.New(handler),
-		level:  level,
+		level:  levelVar,
+		hook:   hook,
 	}
 }
 
@@ -139,17 +185,57 @@ func (l *Logger) Trace(msg string, args ...any) {
 	l.Log(context.Background(), LevelTrace, msg, args...)
 }
 
-// IsTraceEnabled returns true if TRACE level is enabled.
+// IsTraceEnabled returns true if TRACE records are written or passed to the hook.
 func (l *Logger) IsTraceEnabled() bool {
-	return l.level <= LevelTrace
+	return l.level.Level() <= LevelTrace || (l.hook != nil && l.hook.enabled(LevelTrace))
 }
 
-// IsDebugEnabled returns true if DEBUG level is enabled.
+// IsDebugEnabled returns true if DEBUG records are written or passed to the hook.
 func (l *Logger) IsDebugEnabled() bool {
-	return l.level <= LevelDebug
+	return l.level.Level() <= LevelDebug || (l.hook != nil && l.hook.enabled(LevelDebug))
 }
 
 // Level returns the current log level.
 func (l *Logger) Level() slog.Level {
-	return l.level
+	return l.level.Level()
+}
+
+// SetLevel changes the log level at runtime.
+// It only affects loggers created with New or NewWithWriter.
+func (l *Logger) SetLevel(level slog.Level) {
+	if lv, ok := l.level.(*slog.LevelVar); ok {
+		lv.Set(level)
+	}
+}
+
+// SetHook sets a function that receives every record the logger writes,
+// replacing any previous hook. A nil hook removes it. The hook runs
+// synchronously and must not log through the same logger.
+func (l *Logger) SetHook(fn RecordHook) {
+	if l.hook == nil {
+		return
+	}
+	if fn == nil {
+		l.hook.fn.Store(nil)
+		return
+	}
+	l.hook.fn.Store(&fn)
+}
+
+// SetHookLevel sets the level down to which records are passed to the hook
+// even if they are below the log level and not written. Records at or above
+// the log level always reach the hook.
+func (l *Logger) SetHookLevel(level slog.Level) {
+	if l.hook == nil {
+		return
+	}
+	l.hook.level.Set(level)
+}
+
+// MaskToken masks a secret for display, showing only the first 4 and last 4 characters.
+func MaskToken(token string) string {
+	if len(token) <= 8 {
+		return "****"
+	}
+	return token[:4] + "****" + token[len(token)-4:]
 }
@@ -490,13 +490,31 @@ func TestNew(t *testing.T) {
 				t.Error("New() Logger.Logger is nil")
 			}
 
-			if logger.level != tt.level {
-				t.Errorf("New() level = %v, want %v", logger.level, tt.level)
+			if logger.Level() != tt.level {
+				t.Errorf("New() level = %v, want %v", logger.Level(), tt.level)
 			}
 		})
 	}
 }
 
+func TestNewWithWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := NewWithWriter(LevelInfo, &buf)
+
+	logger.Info("written to custom writer", "key", "value")
+	logger.Debug("filtered out")
+
+	output := buf.String()
+	if !strings.Contains(output, "INFO written to custom writer key=value") {
+		t.Errorf("NewWithWriter() output = %q, want INFO line", output)
+	}
+	if strings.Contains(output, "filtered out") {
+		t.Errorf("NewWithWriter() should respect level, got: %s", output)
+	}
+}
+
 func TestLogger_Trace(t *testing.T) {
 	t.Parallel()
 
@@ -791,3 +809,111 @@ func TestLogger_Trace_NotEnabled(t *testing.T) {
 		t.Errorf("Trace() should not log when level is INFO, got: %s", output)
 	}
 }
+
+func TestLogger_SetLevel(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := NewWithWriter(LevelInfo, &buf)
+
+	logger.Debug("before")
+	logger.SetLevel(LevelDebug)
+	logger.Debug("after")
+
+	if logger.Level() != LevelDebug {
+		t.Errorf("Level() = %v, want %v", logger.Level(), LevelDebug)
+	}
+	if !logger.IsDebugEnabled() {
+		t.Error("IsDebugEnabled() = false after SetLevel(LevelDebug)")
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "before") {
+		t.Errorf("record below the initial level was written: %s", output)
+	}
+	if !strings.Contains(output, "DEBUG after") {
+		t.Errorf("record at the new level was not written: %s", output)
+	}
+}
+
+func TestLogger_SetHook(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := NewWithWriter(LevelInfo, &buf)
+
+	var got []string
+	logger.SetHook(func(_ context.Context, r slog.Record) {
+		got = append(got, LevelString(r.Level)+" "+r.Message)
+	})
+
+	logger.Info("first")
+	logger.Debug("filtered")
+	logger.Warn("second")
+	logger.SetHook(nil)
+	logger.Error("after removal")
+
+	want := []string{"INFO first", "WARN second"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("hook records mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(buf.String(), "after removal") {
+		t.Error("records are no longer written after removing the hook")
+	}
+}
+
+func TestLogger_SetHookLevel(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := NewWithWriter(LevelInfo, &buf)
+
+	var got []string
+	logger.SetHook(func(_ context.Context, r slog.Record) {
+		got = append(got, LevelString(r.Level)+" "+r.Message)
+	})
+
+	logger.Debug("before")
+	logger.SetHookLevel(LevelDebug)
+	if !logger.IsDebugEnabled() {
+		t.Error("IsDebugEnabled() = false with the hook at DEBUG")
+	}
+	logger.Debug("hooked")
+	logger.Info("written")
+
+	want := []string{"DEBUG hooked", "INFO written"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("hook records mismatch (-want +got):\n%s", diff)
+	}
+	if logger.Level() != LevelInfo {
+		t.Errorf("Level() = %v, want %v", logger.Level(), LevelInfo)
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "hooked") || strings.Contains(output, "before") {
+		t.Errorf("record below the log level was written: %s", output)
+	}
+	if !strings.Contains(output, "INFO written") {
+		t.Errorf("record at the log level was not written: %s", output)
+	}
+}
+
+func TestMaskToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{token: "abcdefghijklmnopqrstuvwxyz", want: "abcd****wxyz"},
+		{token: "123456789", want: "1234****6789"},
+		{token: "12345678", want: "****"},
+		{token: "", want: "****"},
+	}
+
+	for _, tt := range tests {
+		if got := MaskToken(tt.token); got != tt.want {
+			t.Errorf("MaskToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
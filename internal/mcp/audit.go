@@ -0,0 +1,296 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+// Results reported in AuditRecord.
+const (
+	AuditResultSuccess   = "success"
+	AuditResultError     = "error"
+	AuditResultCancelled = "cancelled"
+)
+
+// AuditRecord is one line of the audit log.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Session is the MCP session id; empty for stdio.
+	Session string `json:"session,omitempty"`
+	// Client is the client name reported at initialize.
+	Client string `json:"client,omitempty"`
+	// Key is the name of the API key that authenticated the request.
+	Key  string `json:"key,omitempty"`
+	Tool string `json:"tool"`
+	// Arguments are the tool arguments with secrets masked.
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// ContextID is the Home Assistant context id of the change, if the tool reported one.
+	ContextID string `json:"context_id,omitempty"`
+	// Result is AuditResultSuccess, AuditResultError, or AuditResultCancelled.
+	Result string `json:"result"`
+	// Error is the error message of a failed call.
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// AuditLog appends audit records to a file as JSON lines. The file is
+// rotated when it would grow beyond its maximum size: the current file
+// becomes path.1, path.1 becomes path.2, and so on up to the number of
+// backups to keep.
+type AuditLog struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenAuditLog opens the audit log at path for appending, creating it if
+// needed. A maxSize of 0 disables rotation.
+func OpenAuditLog(path string, maxSize int64, maxBackups int) (*AuditLog, error) {
+	a := &AuditLog{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// open opens the file at a.path and records its size. a.mu must be held
+// unless a is not yet shared.
+func (a *AuditLog) open() error {
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // Path comes from trusted configuration
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	a.file = f
+	a.size = info.Size()
+	return nil
+}
+
+// Write appends a record to the audit log, rotating the file first if the
+// record would make it exceed the maximum size.
+func (a *AuditLog) Write(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		return errors.New("audit log is closed")
+	}
+	if a.maxSize > 0 && a.size > 0 && a.size+int64(len(line)) > a.maxSize {
+		if err := a.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := a.file.Write(line)
+	a.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("writing audit record: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts the backups, moves the current file to path.1, and opens a
+// new file. Without backups the current file is discarded. a.mu must be held.
+func (a *AuditLog) rotate() error {
+	if err := a.file.Close(); err != nil {
+		return fmt.Errorf("rotating audit log: %w", err)
+	}
+	a.file = nil
+
+	if a.maxBackups > 0 {
+		for i := a.maxBackups - 1; i >= 1; i-- {
+			if err := os.Rename(a.backupPath(i), a.backupPath(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("rotating audit log: %w", err)
+			}
+		}
+		if err := os.Rename(a.path, a.backupPath(1)); err != nil {
+			return fmt.Errorf("rotating audit log: %w", err)
+		}
+	} else if err := os.Remove(a.path); err != nil {
+		return fmt.Errorf("rotating audit log: %w", err)
+	}
+
+	return a.open()
+}
+
+// backupPath returns the path of the n-th rotated file.
+func (a *AuditLog) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", a.path, n)
+}
+
+// Close closes the audit log file. Later writes fail.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// SetAuditLog enables the audit log. Every call of a tool that is not
+// annotated as read-only is recorded once it has run.
+// It must be called before the server starts serving.
+func (s *Server) SetAuditLog(audit *AuditLog) {
+	s.audit = audit
+}
+
+// auditCall collects the audit record of a running tool call.
+type auditCall struct {
+	record AuditRecord
+	start  time.Time
+
+	mu        sync.Mutex
+	contextID string
+}
+
+// auditContextKey is the context key for the audit record of the current tool call.
+type auditContextKey struct{}
+
+// RecordContextID records the Home Assistant context id of the change made by
+// the current tool call in its audit record. The first non-empty id is kept.
+// It does nothing if the call is not audited.
+//
+// The contexts of service calls are recorded by the client, so tools only
+// need it for changes made otherwise.
+func RecordContextID(ctx context.Context, id string) {
+	call, _ := ctx.Value(auditContextKey{}).(*auditCall)
+	if call == nil {
+		return
+	}
+	call.recordContextID(id)
+}
+
+// recordContextID keeps id as the context id of the call unless one was
+// already recorded.
+func (c *auditCall) recordContextID(id string) {
+	if id == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.contextID == "" {
+		c.contextID = id
+	}
+}
+
+// startAudit starts the audit record of a call of the named tool. It returns
+// ctx unchanged and a nil call if the audit log is disabled or the tool is read-only.
+func (s *Server) startAudit(ctx context.Context, name string, args map[string]any) (context.Context, *auditCall) {
+	if s.audit == nil {
+		return ctx, nil
+	}
+	tool, ok := s.registry.GetTool(name)
+	if !ok || isReadOnly(tool) {
+		return ctx, nil
+	}
+
+	sess := s.sessionFor(ctx)
+	call := &auditCall{
+		record: AuditRecord{
+			Session:   sess.ID(),
+			Client:    sess.ClientInfo().Name,
+			Key:       APIKeyNameFromContext(ctx),
+			Tool:      name,
+			Arguments: s.redactArguments(args),
+		},
+		start: time.Now(),
+	}
+	ctx = homeassistant.WithServiceContextRecorder(ctx, call.recordContextID)
+	return context.WithValue(ctx, auditContextKey{}, call), call
+}
+
+// finishAudit completes the audit record of call with the outcome of the
+// tool handler and writes it. A nil call is ignored.
+func (s *Server) finishAudit(ctx context.Context, call *auditCall, result *ToolsCallResult, err error) {
+	if call == nil {
+		return
+	}
+
+	record := call.record
+	record.Time = call.start.UTC()
+	record.DurationMS = time.Since(call.start).Milliseconds()
+	call.mu.Lock()
+	record.ContextID = call.contextID
+	call.mu.Unlock()
+
+	switch {
+	case errors.Is(context.Cause(ctx), errRequestCancelled):
+		record.Result = AuditResultCancelled
+	case err != nil:
+		record.Result = AuditResultError
+		record.Error = s.redact(err.Error())
+	case result != nil && result.IsError:
+		record.Result = AuditResultError
+		record.Error = s.redact(resultText(result))
+	default:
+		record.Result = AuditResultSuccess
+	}
+
+	if err := s.audit.Write(record); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to write audit record", logAttrs(ctx, "tool", record.Tool, "error", err)...)
+	}
+}
+
+// redactArguments returns a copy of tool arguments with the values of
+// sensitive keys and all configured secrets masked.
+func (s *Server) redactArguments(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+	redacted, _ := s.redactValue(args).(map[string]any)
+	return redacted
+}
+
+// redactValue returns a copy of an argument value with secrets masked.
+func (s *Server) redactValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return s.redact(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if isSensitiveKey(key) {
+				out[key] = logging.MaskToken(fmt.Sprintf("%v", item))
+				continue
+			}
+			out[key] = s.redactValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = s.redactValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
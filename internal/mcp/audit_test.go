@@ -0,0 +1,250 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+// readAuditRecords reads the records of an audit log file.
+func readAuditRecords(t *testing.T, path string) []AuditRecord {
+	t.Helper()
+
+	f, err := os.Open(path) //nolint:gosec // Test file
+	if err != nil {
+		t.Fatalf("opening audit log: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("unmarshaling audit record %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAuditLog_Rotate(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	record := AuditRecord{Tool: "call_service", Result: AuditResultSuccess}
+	line, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	// Room for two records per file
+	audit, err := OpenAuditLog(path, int64(2*(len(line)+1)), 2)
+	if err != nil {
+		t.Fatalf("OpenAuditLog() error = %v", err)
+	}
+	for range 7 {
+		if err := audit.Write(record); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := audit.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := map[string]int{path: 1, path + ".1": 2, path + ".2": 2}
+	for file, count := range want {
+		if got := len(readAuditRecords(t, file)); got != count {
+			t.Errorf("%s has %d records, want %d", filepath.Base(file), got, count)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Stat(audit.jsonl.3) error = %v, want not exist", err)
+	}
+
+	if err := audit.Write(record); err == nil {
+		t.Error("Write() after Close() error = nil, want error")
+	}
+}
+
+func TestServer_AuditLog(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, _ homeassistant.Client, args map[string]any) (*ToolsCallResult, error) {
+		RecordContextID(ctx, "01HCTX")
+		RecordContextID(ctx, "01HOTHER")
+		if args["fail"] == true {
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("failed with ha-secret-token")}, IsError: true}, nil
+		}
+		return &ToolsCallResult{Content: []ContentBlock{NewTextContent("ok")}}, nil
+	}
+
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "call_service"}, handler)
+	registry.RegisterTool(Tool{Name: "get_state", Annotations: &ToolAnnotations{ReadOnlyHint: true}}, handler)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := OpenAuditLog(path, 0, 0)
+	if err != nil {
+		t.Fatalf("OpenAuditLog() error = %v", err)
+	}
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+	s.SetRedactedValues("ha-secret-token")
+	s.SetAuditLog(audit)
+
+	sess := newSession("sess-1")
+	sess.setInitializeParams(&InitializeParams{ClientInfo: Implementation{Name: "claude-desktop", Version: "1"}})
+	ctx := withSession(context.Background(), sess)
+
+	for _, params := range []string{
+		`{"name":"call_service","arguments":{"domain":"light","data":{"entity_id":"light.kitchen","api_key":"abcdefghijkl","note":"ha-secret-token"}}}`,
+		`{"name":"get_state","arguments":{"entity_id":"light.kitchen"}}`,
+		`{"name":"call_service","arguments":{"fail":true}}`,
+	} {
+		resp := s.handleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`1`),
+			Method:  MethodToolsCall,
+			Params:  json.RawMessage(params),
+		})
+		if resp.Error != nil {
+			t.Fatalf("tools/call %s: Error = %+v", params, resp.Error)
+		}
+	}
+	if err := audit.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := []AuditRecord{
+		{
+			Session: "sess-1",
+			Client:  "claude-desktop",
+			Tool:    "call_service",
+			Arguments: map[string]any{
+				"domain": "light",
+				"data": map[string]any{
+					"entity_id": "light.kitchen",
+					"api_key":   logging.MaskToken("abcdefghijkl"),
+					"note":      logging.MaskToken("ha-secret-token"),
+				},
+			},
+			ContextID: "01HCTX",
+			Result:    AuditResultSuccess,
+		},
+		{
+			Session:   "sess-1",
+			Client:    "claude-desktop",
+			Tool:      "call_service",
+			Arguments: map[string]any{"fail": true},
+			ContextID: "01HCTX",
+			Result:    AuditResultError,
+			Error:     "failed with " + logging.MaskToken("ha-secret-token"),
+		},
+	}
+	got := readAuditRecords(t, path)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(AuditRecord{}, "Time", "DurationMS")); diff != "" {
+		t.Errorf("audit records mismatch (-want +got):\n%s", diff)
+	}
+	for _, record := range got {
+		if record.Time.IsZero() || strings.Contains(record.Error, "ha-secret-token") {
+			t.Errorf("record = %+v, want a timestamp and no secrets", record)
+		}
+	}
+}
+
+func TestRecordContextID_NotAudited(t *testing.T) {
+	t.Parallel()
+
+	// Must not panic without an audit record in the context
+	RecordContextID(context.Background(), "01HCTX")
+}
+
+func TestServer_AuditLogRecordsServiceContext(t *testing.T) {
+	t.Parallel()
+
+	// A Home Assistant that answers call_service with a context but no entities
+	ha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.CloseNow() }()
+
+		ctx := r.Context()
+		_ = conn.Write(ctx, websocket.MessageText, []byte(`{"type":"auth_required"}`))
+		if _, _, err := conn.Read(ctx); err != nil {
+			return
+		}
+		_ = conn.Write(ctx, websocket.MessageText, []byte(`{"type":"auth_ok"}`))
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID int `json:"id"`
+			}
+			_ = json.Unmarshal(data, &cmd)
+			reply := fmt.Sprintf(`{"id":%d,"type":"result","success":true,"result":{"context":{"id":"01HTURNON"}}}`, cmd.ID)
+			_ = conn.Write(ctx, websocket.MessageText, []byte(reply))
+		}
+	}))
+	t.Cleanup(ha.Close)
+
+	config := homeassistant.DefaultWSClientConfig()
+	config.AutoReconnect = false
+	config.PingInterval = 0
+	client, err := homeassistant.NewConnectedWSClient(context.Background(), ha.URL, "test-token", &config)
+	if err != nil {
+		t.Fatalf("NewConnectedWSClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = homeassistant.CloseClient(client) })
+
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "call_service"}, func(ctx context.Context, client homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+		if _, err := client.CallService(ctx, "light", "turn_on", map[string]any{"entity_id": "light.kitchen"}); err != nil {
+			return nil, err
+		}
+		return &ToolsCallResult{Content: []ContentBlock{NewTextContent("ok")}}, nil
+	})
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := OpenAuditLog(path, 0, 0)
+	if err != nil {
+		t.Fatalf("OpenAuditLog() error = %v", err)
+	}
+	s := NewServer(client, registry, 8080, logging.New(logging.LevelOff))
+	s.SetAuditLog(audit)
+
+	resp := s.handleRequest(context.Background(), &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodToolsCall,
+		Params:  json.RawMessage(`{"name":"call_service"}`),
+	})
+	if resp.Error != nil {
+		t.Fatalf("tools/call: Error = %+v", resp.Error)
+	}
+	if err := audit.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	records := readAuditRecords(t, path)
+	if len(records) != 1 || records[0].ContextID != "01HTURNON" {
+		t.Errorf("audit records = %+v, want one with context_id 01HTURNON", records)
+	}
+}
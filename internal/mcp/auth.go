@@ -0,0 +1,121 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// bearerPrefix is the authorization scheme prefix for bearer tokens.
+const bearerPrefix = "Bearer "
+
+// APIKey is a named bearer token accepted by the HTTP transport.
+type APIKey struct {
+	Name string
+	Key  string
+}
+
+// hashedKey is an API key stored as a SHA-256 digest, so that comparisons take
+// constant time regardless of the presented token's length.
+type hashedKey struct {
+	name   string
+	digest [sha256.Size]byte
+}
+
+// Authenticator validates bearer tokens against a set of named API keys.
+type Authenticator struct {
+	keys []hashedKey
+}
+
+// NewAuthenticator creates an authenticator accepting the given keys.
+func NewAuthenticator(keys []APIKey) *Authenticator {
+	a := &Authenticator{keys: make([]hashedKey, 0, len(keys))}
+	for _, k := range keys {
+		a.keys = append(a.keys, hashedKey{name: k.Name, digest: sha256.Sum256([]byte(k.Key))})
+	}
+	return a
+}
+
+// KeyCount returns the number of accepted keys.
+func (a *Authenticator) KeyCount() int {
+	return len(a.keys)
+}
+
+// authenticate checks the request's Authorization header and returns the name
+// of the matching key. All keys are compared so that timing does not reveal
+// which key, if any, matched.
+func (a *Authenticator) authenticate(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(bearerPrefix):])
+	if token == "" {
+		return "", false
+	}
+
+	digest := sha256.Sum256([]byte(token))
+	name := ""
+	matched := 0
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare(digest[:], k.digest[:]) == 1 {
+			name = k.name
+			matched = 1
+		}
+	}
+	return name, matched == 1
+}
+
+// SetAuthenticator enables bearer-token authentication on the HTTP transport.
+// The /health and /metrics endpoints stay unauthenticated unless protectHealth is set.
+// It must be called before Start.
+func (s *Server) SetAuthenticator(auth *Authenticator, protectHealth bool) {
+	s.auth = auth
+	s.protectHealth = protectHealth
+}
+
+// requireAuth wraps an HTTP handler with bearer-token authentication.
+// Without an authenticator the handler is returned unchanged.
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	if s.auth == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := s.auth.authenticate(r)
+		if !ok {
+			s.logger.Warn("Unauthorized request", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+ServerName+`"`)
+			s.writeErrorStatus(w, http.StatusUnauthorized, nil, InvalidRequest, "unauthorized")
+			return
+		}
+
+		next(w, r.WithContext(withAPIKeyName(r.Context(), name)))
+	}
+}
+
+// apiKeyContextKey is the context key for the name of the authenticated API key.
+type apiKeyContextKey struct{}
+
+// withAPIKeyName returns a context carrying the name of the authenticated API key.
+func withAPIKeyName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, name)
+}
+
+// APIKeyNameFromContext returns the name of the API key that authenticated the
+// current request, or an empty string if the request was not authenticated.
+func APIKeyNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(apiKeyContextKey{}).(string)
+	return name
+}
+
+// logAttrs appends the authenticated key name to log attributes, if any.
+func logAttrs(ctx context.Context, args ...any) []any {
+	if name := APIKeyNameFromContext(ctx); name != "" {
+		return append(args, "key", name)
+	}
+	return args
+}
@@ -0,0 +1,169 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	auth := NewAuthenticator([]APIKey{
+		{Name: "laptop", Key: "laptop-secret"},
+		{Name: "ci", Key: "ci-secret"},
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		wantName string
+		wantOK   bool
+	}{
+		{name: "first key", header: "Bearer laptop-secret", wantName: "laptop", wantOK: true},
+		{name: "second key", header: "Bearer ci-secret", wantName: "ci", wantOK: true},
+		{name: "case-insensitive scheme", header: "bearer ci-secret", wantName: "ci", wantOK: true},
+		{name: "unknown key", header: "Bearer nope"},
+		{name: "missing header", header: ""},
+		{name: "empty token", header: "Bearer "},
+		{name: "basic auth", header: "Basic bGFwdG9wLXNlY3JldA=="},
+		{name: "key prefix", header: "Bearer laptop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			name, ok := auth.authenticate(req)
+			if ok != tt.wantOK || name != tt.wantName {
+				t.Errorf("authenticate() = (%q, %v), want (%q, %v)", name, ok, tt.wantName, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestServer_RequireAuth(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logging.New(logging.LevelOff))
+	s.SetAuthenticator(NewAuthenticator([]APIKey{{Name: "laptop", Key: "secret"}}), false)
+
+	var gotKey string
+	handler := s.requireAuth(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = APIKeyNameFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("rejects missing token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("StatusCode = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+		if !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Bearer") {
+			t.Errorf("WWW-Authenticate = %q, want Bearer challenge", w.Header().Get("WWW-Authenticate"))
+		}
+	})
+
+	t.Run("passes key name to handler", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("StatusCode = %d, want %d", w.Code, http.StatusOK)
+		}
+		if gotKey != "laptop" {
+			t.Errorf("APIKeyNameFromContext() = %q, want %q", gotKey, "laptop")
+		}
+	})
+}
+
+func TestServer_RequireAuth_Disabled(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logging.New(logging.LevelOff))
+
+	handler := s.requireAuth(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("StatusCode = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestServer_SessionBoundToAPIKey(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logging.New(logging.LevelOff))
+	s.SetAuthenticator(NewAuthenticator([]APIKey{{Name: "laptop", Key: "secret"}, {Name: "phone", Key: "other"}}), false)
+	ts := httptest.NewServer(s.requireAuth(s.handleMCP))
+	t.Cleanup(ts.Close)
+
+	do := func(method, key, sessionID, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(context.Background(), method, ts.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest() error = %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if sessionID != "" {
+			req.Header.Set(SessionIDHeader, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	resp := do(http.MethodPost, "secret", "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	id := resp.Header.Get(SessionIDHeader)
+	if id == "" {
+		t.Fatal("initialize response has no session ID header")
+	}
+
+	ping := `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+	for _, method := range []string{http.MethodPost, http.MethodGet, http.MethodDelete} {
+		if resp := do(method, "other", id, ping); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s with another key StatusCode = %d, want %d", method, resp.StatusCode, http.StatusNotFound)
+		}
+	}
+	if _, ok := s.sessions.get(id); !ok {
+		t.Fatal("session ended by a DELETE with another key")
+	}
+	if resp := do(http.MethodPost, "secret", id, ping); resp.StatusCode != http.StatusOK {
+		t.Errorf("POST with the session's key StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestLogAttrs(t *testing.T) {
+	t.Parallel()
+
+	if got := logAttrs(context.Background(), "tool", "x"); len(got) != 2 {
+		t.Errorf("logAttrs() without key = %v, want 2 attributes", got)
+	}
+
+	ctx := withAPIKeyName(context.Background(), "laptop")
+	got := logAttrs(ctx, "tool", "x")
+	if len(got) != 4 || got[2] != "key" || got[3] != "laptop" {
+		t.Errorf("logAttrs() with key = %v, want key attribute appended", got)
+	}
+}
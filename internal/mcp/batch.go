@@ -0,0 +1,68 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// maxBatchConcurrency limits how many entries of a JSON-RPC batch are processed at once.
+const maxBatchConcurrency = 8
+
+// isBatch reports whether body is a JSON-RPC batch (a JSON array).
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// processBatch processes the entries of a JSON-RPC batch concurrently, at most
+// maxBatchConcurrency at a time, and returns their responses in request order.
+// Notifications are left out; nil is returned if the batch contained only notifications.
+func (s *Server) processBatch(ctx context.Context, body []byte, remoteAddr string) []*Response {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(body, &entries); err != nil {
+		s.logger.Error("Invalid JSON", "remote_addr", remoteAddr, "error", err)
+		return []*Response{NewErrorResponse(nil, ParseError, "invalid JSON", err.Error())}
+	}
+
+	if len(entries) == 0 {
+		s.logger.Warn("Empty batch", "remote_addr", remoteAddr)
+		return []*Response{NewErrorResponse(nil, InvalidRequest, "empty batch", nil)}
+	}
+
+	s.logger.Debug("Batch received", logAttrs(ctx, "entries", len(entries))...)
+
+	results := make([]*Response, len(entries))
+	sem := make(chan struct{}, maxBatchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, entry := range entries {
+		// Entries must be request objects; anything else is an invalid request
+		if trimmed := bytes.TrimSpace(entry); len(trimmed) == 0 || trimmed[0] != '{' {
+			results[i] = NewErrorResponse(nil, InvalidRequest, "batch entry is not an object", nil)
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, entry json.RawMessage) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = s.processSingle(ctx, entry, remoteAddr)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	responses := make([]*Response, 0, len(results))
+	for _, resp := range results {
+		if resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
@@ -0,0 +1,190 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+//
+//nolint:errcheck // Test file - defer body.Close() is acceptable
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+// postBatch sends body to the MCP endpoint and returns the recorded response.
+func postBatch(s *Server, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	s.handleMCP(w, req)
+	return w
+}
+
+func TestIsBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{body: `[{"jsonrpc":"2.0"}]`, want: true},
+		{body: " \n\t[]", want: true},
+		{body: `{"jsonrpc":"2.0"}`, want: false},
+		{body: ``, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isBatch([]byte(tt.body)); got != tt.want {
+			t.Errorf("isBatch(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestServer_Batch(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "echo"},
+		func(_ context.Context, _ homeassistant.Client, args map[string]any) (*ToolsCallResult, error) {
+			text, _ := args["text"].(string)
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent(text)}}, nil
+		})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	t.Run("responses in request order without notifications", func(t *testing.T) {
+		t.Parallel()
+
+		w := postBatch(s, `[
+			{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"a"}}},
+			{"jsonrpc":"2.0","method":"notifications/initialized"},
+			{"jsonrpc":"2.0","id":2,"method":"ping"},
+			{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"c"}}}
+		]`)
+
+		var responses []Response
+		if err := json.NewDecoder(w.Body).Decode(&responses); err != nil {
+			t.Fatalf("json.Decode() error = %v", err)
+		}
+		if len(responses) != 3 {
+			t.Fatalf("got %d responses, want 3", len(responses))
+		}
+		for i, want := range []string{"1", "2", "3"} {
+			if got := string(responses[i].ID); got != want {
+				t.Errorf("responses[%d].ID = %s, want %s", i, got, want)
+			}
+			if responses[i].Error != nil {
+				t.Errorf("responses[%d].Error = %+v", i, responses[i].Error)
+			}
+		}
+	})
+
+	t.Run("only notifications", func(t *testing.T) {
+		t.Parallel()
+
+		w := postBatch(s, `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`)
+		if w.Code != http.StatusAccepted {
+			t.Errorf("StatusCode = %d, want %d", w.Code, http.StatusAccepted)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Body = %q, want empty", w.Body.String())
+		}
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		t.Parallel()
+
+		w := postBatch(s, `[]`)
+
+		var responses []Response
+		if err := json.NewDecoder(w.Body).Decode(&responses); err != nil {
+			t.Fatalf("json.Decode() error = %v", err)
+		}
+		if len(responses) != 1 || responses[0].Error == nil || responses[0].Error.Code != InvalidRequest {
+			t.Errorf("responses = %+v, want single InvalidRequest error", responses)
+		}
+	})
+
+	t.Run("invalid entries", func(t *testing.T) {
+		t.Parallel()
+
+		w := postBatch(s, `[1, {"jsonrpc":"1.0","id":5,"method":"ping"}, {"jsonrpc":"2.0","id":6,"method":"ping"}]`)
+
+		var responses []Response
+		if err := json.NewDecoder(w.Body).Decode(&responses); err != nil {
+			t.Fatalf("json.Decode() error = %v", err)
+		}
+		if len(responses) != 3 {
+			t.Fatalf("got %d responses, want 3", len(responses))
+		}
+		if responses[0].Error == nil || responses[0].Error.Code != InvalidRequest {
+			t.Errorf("responses[0].Error = %+v, want InvalidRequest", responses[0].Error)
+		}
+		if responses[1].Error == nil || responses[1].Error.Code != InvalidRequest {
+			t.Errorf("responses[1].Error = %+v, want InvalidRequest", responses[1].Error)
+		}
+		if responses[2].Error != nil {
+			t.Errorf("responses[2].Error = %+v, want nil", responses[2].Error)
+		}
+	})
+
+	t.Run("malformed batch", func(t *testing.T) {
+		t.Parallel()
+
+		w := postBatch(s, `[{"jsonrpc":"2.0"`)
+
+		var responses []Response
+		if err := json.NewDecoder(w.Body).Decode(&responses); err != nil {
+			t.Fatalf("json.Decode() error = %v", err)
+		}
+		if len(responses) != 1 || responses[0].Error == nil || responses[0].Error.Code != ParseError {
+			t.Errorf("responses = %+v, want single ParseError", responses)
+		}
+	})
+}
+
+func TestServer_BatchConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "slow"},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("ok")}}, nil
+		})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	entries := make([]string, maxBatchConcurrency*3)
+	for i := range entries {
+		entries[i] = `{"jsonrpc":"2.0","id":` + strconv.Itoa(i) + `,"method":"tools/call","params":{"name":"slow"}}`
+	}
+	w := postBatch(s, "["+strings.Join(entries, ",")+"]")
+
+	var responses []Response
+	if err := json.NewDecoder(w.Body).Decode(&responses); err != nil {
+		t.Fatalf("json.Decode() error = %v", err)
+	}
+	if len(responses) != len(entries) {
+		t.Errorf("got %d responses, want %d", len(responses), len(entries))
+	}
+	if got := peak.Load(); got > maxBatchConcurrency {
+		t.Errorf("peak concurrency = %d, want <= %d", got, maxBatchConcurrency)
+	}
+	if got := peak.Load(); got < 2 {
+		t.Errorf("peak concurrency = %d, want entries to run concurrently", got)
+	}
+}
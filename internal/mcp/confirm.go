@@ -0,0 +1,248 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// confirmTokenArgument is the argument that carries a confirm token issued for
+// a ConfirmationRequired error. It is added to the input schema of the tools
+// whose calls the confirmation policy may select.
+const confirmTokenArgument = "confirm_token"
+
+// Confirmation timing.
+const (
+	// confirmationTimeout is how long the server waits for the user to
+	// answer an elicitation/create request.
+	confirmationTimeout = 5 * time.Minute
+	// confirmTokenTTL is how long a confirm token stays valid.
+	confirmTokenTTL = 5 * time.Minute
+)
+
+// confirmField is the property of the elicitation form the user must set to
+// confirm a call.
+const confirmField = "confirm"
+
+// ConfirmationPolicy decides which tool calls need the user's confirmation
+// before they run.
+type ConfirmationPolicy interface {
+	// ConfirmationMessage returns the question to ask the user before the
+	// tool is called with the given arguments, or "" if the call needs no
+	// confirmation.
+	ConfirmationMessage(tool Tool, args map[string]any) string
+	// ConfirmsTool reports whether some calls of the tool may need
+	// confirmation.
+	ConfirmsTool(tool Tool) bool
+}
+
+// ConfirmationRequiredError is the data of a ConfirmationRequired error
+// response. It does not carry the confirm token, which only the server log
+// shows, so that the model cannot confirm its own call.
+type ConfirmationRequiredError struct {
+	// Message is the question the user has to agree to.
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SetConfirmationPolicy makes the calls selected by policy wait for the user's
+// confirmation. Clients that support elicitation are asked to confirm with
+// elicitation/create; for other clients the server logs a single-use confirm
+// token that the user passes on once they agree, and the call fails with a
+// ConfirmationRequired error. It must be called before the server starts serving.
+func (s *Server) SetConfirmationPolicy(policy ConfirmationPolicy) {
+	s.confirm = policy
+	s.confirmTokens = &confirmTokenStore{tokens: make(map[string]confirmToken)}
+	s.registry.setConfirmationPolicy(policy)
+}
+
+// withConfirmTokenProperty returns a copy of schema with the confirm_token argument added.
+func withConfirmTokenProperty(schema JSONSchema) JSONSchema {
+	props := make(map[string]JSONSchema, len(schema.Properties)+1)
+	maps.Copy(props, schema.Properties)
+	props[confirmTokenArgument] = JSONSchema{
+		Type:        "string",
+		Description: "Confirm token from the server log, given by the user once they agreed to the call",
+	}
+	schema.Properties = props
+	return schema
+}
+
+// confirmToolCall removes the confirm_token argument from params and, if the
+// call needs the user's confirmation, obtains it. It returns true if the call
+// may run. Otherwise it returns the response to send, which is nil if the
+// client cancelled the call.
+func (s *Server) confirmToolCall(ctx context.Context, req *Request, params *ToolsCallParams) (*Response, bool) {
+	tool, ok := s.registry.GetTool(params.Name)
+	if !ok || isReadOnly(tool) {
+		return nil, true
+	}
+
+	token, hasToken := params.Arguments[confirmTokenArgument].(string)
+	if _, ok := params.Arguments[confirmTokenArgument]; ok {
+		params.Arguments = maps.Clone(params.Arguments)
+		delete(params.Arguments, confirmTokenArgument)
+	}
+
+	dryRun, _ := params.Arguments[dryRunArgument].(bool)
+	if s.confirm == nil || s.dryRun || dryRun {
+		return nil, true
+	}
+	message := s.confirm.ConfirmationMessage(tool, params.Arguments)
+	if message == "" {
+		return nil, true
+	}
+
+	sess := s.sessionFor(ctx)
+	apiKey := APIKeyNameFromContext(ctx)
+	key := confirmationKey(params.Name, params.Arguments)
+	if hasToken && s.confirmTokens.redeem(apiKey, key, token) {
+		s.logger.InfoContext(ctx, "Tool call confirmed by token", logAttrs(ctx, "tool", params.Name)...)
+		return nil, true
+	}
+
+	if sess.canElicit() {
+		if resp, ok, answered := s.askForConfirmation(ctx, req, params.Name, message); answered {
+			return resp, ok
+		}
+	}
+
+	return s.confirmationRequired(ctx, req, params.Name, apiKey, key, message), false
+}
+
+// askForConfirmation asks the user to confirm a call with elicitation/create.
+// It reports false as answered if the client could not ask, so that the
+// caller falls back to a confirm token. Otherwise it returns the result of
+// confirmToolCall.
+func (s *Server) askForConfirmation(ctx context.Context, req *Request, name, message string) (*Response, bool, bool) {
+	accepted, err := s.elicitConfirmation(ctx, message)
+	switch {
+	case errors.Is(context.Cause(ctx), errRequestCancelled):
+		return nil, false, true
+	case err != nil:
+		s.logger.WarnContext(ctx, "Failed to ask for confirmation", logAttrs(ctx, "tool", name, "error", err)...)
+		return nil, false, false
+	case accepted:
+		s.logger.InfoContext(ctx, "Tool call confirmed by user", logAttrs(ctx, "tool", name)...)
+		return nil, true, true
+	default:
+		s.logger.InfoContext(ctx, "Tool call declined by user", logAttrs(ctx, "tool", name)...)
+		return NewSuccessResponse(req.ID, &ToolsCallResult{
+			Content: []ContentBlock{NewTextContent(
+				fmt.Sprintf("The user did not confirm the call of %s, so nothing was changed.", name))},
+			IsError: true,
+		}), false, true
+	}
+}
+
+// elicitConfirmation asks the user to confirm a call with elicitation/create
+// and reports whether they did.
+func (s *Server) elicitConfirmation(ctx context.Context, message string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, confirmationTimeout)
+	defer cancel()
+
+	raw, err := requestContext(ctx, MethodElicitationCreate, ElicitRequestParams{
+		Message: message,
+		RequestedSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				confirmField: {Type: "boolean", Description: "Confirm the operation"},
+			},
+			Required: []string{confirmField},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var result ElicitResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return false, fmt.Errorf("invalid elicitation result: %w", err)
+	}
+	confirmed, _ := result.Content[confirmField].(bool)
+	return result.Action == ElicitActionAccept && confirmed, nil
+}
+
+// confirmationRequired issues a confirm token for a call, logs it for the
+// user without forwarding it to the client, and returns the
+// ConfirmationRequired error response.
+func (s *Server) confirmationRequired(ctx context.Context, req *Request, name, apiKey, key, message string) *Response {
+	token, expires, err := s.confirmTokens.issue(apiKey, key)
+	if err != nil {
+		return NewErrorResponse(req.ID, InternalError, err.Error(), nil)
+	}
+
+	s.logger.WarnContext(withoutLogForwarding(ctx), "Tool call needs confirmation",
+		logAttrs(ctx, "tool", name, "question", message, "confirm_token", token)...)
+
+	return NewErrorResponse(req.ID, ConfirmationRequired,
+		fmt.Sprintf("%s needs the user's confirmation: %s Ask the user. If they agree, they find a confirm token "+
+			"for this call in the ha-mcp server log; call %s again with the same arguments and the confirm_token "+
+			"they give you. Never make up a token. It expires in %s.",
+			name, message, name, confirmTokenTTL),
+		ConfirmationRequiredError{Message: message, ExpiresAt: expires})
+}
+
+// confirmationKey identifies a call by tool name and arguments, so that a
+// confirm token only confirms the call it was issued for.
+func confirmationKey(name string, args map[string]any) string {
+	data, _ := json.Marshal(args) // Map keys are sorted, so equal arguments give equal keys
+	return name + "\x00" + string(data)
+}
+
+// confirmToken is an issued confirm token. It is bound to the API key of
+// the client rather than to its session, so that clients without a session,
+// whose every request gets a session of its own, can redeem it too.
+type confirmToken struct {
+	apiKey  string
+	key     string
+	expires time.Time
+}
+
+// confirmTokenStore holds the confirm tokens that have not been used yet.
+type confirmTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]confirmToken
+}
+
+// issue creates a confirm token for a call by the client authenticated with
+// the named API key.
+func (st *confirmTokenStore) issue(apiKey, key string) (string, time.Time, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("generating confirm token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	now := time.Now()
+	expires := now.Add(confirmTokenTTL)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for t, issued := range st.tokens {
+		if now.After(issued.expires) {
+			delete(st.tokens, t)
+		}
+	}
+	st.tokens[token] = confirmToken{apiKey: apiKey, key: key, expires: expires}
+	return token, expires, nil
+}
+
+// redeem consumes a confirm token and reports whether it was issued for the
+// call by the client with the named API key and has not expired.
+func (st *confirmTokenStore) redeem(apiKey, key, token string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	issued, ok := st.tokens[token]
+	if !ok || issued.apiKey != apiKey || issued.key != key {
+		return false
+	}
+	delete(st.tokens, token)
+	return time.Now().Before(issued.expires)
+}
@@ -0,0 +1,329 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+// confirmNamed asks for confirmation of calls of the named tools.
+type confirmNamed []string
+
+func (c confirmNamed) ConfirmationMessage(tool Tool, _ map[string]any) string {
+	for _, name := range c {
+		if name == tool.Name {
+			return "Really " + name + "?"
+		}
+	}
+	return ""
+}
+
+func (c confirmNamed) ConfirmsTool(tool Tool) bool {
+	return slices.Contains(c, tool.Name)
+}
+
+// loggedConfirmToken matches a confirm token in the server log.
+var loggedConfirmToken = regexp.MustCompile(`confirm_token=([0-9a-f]+)`)
+
+// serverLog collects the log output of a server that handles requests concurrently.
+type serverLog struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *serverLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+// lastConfirmToken returns the confirm token logged last, or "".
+func (l *serverLog) lastConfirmToken() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tokens := loggedConfirmToken.FindAllStringSubmatch(l.buf.String(), -1)
+	if len(tokens) == 0 {
+		return ""
+	}
+	return tokens[len(tokens)-1][1]
+}
+
+// newConfirmServer returns a server with a delete_automation tool that needs
+// confirmation and counts its calls. The server logs to logOut.
+func newConfirmServer(executed *atomic.Int32, logOut io.Writer) *Server {
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "delete_automation"},
+		func(_ context.Context, _ homeassistant.Client, args map[string]any) (*ToolsCallResult, error) {
+			executed.Add(1)
+			if _, ok := args[confirmTokenArgument]; ok {
+				return nil, errors.New("handler received the confirm_token argument")
+			}
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("deleted")}}, nil
+		})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.NewWithWriter(logging.LevelInfo, logOut))
+	s.SetConfirmationPolicy(confirmNamed{"delete_automation"})
+	return s
+}
+
+func TestServer_ConfirmToken(t *testing.T) {
+	t.Parallel()
+
+	var executed atomic.Int32
+	var log serverLog
+	s := newConfirmServer(&executed, &log)
+
+	sess := s.sessions.defaultSession()
+	sink := &recordingSink{}
+	sess.attachSink(sink)
+	ctx := withSession(context.Background(), sess)
+	setLevel(t, s, ctx, "debug")
+
+	call := func(args string) *Response {
+		t.Helper()
+		return s.handleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`1`),
+			Method:  MethodToolsCall,
+			Params:  json.RawMessage(`{"name":"delete_automation","arguments":` + args + `}`),
+		})
+	}
+	tokenOf := func(resp *Response) string {
+		t.Helper()
+		if resp.Error == nil || resp.Error.Code != ConfirmationRequired {
+			t.Fatalf("response = %+v, want a ConfirmationRequired error", resp)
+		}
+		data, ok := resp.Error.Data.(ConfirmationRequiredError)
+		if !ok || data.Message != "Really delete_automation?" {
+			t.Fatalf("error data = %+v, want the confirmation question", resp.Error.Data)
+		}
+
+		// The token is only in the server log, out of the model's sight
+		token := log.lastConfirmToken()
+		if token == "" {
+			t.Fatal("server log has no confirm token")
+		}
+		sent, _ := json.Marshal(resp)
+		if strings.Contains(string(sent), token) {
+			t.Errorf("confirm token %s was sent to the client: %s", token, sent)
+		}
+		for _, msg := range logMessages(t, sink) {
+			if data, _ := msg.Data.(map[string]any); data["confirm_token"] != nil {
+				t.Errorf("log message %v with the confirm token was forwarded to the client", data)
+			}
+		}
+		return token
+	}
+
+	token := tokenOf(call(`{"automation_id":"night"}`))
+
+	// The token is bound to the arguments it was issued for
+	tokenOf(call(`{"automation_id":"morning","confirm_token":"` + token + `"}`))
+	if executed.Load() != 0 {
+		t.Fatal("call ran without confirmation")
+	}
+
+	token = tokenOf(call(`{"automation_id":"night"}`))
+	resp := call(`{"automation_id":"night","confirm_token":"` + token + `"}`)
+	if resp.Error != nil {
+		t.Fatalf("confirmed call: Error = %+v", resp.Error)
+	}
+	if text := resp.Result.(*ToolsCallResult).Content[0].Text; text != "deleted" {
+		t.Errorf("confirmed call result = %q, want deleted", text)
+	}
+
+	// Tokens are single-use
+	tokenOf(call(`{"automation_id":"night","confirm_token":"` + token + `"}`))
+
+	// Dry runs change nothing, so they need no confirmation
+	if resp := call(`{"automation_id":"night","dry_run":true}`); resp.Error != nil {
+		t.Errorf("dry run: Error = %+v", resp.Error)
+	}
+	if got := executed.Load(); got != 2 {
+		t.Errorf("handler ran %d times, want 2", got)
+	}
+}
+
+func TestServer_ConfirmElicitation(t *testing.T) {
+	t.Parallel()
+
+	var executed atomic.Int32
+	s := newConfirmServer(&executed, io.Discard)
+
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	defer outReader.Close()
+	defer inWriter.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.ServeStdio(ctx, inReader, outWriter) }()
+
+	out := bufio.NewReader(outReader)
+	write := func(line string) {
+		t.Helper()
+		if _, err := inWriter.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("writing %s: %v", line, err)
+		}
+	}
+	read := func() map[string]any {
+		t.Helper()
+		line, err := out.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading message: %v", err)
+		}
+		var msg map[string]any
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("unmarshaling %q: %v", line, err)
+		}
+		return msg
+	}
+
+	write(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18",` +
+		`"capabilities":{"elicitation":{}},"clientInfo":{"name":"test","version":"1"}}}`)
+	read()
+
+	tests := []struct {
+		name   string
+		answer string
+		want   string
+	}{
+		{name: "accept", answer: `{"action":"accept","content":{"confirm":true}}`, want: "deleted"},
+		{name: "accept unchecked", answer: `{"action":"accept","content":{"confirm":false}}`, want: "not confirm"},
+		{name: "decline", answer: `{"action":"decline"}`, want: "not confirm"},
+	}
+	for _, tt := range tests {
+		write(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"delete_automation","arguments":{"automation_id":"night"}}}`)
+
+		req := read()
+		if req["method"] != MethodElicitationCreate {
+			t.Fatalf("%s: message = %v, want an elicitation/create request", tt.name, req)
+		}
+		if diff := cmp.Diff("Really delete_automation?", req["params"].(map[string]any)["message"]); diff != "" {
+			t.Errorf("%s: elicitation message mismatch (-want +got):\n%s", tt.name, diff)
+		}
+		id, _ := json.Marshal(req["id"])
+		write(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":` + tt.answer + `}`)
+
+		resp := read()
+		result, _ := resp["result"].(map[string]any)
+		content, _ := result["content"].([]any)
+		if len(content) != 1 || !strings.Contains(content[0].(map[string]any)["text"].(string), tt.want) {
+			t.Errorf("%s: response = %v, want a result containing %q", tt.name, resp, tt.want)
+		}
+	}
+	if got := executed.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+}
+
+func TestServer_ConfirmTokenArgument(t *testing.T) {
+	t.Parallel()
+
+	handler := func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+		return &ToolsCallResult{}, nil
+	}
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "delete_automation"}, handler)
+	registry.RegisterTool(Tool{Name: "create_automation"}, handler)
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+	s.SetConfirmationPolicy(confirmNamed{"delete_automation", "delete_script", "get_script"})
+	registry.RegisterTool(Tool{Name: "delete_script"}, handler)
+	registry.RegisterTool(Tool{Name: "get_script", Annotations: &ToolAnnotations{ReadOnlyHint: true}}, handler)
+
+	// Only tools the policy may select, registered before or after it, take a confirm token
+	want := map[string]bool{
+		"delete_automation": true,
+		"create_automation": false,
+		"delete_script":     true,
+		"get_script":        false,
+	}
+	for name, wantToken := range want {
+		tool, _ := registry.GetTool(name)
+		if _, got := tool.InputSchema.Properties[confirmTokenArgument]; got != wantToken {
+			t.Errorf("%s has confirm_token argument = %v, want %v", name, got, wantToken)
+		}
+	}
+}
+
+func TestServer_ConfirmTokenWithoutSession(t *testing.T) {
+	t.Parallel()
+
+	var executed atomic.Int32
+	var log serverLog
+	s := newConfirmServer(&executed, &log)
+	ts := httptest.NewServer(http.HandlerFunc(s.handleMCP))
+	t.Cleanup(ts.Close)
+
+	// Every POST without Mcp-Session-Id gets a session of its own
+	call := func(args string) *Response {
+		t.Helper()
+		resp := postMessage(t, ts.URL, "", "",
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"delete_automation","arguments":`+args+`}}`)
+		defer resp.Body.Close()
+		var rpcResp Response
+		if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		return &rpcResp
+	}
+
+	if resp := call(`{"automation_id":"night"}`); resp.Error == nil || resp.Error.Code != ConfirmationRequired {
+		t.Fatalf("response = %+v, want a ConfirmationRequired error", resp)
+	}
+	token := log.lastConfirmToken()
+	if resp := call(`{"automation_id":"night","confirm_token":"` + token + `"}`); resp.Error != nil {
+		t.Fatalf("confirmed call: Error = %+v", resp.Error)
+	}
+	if got := executed.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+}
+
+func TestServer_ConfirmTokenBoundToAPIKey(t *testing.T) {
+	t.Parallel()
+
+	var executed atomic.Int32
+	var log serverLog
+	s := newConfirmServer(&executed, &log)
+
+	call := func(key, args string) *Response {
+		t.Helper()
+		ctx := withSession(withAPIKeyName(context.Background(), key), newSession(""))
+		return s.handleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`1`),
+			Method:  MethodToolsCall,
+			Params:  json.RawMessage(`{"name":"delete_automation","arguments":` + args + `}`),
+		})
+	}
+
+	call("laptop", `{"automation_id":"night"}`)
+	token := log.lastConfirmToken()
+
+	// Another client cannot redeem the token, and the failed attempt does not use it up
+	if resp := call("phone", `{"automation_id":"night","confirm_token":"`+token+`"}`); resp.Error == nil {
+		t.Error("token redeemed with another API key")
+	}
+	if resp := call("laptop", `{"automation_id":"night","confirm_token":"`+token+`"}`); resp.Error != nil {
+		t.Errorf("token redeemed with its API key: Error = %+v", resp.Error)
+	}
+	if got := executed.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+}
@@ -0,0 +1,100 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+)
+
+// dryRunArgument is the argument that makes a single tool call a dry run.
+// It is added to the input schema of every tool not annotated as read-only.
+const dryRunArgument = "dry_run"
+
+// DryRunResult is the result of a dry run.
+type DryRunResult struct {
+	DryRun bool   `json:"dry_run"`
+	Tool   string `json:"tool"`
+	// Requests are the requests the call would have sent, in order.
+	Requests []homeassistant.DryRunRequest `json:"requests"`
+}
+
+// SetDryRun makes every call of a tool not annotated as read-only a dry run,
+// whatever its dry_run argument. It must be called before the server starts serving.
+func (s *Server) SetDryRun(enabled bool) {
+	s.dryRun = enabled
+}
+
+// isReadOnly reports whether a tool is annotated as not changing Home Assistant state.
+func isReadOnly(tool Tool) bool {
+	return tool.Annotations != nil && tool.Annotations.ReadOnlyHint
+}
+
+// withDryRunProperty returns a copy of schema with the dry_run argument added.
+func withDryRunProperty(schema JSONSchema) JSONSchema {
+	props := make(map[string]JSONSchema, len(schema.Properties)+1)
+	maps.Copy(props, schema.Properties)
+	props[dryRunArgument] = JSONSchema{
+		Type:        "boolean",
+		Description: "Validate the call and return the requests it would send to Home Assistant without sending them",
+	}
+	schema.Properties = props
+	return schema
+}
+
+// runTool runs a tool handler. Calls of tools that may change Home Assistant
+// state run as a dry run if requested, or are recorded in the audit log.
+func (s *Server) runTool(ctx context.Context, handler ToolHandler, params *ToolsCallParams) (*ToolsCallResult, error) {
+	args := params.Arguments
+	if tool, ok := s.registry.GetTool(params.Name); ok && !isReadOnly(tool) {
+		requested, _ := args[dryRunArgument].(bool)
+		if _, ok := args[dryRunArgument]; ok {
+			args = maps.Clone(args)
+			delete(args, dryRunArgument)
+		}
+		if s.dryRun || requested {
+			return s.runDryRun(ctx, handler, params.Name, args)
+		}
+	}
+
+	ctx, audit := s.startAudit(ctx, params.Name, args)
+	result, err := handler(ctx, s.haClient, args)
+	s.finishAudit(ctx, audit, result, err)
+	return result, err
+}
+
+// runDryRun runs a tool handler with the requests that would change Home
+// Assistant recorded instead of sent, and returns them as the result. If the
+// handler fails before recording a request, e.g. on invalid input, its
+// result is returned unchanged.
+func (s *Server) runDryRun(ctx context.Context, handler ToolHandler, name string, args map[string]any) (*ToolsCallResult, error) {
+	ctx, dryRun := homeassistant.WithDryRun(ctx)
+	result, err := handler(ctx, s.haClient, args)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := dryRun.Requests()
+	s.logger.InfoContext(ctx, "Tool call dry run", logAttrs(ctx, "tool", name, "requests", len(requests))...)
+	if len(requests) == 0 {
+		if result != nil && result.IsError {
+			return result, nil
+		}
+		return &ToolsCallResult{
+			Content: []ContentBlock{NewTextContent("Dry run: the call would send no requests to Home Assistant.")},
+		}, nil
+	}
+
+	output, err := json.MarshalIndent(DryRunResult{DryRun: true, Tool: name, Requests: requests}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling dry run: %w", err)
+	}
+	return &ToolsCallResult{
+		Content: []ContentBlock{NewTextContent(
+			fmt.Sprintf("Dry run: nothing was sent to Home Assistant. The call would send %d request(s):\n\n%s", len(requests), output),
+		)},
+	}, nil
+}
@@ -0,0 +1,131 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+func TestRegistry_DryRunProperty(t *testing.T) {
+	t.Parallel()
+
+	handler := func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+		return &ToolsCallResult{}, nil
+	}
+	props := map[string]JSONSchema{"automation_id": {Type: "string"}}
+
+	r := NewRegistry()
+	r.RegisterTool(Tool{Name: "delete_automation", InputSchema: JSONSchema{Type: "object", Properties: props}}, handler)
+	r.RegisterTool(Tool{
+		Name:        "get_state",
+		InputSchema: JSONSchema{Type: "object", Properties: props},
+		Annotations: &ToolAnnotations{ReadOnlyHint: true},
+	}, handler)
+
+	tool, _ := r.GetTool("delete_automation")
+	if _, ok := tool.InputSchema.Properties[dryRunArgument]; !ok {
+		t.Error("delete_automation has no dry_run argument")
+	}
+	tool, _ = r.GetTool("get_state")
+	if _, ok := tool.InputSchema.Properties[dryRunArgument]; ok {
+		t.Error("read-only get_state has a dry_run argument")
+	}
+	if len(props) != 1 {
+		t.Errorf("registering changed the caller's schema: %v", props)
+	}
+}
+
+func TestServer_DryRun(t *testing.T) {
+	t.Parallel()
+
+	// The REST client records its request in a dry run, so the URL is never contacted
+	rest := homeassistant.NewRESTClient("http://ha.invalid:8123", "token")
+
+	var executed atomic.Int32
+	var gotArgs atomic.Value
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "delete_automation"},
+		func(ctx context.Context, _ homeassistant.Client, args map[string]any) (*ToolsCallResult, error) {
+			gotArgs.Store(args)
+			id, _ := args["automation_id"].(string)
+			if id == "" {
+				return &ToolsCallResult{Content: []ContentBlock{NewTextContent("automation_id is required")}, IsError: true}, nil
+			}
+			if err := rest.DeleteAutomation(ctx, id); err != nil {
+				return &ToolsCallResult{Content: []ContentBlock{NewTextContent(err.Error())}, IsError: true}, nil
+			}
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("deleted")}}, nil
+		})
+	registry.RegisterTool(Tool{Name: "toggle"},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			executed.Add(1)
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("toggled")}}, nil
+		})
+
+	call := func(s *Server, params string) *ToolsCallResult {
+		t.Helper()
+		resp := s.handleRequest(context.Background(), &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`1`),
+			Method:  MethodToolsCall,
+			Params:  json.RawMessage(params),
+		})
+		if resp.Error != nil {
+			t.Fatalf("tools/call %s: Error = %+v", params, resp.Error)
+		}
+		return resp.Result.(*ToolsCallResult)
+	}
+
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	result := call(s, `{"name":"delete_automation","arguments":{"automation_id":"night","dry_run":true}}`)
+	text := result.Content[0].Text
+	_, output, found := strings.Cut(text, "\n\n")
+	if !found || result.IsError {
+		t.Fatalf("dry run result = %q, want requests", text)
+	}
+	var got DryRunResult
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("unmarshaling dry run result: %v", err)
+	}
+	want := DryRunResult{DryRun: true, Tool: "delete_automation", Requests: []homeassistant.DryRunRequest{{
+		Transport: homeassistant.DryRunREST,
+		Method:    http.MethodDelete,
+		URL:       "http://ha.invalid:8123/api/config/automation/config/night",
+	}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("dry run result mismatch (-want +got):\n%s", diff)
+	}
+	if _, ok := gotArgs.Load().(map[string]any)[dryRunArgument]; ok {
+		t.Error("handler received the dry_run argument")
+	}
+
+	// Invalid input is reported as usual
+	result = call(s, `{"name":"delete_automation","arguments":{"dry_run":true}}`)
+	if !result.IsError || result.Content[0].Text != "automation_id is required" {
+		t.Errorf("dry run with invalid input = %+v, want the handler's error", result)
+	}
+
+	// dry_run: false runs the call
+	if result := call(s, `{"name":"toggle","arguments":{"dry_run":false}}`); result.Content[0].Text != "toggled" {
+		t.Errorf("toggle result = %q, want toggled", result.Content[0].Text)
+	}
+
+	// The global switch overrides the argument
+	s.SetDryRun(true)
+	result = call(s, `{"name":"toggle","arguments":{"dry_run":false}}`)
+	if !strings.HasPrefix(result.Content[0].Text, "Dry run:") {
+		t.Errorf("toggle result with global dry run = %q, want a dry run", result.Content[0].Text)
+	}
+	if got := executed.Load(); got != 2 {
+		t.Errorf("toggle handler ran %d times, want 2", got)
+	}
+}
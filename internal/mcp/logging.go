@@ -0,0 +1,190 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+// logForwarderName is the logger name reported in notifications/message.
+const logForwarderName = ServerName
+
+// MCP log levels (RFC 5424 severities) mapped to slog levels.
+var mcpLogLevels = map[string]slog.Level{
+	"debug":     logging.LevelDebug,
+	"info":      logging.LevelInfo,
+	"notice":    logging.LevelInfo + 2,
+	"warning":   logging.LevelWarn,
+	"error":     logging.LevelError,
+	"critical":  logging.LevelError + 4,
+	"alert":     logging.LevelError + 8,
+	"emergency": logging.LevelError + 12,
+}
+
+// sensitiveAttrKeys are substrings of attribute keys whose values are always masked.
+var sensitiveAttrKeys = []string{"token", "password", "secret", "authorization", "api_key"}
+
+// mcpLogLevel returns the MCP log level name for a slog level.
+func mcpLogLevel(level slog.Level) string {
+	switch {
+	case level < logging.LevelInfo:
+		return "debug" // Includes TRACE
+	case level < mcpLogLevels["notice"]:
+		return "info"
+	case level < logging.LevelWarn:
+		return "notice"
+	case level < logging.LevelError:
+		return "warning"
+	case level < mcpLogLevels["critical"]:
+		return "error"
+	case level < mcpLogLevels["alert"]:
+		return "critical"
+	case level < mcpLogLevels["emergency"]:
+		return "alert"
+	default:
+		return "emergency"
+	}
+}
+
+// SetRedactedValues sets secrets (such as the Home Assistant token) that are
+// masked wherever they appear in log records forwarded to clients.
+// It must be called before the server starts serving.
+func (s *Server) SetRedactedValues(values ...string) {
+	s.redacted = s.redacted[:0]
+	for _, v := range values {
+		if v != "" {
+			s.redacted = append(s.redacted, v)
+		}
+	}
+}
+
+// handleSetLevel handles logging/setLevel. The level applies only to the log
+// records forwarded to the requesting client; the server's own log level is
+// left to the operator.
+func (s *Server) handleSetLevel(ctx context.Context, req *Request) *Response {
+	var params LoggingSetLevelParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return NewErrorResponse(req.ID, InvalidParams, "invalid logging/setLevel params", err.Error())
+	}
+
+	level, ok := mcpLogLevels[strings.ToLower(params.Level)]
+	if !ok {
+		return NewErrorResponse(req.ID, InvalidParams, fmt.Sprintf("unknown log level: %s", params.Level), nil)
+	}
+
+	s.logger.InfoContext(ctx, "Log level changed by client", logAttrs(ctx,
+		"session_id", s.sessionFor(ctx).ID(),
+		"level", params.Level)...)
+
+	sess := s.sessionFor(ctx)
+	sess.setLogLevel(level)
+	s.logger.SetHookLevel(s.lowestClientLogLevel(sess))
+
+	return NewSuccessResponse(req.ID, struct{}{})
+}
+
+// lowestClientLogLevel returns the lowest log level asked for by sess or any
+// stored session, so that records below the server's log level still reach
+// the clients that want them.
+func (s *Server) lowestClientLogLevel(sess *Session) slog.Level {
+	lowest, _ := sess.clientLogLevel()
+	for _, other := range s.sessions.all() {
+		if level, ok := other.clientLogLevel(); ok && level < lowest {
+			lowest = level
+		}
+	}
+	return lowest
+}
+
+// noForwardContextKey is the context key that marks records for the server log only.
+type noForwardContextKey struct{}
+
+// withoutLogForwarding returns a context whose log records are written to the
+// server log but not forwarded to clients.
+func withoutLogForwarding(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noForwardContextKey{}, true)
+}
+
+// forwardLogRecord sends a log record to clients as notifications/message.
+// Records logged while handling a request go only to the requesting client;
+// other records go to every client that enabled logging.
+func (s *Server) forwardLogRecord(ctx context.Context, r slog.Record) {
+	if ctx.Value(noForwardContextKey{}) != nil {
+		return
+	}
+	if sess, ok := SessionFromContext(ctx); ok {
+		if sess.wantsLog(r.Level) {
+			_ = notifyContext(ctx, MethodLogMessage, s.logMessageParams(r))
+		}
+		return
+	}
+
+	var params *LoggingMessageParams
+	for _, sess := range s.sessions.all() {
+		if !sess.wantsLog(r.Level) {
+			continue
+		}
+		if params == nil {
+			params = s.logMessageParams(r)
+		}
+		_ = sess.Notify(MethodLogMessage, params)
+	}
+}
+
+// logMessageParams converts a log record to notification parameters with secrets masked.
+func (s *Server) logMessageParams(r slog.Record) *LoggingMessageParams {
+	data := map[string]any{"message": s.redact(r.Message)}
+
+	r.Attrs(func(a slog.Attr) bool {
+		data[a.Key] = s.attrValue(a)
+		return true
+	})
+
+	return &LoggingMessageParams{
+		Level:  mcpLogLevel(r.Level),
+		Logger: logForwarderName,
+		Data:   data,
+	}
+}
+
+// attrValue returns a JSON-friendly, redacted value for a log attribute.
+func (s *Server) attrValue(a slog.Attr) any {
+	value := a.Value.Resolve()
+
+	if isSensitiveKey(a.Key) {
+		return logging.MaskToken(value.String())
+	}
+
+	switch value.Kind() {
+	case slog.KindBool, slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+		return value.Any()
+	default:
+		return s.redact(fmt.Sprintf("%v", value.Any()))
+	}
+}
+
+// isSensitiveKey reports whether values stored under key must always be masked.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveAttrKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact masks every configured secret contained in text.
+func (s *Server) redact(text string) string {
+	for _, secret := range s.redacted {
+		if strings.Contains(text, secret) {
+			text = strings.ReplaceAll(text, secret, logging.MaskToken(secret))
+		}
+	}
+	return text
+}
@@ -0,0 +1,284 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+// newLoggingTestServer creates a server whose logger discards its output.
+func newLoggingTestServer(level string) (*Server, *logging.Logger) {
+	lvl, _ := logging.ParseLevel(level)
+	logger := logging.NewWithWriter(lvl, io.Discard)
+	return NewServer(&mockHAClient{}, NewRegistry(), 8080, logger), logger
+}
+
+// setLevel sends logging/setLevel for the session in ctx.
+func setLevel(t *testing.T, s *Server, ctx context.Context, level string) *Response {
+	t.Helper()
+
+	params, _ := json.Marshal(LoggingSetLevelParams{Level: level})
+	return s.handleRequest(ctx, &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodLoggingSetLvl,
+		Params:  params,
+	})
+}
+
+// logMessages returns the notifications/message params recorded by sink.
+func logMessages(t *testing.T, sink *recordingSink) []LoggingMessageParams {
+	t.Helper()
+
+	var msgs []LoggingMessageParams
+	for _, msg := range sink.messages {
+		notif, ok := msg.(*Notification)
+		if !ok || notif.Method != MethodLogMessage {
+			continue
+		}
+		params, ok := notif.Params.(*LoggingMessageParams)
+		if !ok {
+			t.Fatalf("params type = %T, want *LoggingMessageParams", notif.Params)
+		}
+		msgs = append(msgs, *params)
+	}
+	return msgs
+}
+
+func TestMCPLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "debug", want: "debug"},
+		{name: "info", want: "info"},
+		{name: "notice", want: "notice"},
+		{name: "warning", want: "warning"},
+		{name: "error", want: "error"},
+		{name: "critical", want: "critical"},
+		{name: "alert", want: "alert"},
+		{name: "emergency", want: "emergency"},
+	}
+
+	for _, tt := range tests {
+		if got := mcpLogLevel(mcpLogLevels[tt.name]); got != tt.want {
+			t.Errorf("mcpLogLevel(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := mcpLogLevel(logging.LevelTrace); got != "debug" {
+		t.Errorf("mcpLogLevel(TRACE) = %q, want %q", got, "debug")
+	}
+}
+
+func TestServer_HandleSetLevel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid level", func(t *testing.T) {
+		t.Parallel()
+
+		s, logger := newLoggingTestServer("info")
+		sess := s.sessions.defaultSession()
+		ctx := withSession(context.Background(), sess)
+
+		resp := setLevel(t, s, ctx, "debug")
+		if resp.Error != nil {
+			t.Fatalf("logging/setLevel error = %+v", resp.Error)
+		}
+		if logger.Level() != logging.LevelInfo {
+			t.Errorf("logger level = %v, want %v", logger.Level(), logging.LevelInfo)
+		}
+		if !logger.IsDebugEnabled() {
+			t.Error("debug records are not passed to the hook after setLevel(debug)")
+		}
+		if !sess.wantsLog(logging.LevelDebug) {
+			t.Error("session does not want debug records after setLevel(debug)")
+		}
+	})
+
+	t.Run("unknown level", func(t *testing.T) {
+		t.Parallel()
+
+		s, _ := newLoggingTestServer("info")
+		resp := setLevel(t, s, context.Background(), "verbose")
+		if resp.Error == nil || resp.Error.Code != InvalidParams {
+			t.Errorf("logging/setLevel error = %+v, want InvalidParams", resp.Error)
+		}
+	})
+}
+
+func TestServer_InitializeAdvertisesLogging(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newLoggingTestServer("info")
+	resp := s.handleRequest(context.Background(), &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodInitialize,
+	})
+
+	result, ok := resp.Result.(InitializeResult)
+	if !ok {
+		t.Fatalf("Result type = %T, want InitializeResult", resp.Result)
+	}
+	if result.Capabilities.Logging == nil {
+		t.Error("Capabilities.Logging = nil, want logging capability")
+	}
+}
+
+func TestServer_ForwardLogRecord(t *testing.T) {
+	t.Parallel()
+
+	s, logger := newLoggingTestServer("info")
+	s.SetRedactedValues("super-secret-ha-token", "")
+
+	sess := s.sessions.defaultSession()
+	sink := &recordingSink{}
+	sess.attachSink(sink)
+	setLevel(t, s, withSession(context.Background(), sess), "warning")
+
+	logger.Info("below the client's level")
+	logger.Warn("WS command failed for super-secret-ha-token",
+		"access_token", "abcdefghijklmnop",
+		"attempts", 3,
+		"detail", "sent super-secret-ha-token")
+
+	msgs := logMessages(t, sink)
+	if len(msgs) != 1 {
+		t.Fatalf("got %d log messages, want 1: %+v", len(msgs), msgs)
+	}
+
+	msg := msgs[0]
+	if msg.Level != "warning" || msg.Logger != ServerName {
+		t.Errorf("Level, Logger = %q, %q, want %q, %q", msg.Level, msg.Logger, "warning", ServerName)
+	}
+
+	data, _ := msg.Data.(map[string]any)
+	wantData := map[string]any{
+		"message":      "WS command failed for supe****oken",
+		"access_token": "abcd****mnop",
+		"attempts":     int64(3),
+		"detail":       "sent supe****oken",
+	}
+	for key, want := range wantData {
+		if data[key] != want {
+			t.Errorf("data[%q] = %v (%T), want %v (%T)", key, data[key], data[key], want, want)
+		}
+	}
+}
+
+func TestServer_ForwardLogRecord_RequestScoped(t *testing.T) {
+	t.Parallel()
+
+	s, logger := newLoggingTestServer("info")
+
+	first, _ := s.sessions.create("")
+	second, _ := s.sessions.create("")
+	firstSink, secondSink := &recordingSink{}, &recordingSink{}
+	first.attachSink(firstSink)
+	second.attachSink(secondSink)
+	setLevel(t, s, withSession(context.Background(), first), "info")
+	setLevel(t, s, withSession(context.Background(), second), "info")
+	firstSink.messages, secondSink.messages = nil, nil
+
+	// Records logged while handling a request only go to that request's client
+	logger.InfoContext(withSession(context.Background(), first), "tool call for first")
+	// Records without a request go to every client that enabled logging
+	logger.Info("server-wide event")
+
+	if got := len(logMessages(t, firstSink)); got != 2 {
+		t.Errorf("first session got %d messages, want 2", got)
+	}
+	if got := len(logMessages(t, secondSink)); got != 1 {
+		t.Errorf("second session got %d messages, want 1", got)
+	}
+}
+
+func TestServer_SetLevelPerSession(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	logger := logging.NewWithWriter(logging.LevelInfo, &out)
+	s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logger)
+
+	quiet, _ := s.sessions.create("")
+	verbose, _ := s.sessions.create("")
+	quietSink, verboseSink := &recordingSink{}, &recordingSink{}
+	quiet.attachSink(quietSink)
+	verbose.attachSink(verboseSink)
+	setLevel(t, s, withSession(context.Background(), verbose), "debug")
+	// The last setLevel must neither lower the server log nor silence the verbose client
+	setLevel(t, s, withSession(context.Background(), quiet), "error")
+	quietSink.messages, verboseSink.messages = nil, nil
+	out.Reset()
+
+	logger.Debug("debug event")
+	logger.Info("info event")
+
+	var got []string
+	for _, msg := range logMessages(t, verboseSink) {
+		data, _ := msg.Data.(map[string]any)
+		got = append(got, fmt.Sprintf("%s %v", msg.Level, data["message"]))
+	}
+	want := []string{"debug debug event", "info info event"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("verbose session messages mismatch (-want +got):\n%s", diff)
+	}
+	if got := len(logMessages(t, quietSink)); got != 0 {
+		t.Errorf("quiet session got %d messages, want 0", got)
+	}
+
+	written := out.String()
+	if !strings.Contains(written, "INFO info event") {
+		t.Errorf("server log is missing the info record: %s", written)
+	}
+	if strings.Contains(written, "debug event") {
+		t.Errorf("server log contains the debug record: %s", written)
+	}
+}
+
+func TestServer_ToolErrorIsForwarded(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newLoggingTestServer("info")
+	s.registry.RegisterTool(Tool{Name: "failing"},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			return &ToolsCallResult{
+				Content: []ContentBlock{NewTextContent("Error calling service: command failed: not_found - Service not found")},
+				IsError: true,
+			}, nil
+		})
+
+	sess := s.sessions.defaultSession()
+	sink := &recordingSink{}
+	sess.attachSink(sink)
+	ctx := withSession(context.Background(), sess)
+	setLevel(t, s, ctx, "warning")
+
+	s.handleRequest(ctx, &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`2`),
+		Method:  MethodToolsCall,
+		Params:  json.RawMessage(`{"name":"failing"}`),
+	})
+
+	msgs := logMessages(t, sink)
+	if len(msgs) != 1 {
+		t.Fatalf("got %d log messages, want 1: %+v", len(msgs), msgs)
+	}
+	data, _ := msgs[0].Data.(map[string]any)
+	if data["tool"] != "failing" || data["error"] != "Error calling service: command failed: not_found - Service not found" {
+		t.Errorf("Data = %v, want tool name and underlying error", data)
+	}
+}
@@ -0,0 +1,50 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/metrics"
+)
+
+// toolMetrics holds the metrics of tool calls.
+type toolMetrics struct {
+	calls    *metrics.CounterVec
+	errors   *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+// SetMetrics registers the tool call metrics with reg and serves reg at
+// /metrics on the HTTP transport. Tool calls are counted per tool once they
+// run; calls refused beforehand, e.g. by a rate limit, are not. It must be
+// called before the server starts serving.
+func (s *Server) SetMetrics(reg *metrics.Registry) {
+	s.metrics = reg
+	s.toolMetrics = &toolMetrics{
+		calls: reg.NewCounterVec("ha_mcp_tool_calls_total",
+			"Tool calls run, per tool.", "tool"),
+		errors: reg.NewCounterVec("ha_mcp_tool_errors_total",
+			"Tool calls that failed or returned an error result, per tool.", "tool"),
+		duration: reg.NewHistogramVec("ha_mcp_tool_call_duration_seconds",
+			"Time a tool call took to run, per tool.", metrics.DefaultBuckets, "tool"),
+	}
+}
+
+// observeToolCall records a tool call that ran.
+func (s *Server) observeToolCall(name string, result *ToolsCallResult, err error, duration time.Duration) {
+	m := s.toolMetrics
+	if m == nil {
+		return
+	}
+	m.calls.Inc(name)
+	if err != nil || (result != nil && result.IsError) {
+		m.errors.Inc(name)
+	}
+	m.duration.Observe(duration.Seconds(), name)
+}
+
+// handleMetrics serves the metrics to a Prometheus scrape.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	s.metrics.ServeHTTP(w, r)
+}
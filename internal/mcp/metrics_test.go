@@ -0,0 +1,97 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+	"github.com/zorak1103/ha-mcp/internal/metrics"
+)
+
+func TestServer_ToolMetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	readOnly := &ToolAnnotations{ReadOnlyHint: true}
+	registry.RegisterTool(Tool{Name: "get_states", Annotations: readOnly},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("ok")}}, nil
+		})
+	registry.RegisterTool(Tool{Name: "get_state", Annotations: readOnly},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("not found")}, IsError: true}, nil
+		})
+	registry.RegisterTool(Tool{Name: "get_history", Annotations: readOnly},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			return nil, errors.New("connection closed")
+		})
+
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+	s.SetMetrics(metrics.NewRegistry())
+
+	for _, name := range []string{"get_states", "get_states", "get_state", "get_history", "unknown"} {
+		s.handleRequest(context.Background(), &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`1`),
+			Method:  MethodToolsCall,
+			Params:  json.RawMessage(`{"name":"` + name + `"}`),
+		})
+	}
+
+	w := httptest.NewRecorder()
+	s.handleMetrics(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`ha_mcp_tool_calls_total{tool="get_history"} 1`,
+		`ha_mcp_tool_calls_total{tool="get_state"} 1`,
+		`ha_mcp_tool_calls_total{tool="get_states"} 2`,
+		`ha_mcp_tool_errors_total{tool="get_history"} 1`,
+		`ha_mcp_tool_errors_total{tool="get_state"} 1`,
+		`ha_mcp_tool_call_duration_seconds_count{tool="get_states"} 2`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+	for _, unwanted := range []string{`ha_mcp_tool_errors_total{tool="get_states"}`, `tool="unknown"`} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("metrics contain %q:\n%s", unwanted, body)
+		}
+	}
+}
+
+func TestServer_MonitoringAuth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		protectHealth bool
+		wantStatus    int
+	}{
+		{name: "public", protectHealth: false, wantStatus: http.StatusOK},
+		{name: "protected", protectHealth: true, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logging.New(logging.LevelOff))
+			s.SetAuthenticator(NewAuthenticator([]APIKey{{Name: "prometheus", Key: "secret"}}), tt.protectHealth)
+			s.SetMetrics(metrics.NewRegistry())
+
+			w := httptest.NewRecorder()
+			s.monitoringAuth(s.handleMetrics)(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not issued by the server.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PaginatedParams represents the parameters of list requests that support pagination.
+type PaginatedParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// keyCursor is the position of a cursor made by PageAfter: the key of the
+// last item of the previous page.
+type keyCursor struct {
+	After string `json:"after"`
+}
+
+// EncodeCursor encodes a position in a listing as an opaque cursor.
+func EncodeCursor(position any) string {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes a cursor made by EncodeCursor into position.
+func DecodeCursor(cursor string, position any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, position); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// PageAfter returns the page of items that follows cursor, at most limit
+// items, and the cursor of the next page, which is empty on the last page.
+// Items must be sorted by key without duplicates. An empty cursor starts at
+// the first item, and a limit of 0 or less returns all remaining items.
+// Because cursors name the last key seen rather than an offset, pages stay
+// consistent when items are added or removed between requests.
+func PageAfter[T any](items []T, key func(T) string, cursor string, limit int) ([]T, string, error) {
+	start := 0
+	if cursor != "" {
+		var pos keyCursor
+		if err := DecodeCursor(cursor, &pos); err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(items), func(i int) bool { return key(items[i]) > pos.After })
+	}
+
+	end := len(items)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	page := items[start:end]
+
+	next := ""
+	if end < len(items) && end > start {
+		next = EncodeCursor(keyCursor{After: key(items[end-1])})
+	}
+	return page, next, nil
+}
+
+// SetPageSize sets the number of items per page of tools/list and
+// resources/list. A size of 0 returns everything in one page.
+// It must be called before the server starts serving.
+func (s *Server) SetPageSize(size int) {
+	s.pageSize = size
+}
+
+// listCursor returns the cursor of a list request.
+func listCursor(req *Request) (string, error) {
+	if len(req.Params) == 0 {
+		return "", nil
+	}
+	var params PaginatedParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return "", err
+	}
+	return params.Cursor, nil
+}
+
+// nextCursor returns a cursor as the NextCursor of a list result.
+func nextCursor(cursor string) *string {
+	if cursor == "" {
+		return nil
+	}
+	return &cursor
+}
@@ -0,0 +1,107 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+func TestPageAfter(t *testing.T) {
+	t.Parallel()
+
+	items := []string{"a", "b", "c", "d", "e"}
+	identity := func(s string) string { return s }
+
+	tests := []struct {
+		name     string
+		cursor   string
+		limit    int
+		want     []string
+		wantNext bool
+	}{
+		{name: "all", want: items},
+		{name: "first page", limit: 2, want: []string{"a", "b"}, wantNext: true},
+		{name: "after b", cursor: EncodeCursor(keyCursor{After: "b"}), limit: 2, want: []string{"c", "d"}, wantNext: true},
+		{name: "last page", cursor: EncodeCursor(keyCursor{After: "d"}), limit: 2, want: []string{"e"}},
+		{name: "exact last page", cursor: EncodeCursor(keyCursor{After: "c"}), limit: 2, want: []string{"d", "e"}},
+		// The item a cursor points after may have been removed since
+		{name: "removed key", cursor: EncodeCursor(keyCursor{After: "bb"}), limit: 1, want: []string{"c"}, wantNext: true},
+		{name: "past the end", cursor: EncodeCursor(keyCursor{After: "z"}), limit: 2, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, next, err := PageAfter(items, identity, tt.cursor, tt.limit)
+			if err != nil {
+				t.Fatalf("PageAfter() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("PageAfter() mismatch (-want +got):\n%s", diff)
+			}
+			if (next != "") != tt.wantNext {
+				t.Errorf("PageAfter() next = %q, want next page %v", next, tt.wantNext)
+			}
+		})
+	}
+
+	if _, _, err := PageAfter(items, identity, "%%%", 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("PageAfter() with invalid cursor error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestServer_ToolsListPagination(t *testing.T) {
+	t.Parallel()
+
+	handler := func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+		return &ToolsCallResult{}, nil
+	}
+	registry := NewRegistry()
+	for _, name := range []string{"get_state", "call_service", "list_helpers", "delete_scene", "get_history"} {
+		registry.RegisterTool(Tool{Name: name}, handler)
+	}
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+	s.SetPageSize(2)
+
+	var names []string
+	params := `{}`
+	for pages := 0; pages < 5; pages++ {
+		resp := s.handleRequest(context.Background(), &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`1`),
+			Method:  MethodToolsList,
+			Params:  json.RawMessage(params),
+		})
+		if resp.Error != nil {
+			t.Fatalf("tools/list %s: Error = %+v", params, resp.Error)
+		}
+		result := resp.Result.(ToolsListResult)
+		names = append(names, toolNames(result.Tools)...)
+		if result.NextCursor == nil {
+			break
+		}
+		params = `{"cursor":"` + *result.NextCursor + `"}`
+	}
+
+	want := []string{"call_service", "delete_scene", "get_history", "get_state", "list_helpers"}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("paged tool names mismatch (-want +got):\n%s", diff)
+	}
+
+	resp := s.handleRequest(context.Background(), &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodToolsList,
+		Params:  json.RawMessage(`{"cursor":"bogus!"}`),
+	})
+	if resp.Error == nil || resp.Error.Code != InvalidParams {
+		t.Errorf("tools/list with invalid cursor = %+v, want InvalidParams", resp)
+	}
+}
@@ -0,0 +1,99 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// ProgressReporter sends notifications/progress for the tool call it belongs to.
+// A nil reporter is valid and discards all reports, so handlers can report
+// progress unconditionally.
+type ProgressReporter struct {
+	token json.RawMessage
+	send  func(params ProgressParams)
+
+	mu       sync.Mutex
+	progress float64
+	total    float64
+}
+
+// newProgressReporter creates a reporter that sends notifications for the request in ctx.
+func newProgressReporter(ctx context.Context, token json.RawMessage) *ProgressReporter {
+	return &ProgressReporter{
+		token: token,
+		send: func(params ProgressParams) {
+			_ = notifyContext(ctx, MethodProgress, params) // Progress is best effort
+		},
+	}
+}
+
+// progressContextKey is the context key for the progress reporter of the current request.
+type progressContextKey struct{}
+
+// withProgressReporter returns a context carrying the given progress reporter.
+func withProgressReporter(ctx context.Context, p *ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, p)
+}
+
+// ProgressFromContext returns the progress reporter of the current tool call.
+// It returns nil if the client did not ask for progress; calls on nil are no-ops.
+func ProgressFromContext(ctx context.Context) *ProgressReporter {
+	p, _ := ctx.Value(progressContextKey{}).(*ProgressReporter)
+	return p
+}
+
+// Report sends the current progress. Progress must increase with each report;
+// reports that do not are dropped. A total of 0 means the total is unknown.
+func (p *ProgressReporter) Report(progress, total float64, message string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if progress <= p.progress {
+		p.mu.Unlock()
+		return
+	}
+	p.progress = progress
+	p.total = total
+	p.mu.Unlock()
+
+	p.emit(progress, total, message)
+}
+
+// AddTotal increases the expected number of steps, e.g. once a list of items to process is known.
+func (p *ProgressReporter) AddTotal(steps int) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += float64(steps)
+}
+
+// Step marks one step as done and reports the new progress against the expected total.
+func (p *ProgressReporter) Step(message string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.progress++
+	progress, total := p.progress, p.total
+	p.mu.Unlock()
+
+	p.emit(progress, total, message)
+}
+
+// emit sends a progress notification.
+func (p *ProgressReporter) emit(progress, total float64, message string) {
+	p.send(ProgressParams{
+		ProgressToken: p.token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+}
@@ -0,0 +1,110 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+//
+//nolint:errcheck // Test file - defer body.Close() is acceptable
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+)
+
+func TestProgressReporter(t *testing.T) {
+	t.Parallel()
+
+	var sent []ProgressParams
+	p := &ProgressReporter{
+		token: json.RawMessage(`"tok"`),
+		send:  func(params ProgressParams) { sent = append(sent, params) },
+	}
+
+	p.AddTotal(2)
+	p.Step("first")
+	p.Report(1, 2, "not increasing") // Dropped
+	p.AddTotal(1)
+	p.Step("second")
+	p.Report(3, 3, "done")
+
+	want := []ProgressParams{
+		{ProgressToken: json.RawMessage(`"tok"`), Progress: 1, Total: 2, Message: "first"},
+		{ProgressToken: json.RawMessage(`"tok"`), Progress: 2, Total: 3, Message: "second"},
+		{ProgressToken: json.RawMessage(`"tok"`), Progress: 3, Total: 3, Message: "done"},
+	}
+	if diff := cmp.Diff(want, sent); diff != "" {
+		t.Errorf("sent progress mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProgressReporter_Nil(t *testing.T) {
+	t.Parallel()
+
+	p := ProgressFromContext(context.Background())
+	if p != nil {
+		t.Fatalf("ProgressFromContext() = %v, want nil without progress token", p)
+	}
+
+	// Must not panic
+	p.AddTotal(1)
+	p.Step("step")
+	p.Report(1, 1, "done")
+}
+
+func TestServer_ToolsCallProgress(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "long"},
+		func(ctx context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			progress := ProgressFromContext(ctx)
+			progress.AddTotal(2)
+			progress.Step("half")
+			progress.Step("all")
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("done")}}, nil
+		})
+	_, ts := newTestHTTPServer(t, registry)
+
+	t.Run("with progress token", func(t *testing.T) {
+		t.Parallel()
+
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"long","_meta":{"progressToken":"p1"}}}`
+		resp := postMessage(t, ts.URL, "", "application/json, text/event-stream", body)
+		defer resp.Body.Close()
+
+		reader := bufio.NewReader(resp.Body)
+		for i, want := range []float64{1, 2} {
+			var notif struct {
+				Method string         `json:"method"`
+				Params ProgressParams `json:"params"`
+			}
+			if err := json.Unmarshal([]byte(readSSEData(t, reader)), &notif); err != nil {
+				t.Fatalf("event %d: json.Unmarshal() error = %v", i, err)
+			}
+			if notif.Method != MethodProgress {
+				t.Errorf("event %d: Method = %q, want %q", i, notif.Method, MethodProgress)
+			}
+			if string(notif.Params.ProgressToken) != `"p1"` || notif.Params.Progress != want || notif.Params.Total != 2 {
+				t.Errorf("event %d: Params = %+v, want token \"p1\", progress %v of 2", i, notif.Params, want)
+			}
+		}
+		if data := readSSEData(t, reader); !strings.Contains(data, `"id":1`) {
+			t.Errorf("final event = %s, want response with id 1", data)
+		}
+	})
+
+	t.Run("without progress token", func(t *testing.T) {
+		t.Parallel()
+
+		body := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"long"}}`
+		resp := postMessage(t, ts.URL, "", "application/json, text/event-stream", body)
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want plain JSON response without progress", ct)
+		}
+	})
+}
@@ -0,0 +1,231 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Names of the limits reported in RateLimitError.
+const (
+	rateLimitClient = "client"
+	rateLimitTool   = "tool"
+)
+
+// bucketIdleTimeout is how long a token bucket may go unused before it is
+// dropped. A dropped bucket is recreated full, which is the state it would
+// have refilled to unless its rate is below one token per ten minutes.
+const bucketIdleTimeout = 10 * time.Minute
+
+// RateLimit configures a token bucket that refills at Rate tokens per second
+// up to Burst tokens. A Rate of zero disables the limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimits configures the limits applied to tool calls. Zero values disable a limit.
+//
+// The limits are counted per client, identified by its API key, rather than
+// per MCP session, so that a client cannot reset them by initializing a new
+// session. Clients of a server without authentication share one budget.
+type RateLimits struct {
+	// Client limits all tool calls of a client.
+	Client RateLimit
+	// Tool limits the calls of each tool by a client.
+	Tool RateLimit
+	// Tools overrides Tool for individual tools by name.
+	Tools map[string]RateLimit
+	// MaxConcurrentCommands caps the tool calls, resource reads, and prompt
+	// renders that run against Home Assistant at the same time. Further
+	// requests wait for a free slot.
+	MaxConcurrentCommands int
+}
+
+// RateLimitError is the data of a RateLimited error response.
+type RateLimitError struct {
+	// Limit is the exceeded limit: "client" or "tool".
+	Limit string `json:"limit"`
+	// RetryAfter is the number of seconds after which the call would be allowed.
+	RetryAfter float64 `json:"retryAfter"`
+}
+
+// SetRateLimits configures tool call rate limits and the concurrency cap.
+// It must be called before the server starts serving.
+func (s *Server) SetRateLimits(limits RateLimits) {
+	s.limiter = newRateLimiter(limits)
+	s.commandSlots = nil
+	if limits.MaxConcurrentCommands > 0 {
+		s.commandSlots = make(chan struct{}, limits.MaxConcurrentCommands)
+	}
+}
+
+// acquireCommandSlot waits for a free slot under MaxConcurrentCommands. The
+// returned function releases the slot.
+func (s *Server) acquireCommandSlot(ctx context.Context) (func(), error) {
+	if s.commandSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case s.commandSlots <- struct{}{}:
+		return func() { <-s.commandSlots }, nil
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+// checkRateLimit takes a token for a call of tool by the client authenticated
+// in ctx. If a limit is exceeded, it returns the error data describing it.
+func (s *Server) checkRateLimit(ctx context.Context, tool string) *RateLimitError {
+	if s.limiter == nil {
+		return nil
+	}
+	limit, wait := s.limiter.allow(APIKeyNameFromContext(ctx), tool, time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	// Round up to milliseconds so that retrying after RetryAfter succeeds
+	return &RateLimitError{Limit: limit, RetryAfter: math.Ceil(wait.Seconds()*1000) / 1000}
+}
+
+// rateLimitMessage returns the error message for a rate-limited call.
+func rateLimitMessage(tool string, rlErr *RateLimitError) string {
+	return fmt.Sprintf("%s rate limit exceeded for %s, retry after %.3gs", rlErr.Limit, tool, rlErr.RetryAfter)
+}
+
+// tokenBucket holds the state of a token bucket.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (b *tokenBucket) refill(limit RateLimit, now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	b.last = now
+}
+
+// wait returns how long until the bucket holds a whole token.
+func (b *tokenBucket) wait(limit RateLimit) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+}
+
+// bucketKey identifies a token bucket by API key name and tool. Buckets of
+// the client limit have an empty tool.
+type bucketKey struct {
+	client string
+	tool   string
+}
+
+// rateLimiter applies the client and per-tool token buckets.
+type rateLimiter struct {
+	limits RateLimits
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*tokenBucket
+	lastSweep time.Time
+}
+
+// newRateLimiter creates a rate limiter. Limits with a rate but no burst get
+// a burst of one second's worth of calls.
+func newRateLimiter(limits RateLimits) *rateLimiter {
+	limits.Client = normalizeRateLimit(limits.Client)
+	limits.Tool = normalizeRateLimit(limits.Tool)
+	tools := make(map[string]RateLimit, len(limits.Tools))
+	for name, limit := range limits.Tools {
+		tools[name] = normalizeRateLimit(limit)
+	}
+	limits.Tools = tools
+
+	return &rateLimiter{
+		limits:  limits,
+		buckets: make(map[bucketKey]*tokenBucket),
+	}
+}
+
+func normalizeRateLimit(limit RateLimit) RateLimit {
+	if limit.Rate > 0 && limit.Burst < 1 {
+		limit.Burst = max(1, int(math.Ceil(limit.Rate)))
+	}
+	return limit
+}
+
+// toolLimit returns the limit for calls of the named tool.
+func (l *rateLimiter) toolLimit(tool string) RateLimit {
+	if limit, ok := l.limits.Tools[tool]; ok {
+		return limit
+	}
+	return l.limits.Tool
+}
+
+// allow takes a token from the client bucket and the tool bucket
+// of the call. If either is empty, no token is taken, and allow returns the
+// name of the exceeded limit and how long until the call would be allowed.
+func (l *rateLimiter) allow(client, tool string, now time.Time) (string, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	type check struct {
+		name   string
+		limit  RateLimit
+		bucket *tokenBucket
+	}
+	var checks []check
+	if l.limits.Client.Rate > 0 {
+		checks = append(checks, check{rateLimitClient, l.limits.Client, l.bucket(bucketKey{client: client}, l.limits.Client, now)})
+	}
+	if limit := l.toolLimit(tool); limit.Rate > 0 {
+		checks = append(checks, check{rateLimitTool, limit, l.bucket(bucketKey{client: client, tool: tool}, limit, now)})
+	}
+
+	var exceeded string
+	var longest time.Duration
+	for _, c := range checks {
+		c.bucket.refill(c.limit, now)
+		if wait := c.bucket.wait(c.limit); wait > longest {
+			exceeded, longest = c.name, wait
+		}
+	}
+	if longest > 0 {
+		return exceeded, longest
+	}
+
+	for _, c := range checks {
+		c.bucket.tokens--
+	}
+	return "", 0
+}
+
+// bucket returns the bucket for key, creating a full one if needed. l.mu must be held.
+func (l *rateLimiter) bucket(key bucketKey, limit RateLimit, now time.Time) *tokenBucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	return b
+}
+
+// sweep drops buckets that have not been used for bucketIdleTimeout, such as
+// those of removed API keys. l.mu must be held.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketIdleTimeout {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= bucketIdleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+}
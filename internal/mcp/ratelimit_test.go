@@ -0,0 +1,246 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	type call struct {
+		at        time.Duration // since start
+		client    string
+		tool      string
+		wantLimit string
+		wantWait  time.Duration
+	}
+
+	tests := []struct {
+		name   string
+		limits RateLimits
+		calls  []call
+	}{
+		{
+			name:   "no limits",
+			limits: RateLimits{},
+			calls: []call{
+				{client: "a", tool: "x"},
+				{client: "a", tool: "x"},
+			},
+		},
+		{
+			name:   "client burst then refill",
+			limits: RateLimits{Client: RateLimit{Rate: 2, Burst: 2}},
+			calls: []call{
+				{client: "a", tool: "x"},
+				{client: "a", tool: "y"},
+				{client: "a", tool: "z", wantLimit: "client", wantWait: 500 * time.Millisecond},
+				{client: "b", tool: "x"},
+				{at: 500 * time.Millisecond, client: "a", tool: "z"},
+			},
+		},
+		{
+			name:   "tool limit is per client and tool",
+			limits: RateLimits{Tool: RateLimit{Rate: 1, Burst: 1}},
+			calls: []call{
+				{client: "a", tool: "x"},
+				{client: "a", tool: "x", wantLimit: "tool", wantWait: time.Second},
+				{client: "a", tool: "y"},
+				{client: "b", tool: "x"},
+			},
+		},
+		{
+			name: "tool override",
+			limits: RateLimits{
+				Tool:  RateLimit{Rate: 10, Burst: 10},
+				Tools: map[string]RateLimit{"call_service": {Rate: 0.5, Burst: 1}, "get_state": {}},
+			},
+			calls: []call{
+				{client: "a", tool: "call_service"},
+				{client: "a", tool: "call_service", wantLimit: "tool", wantWait: 2 * time.Second},
+				{client: "a", tool: "get_state"},
+			},
+		},
+		{
+			name: "denied call takes no token",
+			limits: RateLimits{
+				Client: RateLimit{Rate: 1, Burst: 2},
+				Tools:  map[string]RateLimit{"call_service": {Rate: 1, Burst: 1}},
+			},
+			calls: []call{
+				{client: "a", tool: "call_service"},
+				{client: "a", tool: "call_service", wantLimit: "tool", wantWait: time.Second},
+				{client: "a", tool: "get_state"},
+			},
+		},
+		{
+			name:   "default burst",
+			limits: RateLimits{Client: RateLimit{Rate: 0.5}},
+			calls: []call{
+				{client: "a", tool: "x"},
+				{client: "a", tool: "x", wantLimit: "client", wantWait: 2 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			l := newRateLimiter(tt.limits)
+			start := time.Now()
+			for i, c := range tt.calls {
+				limit, wait := l.allow(c.client, c.tool, start.Add(c.at))
+				if limit != c.wantLimit || wait != c.wantWait {
+					t.Errorf("call %d: allow() = %q, %v, want %q, %v", i, limit, wait, c.wantLimit, c.wantWait)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_HandleToolsCall_RateLimited(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "call_service", InputSchema: JSONSchema{Type: "object"}},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("ok")}}, nil
+		})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+	s.SetRateLimits(RateLimits{Tools: map[string]RateLimit{"call_service": {Rate: 0.1, Burst: 1}}})
+
+	call := func() *Response {
+		return s.handleRequest(context.Background(), &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`1`),
+			Method:  MethodToolsCall,
+			Params:  json.RawMessage(`{"name":"call_service"}`),
+		})
+	}
+
+	if resp := call(); resp.Error != nil {
+		t.Fatalf("first call: Error = %+v, want nil", resp.Error)
+	}
+
+	resp := call()
+	if resp.Error == nil || resp.Error.Code != RateLimited {
+		t.Fatalf("second call: Error = %+v, want RateLimited", resp.Error)
+	}
+	rlErr, ok := resp.Error.Data.(*RateLimitError)
+	if !ok {
+		t.Fatalf("error data = %T, want *RateLimitError", resp.Error.Data)
+	}
+	if rlErr.Limit != "tool" || rlErr.RetryAfter <= 9 || rlErr.RetryAfter > 10 {
+		t.Errorf("error data = %+v, want tool limit with retryAfter in (9, 10]", rlErr)
+	}
+}
+
+func TestServer_RateLimitPerAPIKey(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "call_service", InputSchema: JSONSchema{Type: "object"}},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("ok")}}, nil
+		})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+	s.SetRateLimits(RateLimits{Tools: map[string]RateLimit{"call_service": {Rate: 0.1, Burst: 1}}})
+
+	call := func(key, sessionID string) *Response {
+		ctx := withSession(withAPIKeyName(context.Background(), key), newSession(sessionID))
+		return s.handleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`1`),
+			Method:  MethodToolsCall,
+			Params:  json.RawMessage(`{"name":"call_service"}`),
+		})
+	}
+
+	if resp := call("laptop", "first"); resp.Error != nil {
+		t.Fatalf("first call: Error = %+v, want nil", resp.Error)
+	}
+	// A new session of the same client does not get a new budget
+	if resp := call("laptop", "second"); resp.Error == nil || resp.Error.Code != RateLimited {
+		t.Errorf("call in a new session: Error = %+v, want RateLimited", resp.Error)
+	}
+	if resp := call("phone", "third"); resp.Error != nil {
+		t.Errorf("call with another key: Error = %+v, want nil", resp.Error)
+	}
+}
+
+func TestServer_MaxConcurrentCommands(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "slow", InputSchema: JSONSchema{Type: "object"}},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+			running.Add(-1)
+			return &ToolsCallResult{}, nil
+		})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+	s.SetRateLimits(RateLimits{MaxConcurrentCommands: 2})
+
+	const calls = 5
+	done := make(chan *Response, calls)
+	for range calls {
+		go func() {
+			done <- s.handleRequest(context.Background(), &Request{
+				JSONRPC: JSONRPCVersion,
+				ID:      json.RawMessage(`1`),
+				Method:  MethodToolsCall,
+				Params:  json.RawMessage(`{"name":"slow"}`),
+			})
+		}()
+	}
+
+	waitFor(t, func() bool { return running.Load() == 2 })
+	close(release)
+
+	for range calls {
+		if resp := <-done; resp.Error != nil {
+			t.Errorf("Error = %+v, want nil", resp.Error)
+		}
+	}
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrent calls = %d, want 2", got)
+	}
+}
+
+func TestServer_AcquireCommandSlot_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logging.New(logging.LevelOff))
+	s.SetRateLimits(RateLimits{MaxConcurrentCommands: 1})
+
+	release, err := s.acquireCommandSlot(context.Background())
+	if err != nil {
+		t.Fatalf("acquireCommandSlot() error = %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errRequestCancelled)
+	if _, err := s.acquireCommandSlot(ctx); !errors.Is(err, errRequestCancelled) {
+		t.Errorf("acquireCommandSlot() error = %v, want %v", err, errRequestCancelled)
+	}
+}
@@ -3,7 +3,11 @@ package mcp
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"regexp"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/zorak1103/ha-mcp/internal/homeassistant"
@@ -16,10 +20,30 @@ type ToolHandler func(ctx context.Context, client homeassistant.Client, args map
 // ResourceHandler is a function that handles a resource read.
 type ResourceHandler func(ctx context.Context, client homeassistant.Client, uri string) (*ResourcesReadResult, error)
 
-// toolEntry holds a tool definition and its handler.
+// ResourceLister enumerates the concrete resources of a resource template for
+// resources/list.
+type ResourceLister func(ctx context.Context, client homeassistant.Client) ([]Resource, error)
+
+// PromptHandler is a function that renders a prompt. Required arguments are
+// checked against the prompt definition before the handler is called.
+type PromptHandler func(ctx context.Context, client homeassistant.Client, args map[string]string) (*PromptsGetResult, error)
+
+// ToolFilter restricts the tools a registry exposes and the calls it lets through.
+type ToolFilter interface {
+	// AllowTool reports whether the tool is exposed at all.
+	AllowTool(tool Tool) bool
+	// AllowCall returns an error if a call to an exposed tool with the
+	// given arguments must not run.
+	AllowCall(tool Tool, args map[string]any) error
+}
+
+// toolEntry holds a tool definition, its handler, the compiled patterns of
+// its input schema, and the toolset it belongs to.
 type toolEntry struct {
-	tool    Tool
-	handler ToolHandler
+	tool     Tool
+	handler  ToolHandler
+	patterns map[string]*regexp.Regexp
+	toolset  string // empty for tools that are always enabled
 }
 
 // resourceEntry holds a resource definition and its handler.
@@ -28,28 +52,110 @@ type resourceEntry struct {
 	handler  ResourceHandler
 }
 
-// Registry manages MCP tools and resources.
+// templateEntry holds a resource template, its compiled pattern, and its handlers.
+type templateEntry struct {
+	template ResourceTemplate
+	pattern  *regexp.Regexp
+	handler  ResourceHandler
+	lister   ResourceLister
+}
+
+// promptEntry holds a prompt definition and its handler.
+type promptEntry struct {
+	prompt  Prompt
+	handler PromptHandler
+}
+
+// Registry manages MCP tools, resources, and prompts.
 type Registry struct {
 	mu        sync.RWMutex
 	tools     map[string]toolEntry
 	resources map[string]resourceEntry
+	templates []templateEntry // matched in registration order
+	prompts   map[string]promptEntry
+	filter    ToolFilter
+	disabled  map[string]bool    // tools rejected by filter
+	confirm   ConfirmationPolicy // selects the tools that take a confirm token
+
+	toolsets        []Toolset       // in registration order
+	currentToolset  string          // toolset of tools registered by RegisterToolset
+	defaultToolsets map[string]bool // toolsets enabled in new sessions; nil enables all
 }
 
-// NewRegistry creates a new tool and resource registry.
+// NewRegistry creates a new tool, resource, and prompt registry.
 func NewRegistry() *Registry {
 	return &Registry{
 		tools:     make(map[string]toolEntry),
 		resources: make(map[string]resourceEntry),
+		prompts:   make(map[string]promptEntry),
+		disabled:  make(map[string]bool),
 	}
 }
 
-// RegisterTool registers a tool with its handler.
+// SetToolFilter restricts the registry to the tools allowed by filter.
+// Registered tools that the filter rejects are removed, and later
+// registrations are filtered as well.
+func (r *Registry) SetToolFilter(filter ToolFilter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.filter = filter
+	for name, entry := range r.tools {
+		if !filter.AllowTool(entry.tool) {
+			delete(r.tools, name)
+			r.disabled[name] = true
+		}
+	}
+}
+
+// setConfirmationPolicy adds the confirm_token argument to the input schema of
+// the registered tools whose calls policy may select, and of such tools
+// registered later.
+func (r *Registry) setConfirmationPolicy(policy ConfirmationPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.confirm = policy
+	for name, entry := range r.tools {
+		if r.takesConfirmTokenLocked(entry.tool) {
+			entry.tool.InputSchema = withConfirmTokenProperty(entry.tool.InputSchema)
+			r.tools[name] = entry
+		}
+	}
+}
+
+// takesConfirmTokenLocked reports whether calls of tool may need
+// confirmation. r.mu must be held.
+func (r *Registry) takesConfirmTokenLocked(tool Tool) bool {
+	return r.confirm != nil && !isReadOnly(tool) && r.confirm.ConfirmsTool(tool)
+}
+
+// RegisterTool registers a tool with its handler. It panics if a pattern in
+// the input schema is not a valid regular expression.
 func (r *Registry) RegisterTool(tool Tool, handler ToolHandler) {
+	if !isReadOnly(tool) {
+		tool.InputSchema = withDryRunProperty(tool.InputSchema)
+	}
+	patterns := make(map[string]*regexp.Regexp)
+	if err := compileSchemaPatterns(&tool.InputSchema, patterns); err != nil {
+		panic(fmt.Sprintf("tool %s: %v", tool.Name, err))
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if r.filter != nil && !r.filter.AllowTool(tool) {
+		r.disabled[tool.Name] = true
+		return
+	}
+	delete(r.disabled, tool.Name)
+	if r.takesConfirmTokenLocked(tool) {
+		tool.InputSchema = withConfirmTokenProperty(tool.InputSchema)
+	}
 	r.tools[tool.Name] = toolEntry{
-		tool:    tool,
-		handler: handler,
+		tool:     tool,
+		handler:  handler,
+		patterns: patterns,
+		toolset:  r.currentToolset,
 	}
 }
 
@@ -63,6 +169,36 @@ func (r *Registry) RegisterResource(resource Resource, handler ResourceHandler)
 	}
 }
 
+// RegisterResourceTemplate registers a resource template with its handler. The
+// handler serves every URI matching the template. The lister, if not nil,
+// enumerates concrete resources for resources/list. It panics if the template
+// is malformed.
+func (r *Registry) RegisterResourceTemplate(template ResourceTemplate, handler ResourceHandler, lister ResourceLister) {
+	pattern, err := compileURITemplate(template.URITemplate)
+	if err != nil {
+		panic(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates = append(r.templates, templateEntry{
+		template: template,
+		pattern:  pattern,
+		handler:  handler,
+		lister:   lister,
+	})
+}
+
+// RegisterPrompt registers a prompt with its handler.
+func (r *Registry) RegisterPrompt(prompt Prompt, handler PromptHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompts[prompt.Name] = promptEntry{
+		prompt:  prompt,
+		handler: handler,
+	}
+}
+
 // ListTools returns all registered tools.
 func (r *Registry) ListTools() []Tool {
 	r.mu.RLock()
@@ -87,6 +223,44 @@ func (r *Registry) ListResources() []Resource {
 	return resources
 }
 
+// ListResourceTemplates returns all registered resource templates.
+func (r *Registry) ListResourceTemplates() []ResourceTemplate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]ResourceTemplate, 0, len(r.templates))
+	for _, entry := range r.templates {
+		templates = append(templates, entry.template)
+	}
+	return templates
+}
+
+// resourceListers returns the listers of all registered resource templates.
+func (r *Registry) resourceListers() []ResourceLister {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	listers := make([]ResourceLister, 0, len(r.templates))
+	for _, entry := range r.templates {
+		if entry.lister != nil {
+			listers = append(listers, entry.lister)
+		}
+	}
+	return listers
+}
+
+// ListPrompts returns all registered prompts.
+func (r *Registry) ListPrompts() []Prompt {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prompts := make([]Prompt, 0, len(r.prompts))
+	for _, entry := range r.prompts {
+		prompts = append(prompts, entry.prompt)
+	}
+	return prompts
+}
+
 // GetHandler returns the handler for a tool by name.
 func (r *Registry) GetHandler(name string) (ToolHandler, bool) {
 	r.mu.RLock()
@@ -99,16 +273,21 @@ func (r *Registry) GetHandler(name string) (ToolHandler, bool) {
 	return entry.handler, true
 }
 
-// GetResourceHandler returns the handler for a resource by URI.
+// GetResourceHandler returns the handler for a resource by URI. Exactly
+// registered resources take precedence over resource templates.
 func (r *Registry) GetResourceHandler(uri string) (ResourceHandler, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	entry, exists := r.resources[uri]
-	if !exists {
-		return nil, false
+	if entry, exists := r.resources[uri]; exists {
+		return entry.handler, true
 	}
-	return entry.handler, true
+	for _, entry := range r.templates {
+		if entry.pattern.MatchString(uri) {
+			return entry.handler, true
+		}
+	}
+	return nil, false
 }
 
 // GetTool returns a tool by name.
@@ -123,6 +302,47 @@ func (r *Registry) GetTool(name string) (Tool, bool) {
 	return entry.tool, true
 }
 
+// ValidateArguments checks the arguments of a call to the named tool against
+// its input schema. It returns a *ValidationError for the first violation.
+func (r *Registry) ValidateArguments(name string, args map[string]any) error {
+	r.mu.RLock()
+	entry, exists := r.tools[name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("tool not found: %s", name)
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+
+	v := schemaValidator{patterns: entry.patterns}
+	if verr := v.validate(&entry.tool.InputSchema, args, ""); verr != nil {
+		return verr
+	}
+	return nil
+}
+
+// CheckToolCall returns an error if the tool filter forbids a call to the
+// named tool with the given arguments.
+func (r *Registry) CheckToolCall(name string, args map[string]any) error {
+	r.mu.RLock()
+	entry, exists := r.tools[name]
+	filter := r.filter
+	r.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("tool not found: %s", name)
+	}
+	if filter == nil {
+		return nil
+	}
+	if !filter.AllowTool(entry.tool) {
+		return fmt.Errorf("tool %s is disabled by configuration", name)
+	}
+	return filter.AllowCall(entry.tool, args)
+}
+
 // GetResource returns a resource by URI.
 func (r *Registry) GetResource(uri string) (Resource, bool) {
 	r.mu.RLock()
@@ -135,6 +355,18 @@ func (r *Registry) GetResource(uri string) (Resource, bool) {
 	return entry.resource, true
 }
 
+// GetPrompt returns a prompt definition and its handler by name.
+func (r *Registry) GetPrompt(name string) (Prompt, PromptHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, exists := r.prompts[name]
+	if !exists {
+		return Prompt{}, nil, false
+	}
+	return entry.prompt, entry.handler, true
+}
+
 // ToolCount returns the number of registered tools.
 func (r *Registry) ToolCount() int {
 	r.mu.RLock()
@@ -149,16 +381,32 @@ func (r *Registry) ResourceCount() int {
 	return len(r.resources)
 }
 
+// ResourceTemplateCount returns the number of registered resource templates.
+func (r *Registry) ResourceTemplateCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.templates)
+}
+
+// PromptCount returns the number of registered prompts.
+func (r *Registry) PromptCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.prompts)
+}
+
 // maxDescriptionLen is the maximum length for tool descriptions in log output.
 const maxDescriptionLen = 80
 
 // LogRegisteredTools logs all registered tools at Debug level.
 // This is useful for debugging and verifying that all expected tools are available.
+// If a tool filter is set, the effective tool set and the tools it disabled
+// are logged at Info level.
 func (r *Registry) LogRegisteredTools(logger *logging.Logger) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if logger == nil || !logger.IsDebugEnabled() {
+	if logger == nil {
 		return
 	}
 
@@ -169,6 +417,12 @@ func (r *Registry) LogRegisteredTools(logger *logging.Logger) {
 	}
 	sort.Strings(toolNames)
 
+	r.logEffectiveToolsLocked(logger, toolNames)
+
+	if !logger.IsDebugEnabled() {
+		return
+	}
+
 	logger.Debug("Registered MCP tools:")
 	for _, name := range toolNames {
 		entry := r.tools[name]
@@ -189,6 +443,53 @@ func (r *Registry) LogRegisteredTools(logger *logging.Logger) {
 			logger.Debug("  - "+uri, "name", entry.resource.Name)
 		}
 	}
+
+	if len(r.templates) > 0 {
+		logger.Debug("Registered MCP resource templates:")
+		for _, entry := range r.templates {
+			logger.Debug("  - "+entry.template.URITemplate, "name", entry.template.Name)
+		}
+	}
+
+	// And prompts
+	if len(r.prompts) > 0 {
+		promptNames := make([]string, 0, len(r.prompts))
+		for name := range r.prompts {
+			promptNames = append(promptNames, name)
+		}
+		sort.Strings(promptNames)
+
+		logger.Debug("Registered MCP prompts:")
+		for _, name := range promptNames {
+			entry := r.prompts[name]
+			logger.Debug("  - "+name, "description", truncateDescription(entry.prompt.Description, maxDescriptionLen))
+		}
+	}
+}
+
+// logEffectiveToolsLocked logs the tools left by the tool filter and the
+// toolsets enabled by default. r.mu must be held.
+func (r *Registry) logEffectiveToolsLocked(logger *logging.Logger, toolNames []string) {
+	if r.filter != nil {
+		disabledNames := make([]string, 0, len(r.disabled))
+		for name := range r.disabled {
+			disabledNames = append(disabledNames, name)
+		}
+		sort.Strings(disabledNames)
+
+		logger.Info("Effective MCP tool set", "count", len(toolNames), "tools", toolNames)
+		logger.Info("Tools disabled by configuration", "count", len(disabledNames), "tools", disabledNames)
+	}
+
+	if len(r.toolsets) > 0 {
+		enabled := make([]string, 0, len(r.toolsets))
+		for _, ts := range r.toolsets {
+			if r.toolsetEnabledLocked(nil, ts.Name) {
+				enabled = append(enabled, ts.Name)
+			}
+		}
+		logger.Info("Toolsets enabled by default", "count", len(enabled), "total", len(r.toolsets), "toolsets", enabled)
+	}
 }
 
 // truncateDescription truncates a description to maxLen characters.
@@ -198,3 +499,64 @@ func truncateDescription(desc string, maxLen int) string {
 	}
 	return desc[:maxLen-3] + "..."
 }
+
+// uriTemplateVar matches a simple {name} expression in a URI template.
+var uriTemplateVar = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// compileURITemplate compiles an RFC 6570 level 1 URI template into a regular
+// expression. Each {name} expression matches one non-empty path segment and is
+// captured in a named group.
+func compileURITemplate(template string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	writeLiteral := func(literal string) error {
+		if strings.ContainsAny(literal, "{}") {
+			return fmt.Errorf("invalid URI template: %s", template)
+		}
+		sb.WriteString(regexp.QuoteMeta(literal))
+		return nil
+	}
+
+	last := 0
+	for _, loc := range uriTemplateVar.FindAllStringSubmatchIndex(template, -1) {
+		if err := writeLiteral(template[last:loc[0]]); err != nil {
+			return nil, err
+		}
+		sb.WriteString("(?P<" + template[loc[2]:loc[3]] + ">[^/?#]+)")
+		last = loc[1]
+	}
+	if err := writeLiteral(template[last:]); err != nil {
+		return nil, err
+	}
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+// MatchURITemplate matches uri against a URI template and returns the
+// percent-decoded values of its variables.
+func MatchURITemplate(template, uri string) (map[string]string, bool) {
+	pattern, err := compileURITemplate(template)
+	if err != nil {
+		return nil, false
+	}
+
+	match := pattern.FindStringSubmatch(uri)
+	if match == nil {
+		return nil, false
+	}
+
+	vars := make(map[string]string, len(match)-1)
+	for i, name := range pattern.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		value, err := url.PathUnescape(match[i])
+		if err != nil {
+			return nil, false
+		}
+		vars[name] = value
+	}
+	return vars, true
+}
@@ -4,7 +4,11 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 
@@ -43,6 +47,8 @@ func TestRegistry_RegisterTool(t *testing.T) {
 		Name:        "test_tool",
 		Description: "A test tool",
 		InputSchema: JSONSchema{Type: "object"},
+		// Read-only, so that no dry_run argument is added
+		Annotations: &ToolAnnotations{ReadOnlyHint: true},
 	}
 	handler := func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
 		return &ToolsCallResult{Content: []ContentBlock{NewTextContent("test")}}, nil
@@ -504,3 +510,236 @@ func TestRegistry_ToolCount_ResourceCount(t *testing.T) {
 		t.Errorf("ToolCount() after overwrite = %d, want 3", got)
 	}
 }
+
+func TestRegistry_RegisterPrompt(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	prompt := Prompt{
+		Name:        "test_prompt",
+		Description: "A test prompt",
+		Arguments:   []PromptArgument{{Name: "entity_id", Required: true}},
+	}
+	handler := func(_ context.Context, _ homeassistant.Client, _ map[string]string) (*PromptsGetResult, error) {
+		return &PromptsGetResult{}, nil
+	}
+
+	r.RegisterPrompt(prompt, handler)
+
+	if r.PromptCount() != 1 {
+		t.Errorf("PromptCount() = %d, want 1", r.PromptCount())
+	}
+
+	got, gotHandler, exists := r.GetPrompt("test_prompt")
+	if !exists {
+		t.Fatal("GetPrompt() returned false, want true")
+	}
+	if gotHandler == nil {
+		t.Error("GetPrompt() returned nil handler")
+	}
+	if diff := cmp.Diff(prompt, got); diff != "" {
+		t.Errorf("GetPrompt() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, exists := r.GetPrompt("missing"); exists {
+		t.Error("GetPrompt(missing) returned true, want false")
+	}
+	if len(r.ListPrompts()) != 1 {
+		t.Errorf("len(ListPrompts()) = %d, want 1", len(r.ListPrompts()))
+	}
+}
+
+func TestRegistry_ResourceTemplates(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	exact := func(_ context.Context, _ homeassistant.Client, _ string) (*ResourcesReadResult, error) {
+		return &ResourcesReadResult{Contents: []ResourceContent{{Text: "exact"}}}, nil
+	}
+	templated := func(_ context.Context, _ homeassistant.Client, _ string) (*ResourcesReadResult, error) {
+		return &ResourcesReadResult{Contents: []ResourceContent{{Text: "template"}}}, nil
+	}
+
+	r.RegisterResource(Resource{URI: "hass://entity/sun.sun", Name: "Sun"}, exact)
+	r.RegisterResourceTemplate(ResourceTemplate{URITemplate: "hass://entity/{entity_id}", Name: "Entity"}, templated, nil)
+
+	if r.ResourceTemplateCount() != 1 {
+		t.Errorf("ResourceTemplateCount() = %d, want 1", r.ResourceTemplateCount())
+	}
+
+	tests := []struct {
+		uri      string
+		wantOK   bool
+		wantText string
+	}{
+		{uri: "hass://entity/sun.sun", wantOK: true, wantText: "exact"},
+		{uri: "hass://entity/light.kitchen", wantOK: true, wantText: "template"},
+		{uri: "hass://entity/", wantOK: false},
+		{uri: "hass://entity/a/b", wantOK: false},
+		{uri: "hass://area/kitchen", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			t.Parallel()
+
+			handler, ok := r.GetResourceHandler(tt.uri)
+			if ok != tt.wantOK {
+				t.Fatalf("GetResourceHandler(%q) ok = %v, want %v", tt.uri, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			result, _ := handler(context.Background(), nil, tt.uri)
+			if got := result.Contents[0].Text; got != tt.wantText {
+				t.Errorf("handler returned %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestRegistry_RegisterResourceTemplate_Invalid(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterResourceTemplate() did not panic for a malformed template")
+		}
+	}()
+	NewRegistry().RegisterResourceTemplate(ResourceTemplate{URITemplate: "hass://entity/{entity-id}"}, nil, nil)
+}
+
+func TestMatchURITemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		uri      string
+		want     map[string]string
+		wantOK   bool
+	}{
+		{
+			name:     "single variable",
+			template: "hass://automation/{id}",
+			uri:      "hass://automation/morning_lights",
+			want:     map[string]string{"id": "morning_lights"},
+			wantOK:   true,
+		},
+		{
+			name:     "percent-encoded value",
+			template: "hass://lovelace/{dashboard}",
+			uri:      "hass://lovelace/my%20dash",
+			want:     map[string]string{"dashboard": "my dash"},
+			wantOK:   true,
+		},
+		{
+			name:     "multiple variables",
+			template: "test://{a}/x/{b}",
+			uri:      "test://1/x/2",
+			want:     map[string]string{"a": "1", "b": "2"},
+			wantOK:   true,
+		},
+		{
+			name:     "literal characters are not patterns",
+			template: "hass://entity.{id}",
+			uri:      "hass://entityX1",
+			wantOK:   false,
+		},
+		{
+			name:     "no match",
+			template: "hass://script/{id}",
+			uri:      "hass://automation/x",
+			wantOK:   false,
+		},
+		{
+			name:     "malformed template",
+			template: "hass://script/{id",
+			uri:      "hass://script/{id",
+			wantOK:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := MatchURITemplate(tt.template, tt.uri)
+			if ok != tt.wantOK {
+				t.Fatalf("MatchURITemplate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("MatchURITemplate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// prefixFilter is a ToolFilter that exposes tools with a name prefix and
+// rejects calls with a "deny" argument.
+type prefixFilter struct {
+	prefix string
+}
+
+func (f prefixFilter) AllowTool(tool Tool) bool {
+	return strings.HasPrefix(tool.Name, f.prefix)
+}
+
+func (f prefixFilter) AllowCall(_ Tool, args map[string]any) error {
+	if _, ok := args["deny"]; ok {
+		return errors.New("denied")
+	}
+	return nil
+}
+
+func TestRegistry_SetToolFilter(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	r.RegisterTool(Tool{Name: "get_a"}, nil)
+	r.RegisterTool(Tool{Name: "set_a"}, nil)
+
+	r.SetToolFilter(prefixFilter{prefix: "get_"})
+
+	r.RegisterTool(Tool{Name: "get_b"}, nil)
+	r.RegisterTool(Tool{Name: "set_b"}, nil)
+
+	var names []string
+	for _, tool := range r.ListTools() {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	if diff := cmp.Diff([]string{"get_a", "get_b"}, names); diff != "" {
+		t.Errorf("ListTools() mismatch (-want +got):\n%s", diff)
+	}
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]any
+		wantErr bool
+	}{
+		{name: "allowed", tool: "get_a", args: nil},
+		{name: "denied call", tool: "get_a", args: map[string]any{"deny": true}, wantErr: true},
+		{name: "filtered tool", tool: "set_a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := r.CheckToolCall(tt.tool, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckToolCall(%q) error = %v, wantErr %v", tt.tool, err, tt.wantErr)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	r.LogRegisteredTools(logging.NewWithWriter(logging.LevelInfo, &buf))
+	for _, want := range []string{"Effective MCP tool set", "Tools disabled by configuration", "set_b"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("LogRegisteredTools() output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
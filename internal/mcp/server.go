@@ -8,11 +8,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zorak1103/ha-mcp/internal/homeassistant"
 	"github.com/zorak1103/ha-mcp/internal/logging"
+	"github.com/zorak1103/ha-mcp/internal/metrics"
 )
 
 const (
@@ -20,8 +24,9 @@ const (
 	ServerName = "ha-mcp"
 	// ServerVersion is the version reported in MCP initialize response.
 	ServerVersion = "1.0.0"
-	// ProtocolVersion is the MCP protocol version supported.
-	ProtocolVersion = "2024-11-05"
+	// ProtocolVersion is the newest MCP protocol version supported. It is
+	// offered to clients that request a version the server does not know.
+	ProtocolVersion = ProtocolVersion20251125
 )
 
 // HTTP server timeout constants.
@@ -34,13 +39,37 @@ const (
 
 // Server represents the MCP server.
 type Server struct {
-	haClient    homeassistant.Client
-	registry    *Registry
-	httpServer  *http.Server
-	port        int
-	logger      *logging.Logger
-	mu          sync.RWMutex
-	initialized bool
+	haClient   homeassistant.Client
+	registry   *Registry
+	httpServer *http.Server
+	port       int
+	logger     *logging.Logger
+	sessions   *sessionStore
+
+	// clientInitialized is set once any client completed initialization,
+	// including clients without a session of their own.
+	clientInitialized atomic.Bool
+
+	auth          *Authenticator
+	protectHealth bool
+	redacted      []string
+
+	limiter      *rateLimiter
+	commandSlots chan struct{} // nil without a concurrency cap
+
+	audit  *AuditLog
+	dryRun bool
+
+	confirm       ConfirmationPolicy
+	confirmTokens *confirmTokenStore
+
+	pageSize int // of tools/list and resources/list; 0 = unlimited
+
+	metrics     *metrics.Registry // nil without /metrics
+	toolMetrics *toolMetrics
+
+	watchMu sync.Mutex
+	watcher *stateWatcher
 }
 
 // NewServer creates a new MCP server instance.
@@ -48,19 +77,25 @@ func NewServer(haClient homeassistant.Client, registry *Registry, port int, logg
 	if logger == nil {
 		logger = logging.New(logging.LevelInfo)
 	}
-	return &Server{
+	s := &Server{
 		haClient: haClient,
 		registry: registry,
 		port:     port,
 		logger:   logger,
+		sessions: newSessionStore(),
 	}
+	logger.SetHook(s.forwardLogRecord)
+	return s
 }
 
 // Start starts the MCP HTTP server.
 func (s *Server) Start() error {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", s.handleMCP)
-	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/", s.requireAuth(s.handleMCP))
+	mux.HandleFunc("/health", s.monitoringAuth(s.handleHealth))
+	if s.metrics != nil {
+		mux.HandleFunc("/metrics", s.monitoringAuth(s.handleMetrics))
+	}
 
 	s.httpServer = &http.Server{
 		Addr:              fmt.Sprintf(":%d", s.port),
@@ -71,7 +106,10 @@ func (s *Server) Start() error {
 		IdleTimeout:       httpIdleTimeout,
 	}
 
-	s.logger.Info("MCP server starting", "port", s.port)
+	if s.auth == nil {
+		s.logger.Warn("MCP HTTP endpoint is unauthenticated, configure server.auth to require API keys")
+	}
+	s.logger.Info("MCP server starting", "port", s.port, "auth", s.auth != nil)
 	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("server error: %w", err)
 	}
@@ -80,13 +118,30 @@ func (s *Server) Start() error {
 
 // Shutdown gracefully shuts down the server.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopWatching(ctx)
+
 	if s.httpServer == nil {
 		return nil
 	}
 	s.logger.Info("MCP server shutting down...")
+
+	// Close open SSE streams so their handlers return and connections become idle
+	for _, sess := range s.sessions.all() {
+		closeSessionStream(sess)
+	}
+
 	return s.httpServer.Shutdown(ctx)
 }
 
+// monitoringAuth wraps the handler of a monitoring endpoint, which requires
+// authentication only if protectHealth is set.
+func (s *Server) monitoringAuth(next http.HandlerFunc) http.HandlerFunc {
+	if s.protectHealth {
+		return s.requireAuth(next)
+	}
+	return next
+}
+
 // handleHealth handles health check requests.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	s.logger.Debug("Health check request", "remote_addr", r.RemoteAddr)
@@ -95,16 +150,32 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(`{"status":"ok"}`))
 }
 
-// handleMCP handles MCP JSON-RPC requests.
+// handleMCP handles requests to the MCP endpoint (Streamable HTTP transport).
+// POST carries client messages, GET opens an SSE stream for server-to-client
+// messages, and DELETE ends a session.
 func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
-	startTime := time.Now()
+	if version := r.Header.Get(ProtocolVersionHeader); version != "" && !IsSupportedProtocolVersion(version) {
+		s.logger.Warn("Unsupported protocol version header", "version", version, "remote_addr", r.RemoteAddr)
+		s.writeErrorStatus(w, http.StatusBadRequest, nil, InvalidRequest, "unsupported protocol version: "+version)
+		return
+	}
 
-	if r.Method != http.MethodPost {
+	switch r.Method {
+	case http.MethodPost:
+		s.handlePost(w, r)
+	case http.MethodGet:
+		s.handleSSE(w, r)
+	case http.MethodDelete:
+		s.handleDeleteSession(w, r)
+	default:
 		s.logger.Warn("Invalid HTTP method", "method", r.Method, "remote_addr", r.RemoteAddr)
-		s.writeError(w, nil, InvalidRequest, "method not allowed", nil)
-		return
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		s.writeErrorStatus(w, http.StatusMethodNotAllowed, nil, InvalidRequest, "method not allowed")
 	}
+}
 
+// handlePost handles a JSON-RPC message sent by the client.
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
 
 	body, err := io.ReadAll(r.Body)
@@ -114,49 +185,238 @@ func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sess, ok := s.resolvePostSession(w, r, body)
+	if !ok {
+		return
+	}
+	sess.touch()
+
+	stream := newResponseStream(w, r)
+	ctx := withRequestSink(withSession(r.Context(), sess), stream)
+
+	resp := s.processMessage(ctx, body, r.RemoteAddr)
+	if stream.finish(resp) {
+		return // Response was delivered as the final SSE event
+	}
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	s.writeResponse(w, resp)
+}
+
+// resolvePostSession finds the session a POST belongs to. An initialize request
+// without a session ID starts a new session. Other requests without a session ID
+// get an ephemeral session of their own, so that clients without session support
+// keep working but share no state with each other.
+func (s *Server) resolvePostSession(w http.ResponseWriter, r *http.Request, body []byte) (*Session, bool) {
+	if r.Header.Get(SessionIDHeader) != "" {
+		return s.lookupSession(w, r)
+	}
+
+	if peekMethod(body) != MethodInitialize {
+		return newEphemeralSession(), true
+	}
+
+	sess, err := s.sessions.create(APIKeyNameFromContext(r.Context()))
+	if err != nil {
+		s.logger.Error("Failed to create session", "remote_addr", r.RemoteAddr, "error", err)
+		s.writeErrorStatus(w, http.StatusInternalServerError, nil, InternalError, "failed to create session")
+		return nil, false
+	}
+
+	s.logger.Debug("Session created", logAttrs(r.Context(), "session_id", sess.ID(), "remote_addr", r.RemoteAddr)...)
+	w.Header().Set(SessionIDHeader, sess.ID())
+	return sess, true
+}
+
+// lookupSession returns the session named by the Mcp-Session-Id header,
+// writing an error response if the header is missing or the session is unknown.
+// A session created with another API key is reported as unknown, so that
+// clients cannot take over each other's sessions.
+func (s *Server) lookupSession(w http.ResponseWriter, r *http.Request) (*Session, bool) {
+	id := r.Header.Get(SessionIDHeader)
+	if id == "" {
+		s.writeErrorStatus(w, http.StatusBadRequest, nil, InvalidRequest, "missing "+SessionIDHeader+" header")
+		return nil, false
+	}
+
+	sess, ok := s.sessions.get(id)
+	if !ok {
+		s.logger.Warn("Unknown session", "session_id", id, "remote_addr", r.RemoteAddr)
+		s.writeErrorStatus(w, http.StatusNotFound, nil, InvalidRequest, "session not found")
+		return nil, false
+	}
+	if sess.apiKey != APIKeyNameFromContext(r.Context()) {
+		s.logger.Warn("Session used with another API key",
+			logAttrs(r.Context(), "session_id", id, "remote_addr", r.RemoteAddr)...)
+		s.writeErrorStatus(w, http.StatusNotFound, nil, InvalidRequest, "session not found")
+		return nil, false
+	}
+	return sess, true
+}
+
+// handleSSE opens a long-lived SSE stream for server-to-client messages of a session.
+// A session has at most one such stream; opening a new one closes the previous stream.
+func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+	if !acceptsEventStream(r) {
+		s.writeErrorStatus(w, http.StatusNotAcceptable, nil, InvalidRequest, "client must accept "+contentTypeEventStream)
+		return
+	}
+
+	stream := newSessionStream()
+	if prev, isStream := sess.attachSink(stream).(*sessionStream); isStream {
+		prev.close()
+	}
+	defer stream.close()
+	defer sess.detachSink(stream)
+
+	s.logger.Debug("SSE stream opened", "session_id", sess.ID(), "remote_addr", r.RemoteAddr)
+	defer s.logger.Debug("SSE stream closed", "session_id", sess.ID())
+
+	rc := beginEventStream(w)
+	ticker := time.NewTicker(sseKeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-stream.done:
+			return
+		case data := <-stream.messages:
+			if err := writeSSEEvent(w, rc, data); err != nil {
+				return
+			}
+			sess.touch()
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			_ = rc.Flush()
+		}
+	}
+}
+
+// handleDeleteSession ends a session at the client's request.
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	s.sessions.remove(sess.ID())
+	sess.end()
+
+	s.logger.Info("MCP session ended", "session_id", sess.ID(), "client_name", sess.ClientInfo().Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// closeSessionStream closes the session's GET stream, if one is open.
+func closeSessionStream(sess *Session) {
+	if stream, ok := sess.attachSink(nil).(*sessionStream); ok {
+		stream.close()
+	}
+}
+
+// peekMethod returns the method of a JSON-RPC message without fully validating it.
+func peekMethod(body []byte) string {
+	var msg struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return ""
+	}
+	return msg.Method
+}
+
+// processMessage parses a JSON-RPC message or batch, dispatches it and returns
+// the reply: a *Response for a single message, a []*Response for a batch, or nil
+// if there is nothing to send back (notifications). It is shared by all transports.
+func (s *Server) processMessage(ctx context.Context, body []byte, remoteAddr string) any {
 	// TRACE: Log full request body
-	s.logger.Trace("Request received", "remote_addr", r.RemoteAddr, "body", string(body))
+	s.logger.Trace("Request received", "remote_addr", remoteAddr, "body", string(body))
+
+	if isBatch(body) {
+		if responses := s.processBatch(ctx, body, remoteAddr); responses != nil {
+			return responses
+		}
+		return nil
+	}
+
+	if resp := s.processSingle(ctx, body, remoteAddr); resp != nil {
+		return resp
+	}
+	return nil
+}
+
+// processSingle parses a single JSON-RPC message, dispatches it and returns the response.
+// A nil response means the message was a notification.
+func (s *Server) processSingle(ctx context.Context, body []byte, remoteAddr string) *Response {
+	startTime := time.Now()
 
 	var req Request
 	if err := json.Unmarshal(body, &req); err != nil {
-		s.logger.Error("Invalid JSON", "remote_addr", r.RemoteAddr, "error", err)
-		s.writeError(w, nil, ParseError, "invalid JSON", err.Error())
-		return
+		s.logger.Error("Invalid JSON", "remote_addr", remoteAddr, "error", err)
+		return NewErrorResponse(nil, ParseError, "invalid JSON", err.Error())
 	}
 
 	if req.JSONRPC != JSONRPCVersion {
-		s.logger.Warn("Invalid JSON-RPC version", "remote_addr", r.RemoteAddr, "version", req.JSONRPC)
-		s.writeError(w, req.ID, InvalidRequest, "invalid jsonrpc version", nil)
-		return
+		s.logger.Warn("Invalid JSON-RPC version", "remote_addr", remoteAddr, "version", req.JSONRPC)
+		return NewErrorResponse(req.ID, InvalidRequest, "invalid jsonrpc version", nil)
+	}
+
+	// Responses to server requests such as elicitation/create have no method
+	if req.Method == "" && req.ID != nil && s.handleClientResponse(ctx, body) {
+		return nil
 	}
 
 	// DEBUG: Log method and summary
-	s.logger.Debug("Request", "method", req.Method, "id", formatID(req.ID))
+	s.logger.DebugContext(ctx, "Request", logAttrs(ctx, "method", req.Method, "id", formatID(req.ID))...)
 
-	resp := s.handleRequest(r.Context(), &req)
+	resp := s.handleRequest(ctx, &req)
 
 	duration := time.Since(startTime)
-	s.logResponse(&req, resp, duration)
+	s.logResponse(ctx, &req, resp, duration)
 
-	s.writeResponse(w, resp)
+	return resp
+}
+
+// handleClientResponse delivers a response from the client to the server
+// request waiting for it and reports whether body was a response. Responses
+// that nothing waits for anymore, e.g. after a timeout, are dropped.
+func (s *Server) handleClientResponse(ctx context.Context, body []byte) bool {
+	var resp clientResponse
+	if err := json.Unmarshal(body, &resp); err != nil || (resp.Result == nil && resp.Error == nil) {
+		return false
+	}
+
+	if !s.sessionFor(ctx).deliverResponse(&resp) {
+		s.logger.DebugContext(ctx, "Dropped response to unknown request", logAttrs(ctx, "id", formatID(resp.ID))...)
+	}
+	return true
 }
 
 // logResponse logs the response at appropriate levels.
-func (s *Server) logResponse(req *Request, resp *Response, duration time.Duration) {
+func (s *Server) logResponse(ctx context.Context, req *Request, resp *Response, duration time.Duration) {
 	if resp == nil {
 		// Notification - no response
-		s.logger.Debug("Notification processed", "method", req.Method, "duration", duration)
+		s.logger.DebugContext(ctx, "Notification processed", logAttrs(ctx, "method", req.Method, "duration", duration)...)
 		return
 	}
 
 	if resp.Error != nil {
 		// Error response
-		s.logger.Error("Request failed",
+		s.logger.ErrorContext(ctx, "Request failed", logAttrs(ctx,
 			"method", req.Method,
 			"id", formatID(req.ID),
 			"error_code", resp.Error.Code,
 			"error_message", resp.Error.Message,
-			"duration", duration)
+			"duration", duration)...)
 
 		// TRACE: Log full error details
 		if resp.Error.Data != nil {
@@ -166,7 +426,7 @@ func (s *Server) logResponse(req *Request, resp *Response, duration time.Duratio
 	}
 
 	// Success response
-	s.logger.Info("Request completed", "method", req.Method, "id", formatID(req.ID), "duration", duration)
+	s.logger.InfoContext(ctx, "Request completed", logAttrs(ctx, "method", req.Method, "id", formatID(req.ID), "duration", duration)...)
 
 	// TRACE: Log full response
 	if s.logger.IsTraceEnabled() {
@@ -189,19 +449,33 @@ func formatID(id json.RawMessage) string {
 func (s *Server) handleRequest(ctx context.Context, req *Request) *Response {
 	switch req.Method {
 	case MethodInitialize:
-		return s.handleInitialize(req)
+		return s.handleInitialize(ctx, req)
 	case MethodInitialized:
-		return s.handleInitialized(req)
+		return s.handleInitialized(ctx, req)
+	case MethodCanceled:
+		return s.handleCancelled(ctx, req)
+	case MethodLoggingSetLvl:
+		return s.handleSetLevel(ctx, req)
 	case MethodPing:
 		return s.handlePing(req)
 	case MethodToolsList:
-		return s.handleToolsList(req)
+		return s.handleToolsList(ctx, req)
 	case MethodToolsCall:
 		return s.handleToolsCall(ctx, req)
 	case MethodResourcesList:
-		return s.handleResourcesList(req)
+		return s.handleResourcesList(ctx, req)
 	case MethodResourcesRead:
 		return s.handleResourcesRead(ctx, req)
+	case MethodResourcesTmpl:
+		return s.handleResourcesTemplatesList(req)
+	case MethodResourcesSubscribe:
+		return s.handleResourcesSubscribe(ctx, req)
+	case MethodResourcesUnsubscribe:
+		return s.handleResourcesUnsubscribe(ctx, req)
+	case MethodPromptsList:
+		return s.handlePromptsList(req)
+	case MethodPromptsGet:
+		return s.handlePromptsGet(ctx, req)
 	default:
 		s.logger.Warn("Unknown method requested", "method", req.Method)
 		return NewErrorResponse(req.ID, MethodNotFound, fmt.Sprintf("method not found: %s", req.Method), nil)
@@ -209,7 +483,7 @@ func (s *Server) handleRequest(ctx context.Context, req *Request) *Response {
 }
 
 // handleInitialize handles the initialize request.
-func (s *Server) handleInitialize(req *Request) *Response {
+func (s *Server) handleInitialize(ctx context.Context, req *Request) *Response {
 	var params InitializeParams
 	if req.Params != nil {
 		if err := json.Unmarshal(req.Params, &params); err != nil {
@@ -217,10 +491,19 @@ func (s *Server) handleInitialize(req *Request) *Response {
 		}
 	}
 
+	sess := s.sessionFor(ctx)
+	sess.setInitializeParams(&params)
+	version := sess.ProtocolVersion()
+
 	s.logger.Info("MCP client connected",
+		"session_id", sess.ID(),
 		"client_name", params.ClientInfo.Name,
 		"client_version", params.ClientInfo.Version,
-		"protocol_version", params.ProtocolVersion)
+		"protocol_version", version)
+	if version != params.ProtocolVersion {
+		s.logger.Warn("Unsupported protocol version requested, offering newest",
+			"session_id", sess.ID(), "requested", params.ProtocolVersion, "offered", version)
+	}
 
 	// DEBUG: Log client capabilities
 	s.logger.Debug("Client info",
@@ -229,15 +512,19 @@ func (s *Server) handleInitialize(req *Request) *Response {
 		"protocol", params.ProtocolVersion)
 
 	result := InitializeResult{
-		ProtocolVersion: ProtocolVersion,
+		ProtocolVersion: version,
 		Capabilities: ServerCapabilities{
 			Tools: &ToolsCapability{
-				ListChanged: false,
+				ListChanged: s.registry.hasToolsets(),
 			},
 			Resources: &ResourcesCapability{
-				Subscribe:   false,
+				Subscribe:   s.supportsSubscriptions(),
+				ListChanged: false,
+			},
+			Prompts: &PromptsCapability{
 				ListChanged: false,
 			},
+			Logging: &LoggingCapability{},
 		},
 		ServerInfo: Implementation{
 			Name:    ServerName,
@@ -253,12 +540,12 @@ func (s *Server) handleInitialize(req *Request) *Response {
 // Per JSON-RPC 2.0 spec section 4.1, notifications (requests without id) MUST NOT
 // receive a response. However, some MCP clients incorrectly send initialized as a
 // request with an id, so we handle both cases for compatibility.
-func (s *Server) handleInitialized(req *Request) *Response {
-	s.mu.Lock()
-	s.initialized = true
-	s.mu.Unlock()
+func (s *Server) handleInitialized(ctx context.Context, req *Request) *Response {
+	sess := s.sessionFor(ctx)
+	sess.markInitialized()
+	s.clientInitialized.Store(true)
 
-	s.logger.Info("MCP client initialization complete")
+	s.logger.Info("MCP client initialization complete", "session_id", sess.ID())
 
 	// Notifications (no id) must not receive a response per JSON-RPC 2.0 spec
 	if req.ID == nil {
@@ -269,18 +556,55 @@ func (s *Server) handleInitialized(req *Request) *Response {
 	return NewSuccessResponse(req.ID, struct{}{})
 }
 
+// handleCancelled handles notifications/cancelled by canceling the matching in-flight request.
+func (s *Server) handleCancelled(ctx context.Context, req *Request) *Response {
+	var params CancelledParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.RequestID == nil {
+		s.logger.Warn("Invalid cancellation notification", "error", err)
+		return nil
+	}
+
+	if s.sessionFor(ctx).cancelRequest(params.RequestID) {
+		s.logger.Info("Request cancelled", logAttrs(ctx, "id", formatID(params.RequestID), "reason", params.Reason)...)
+	} else {
+		s.logger.Debug("Cancellation for unknown or finished request", "id", formatID(params.RequestID))
+	}
+
+	return nil // Notifications don't get responses
+}
+
 // handlePing handles ping requests.
 func (s *Server) handlePing(req *Request) *Response {
 	s.logger.Debug("Ping received")
 	return NewSuccessResponse(req.ID, PingResult{})
 }
 
-// handleToolsList handles tools/list requests.
-func (s *Server) handleToolsList(req *Request) *Response {
-	tools := s.registry.ListTools()
+// handleToolsList handles tools/list requests. Only tools enabled for the
+// session are listed, and output schemas only for clients that negotiated a
+// protocol version with structured tool output.
+func (s *Server) handleToolsList(ctx context.Context, req *Request) *Response {
+	cursor, err := listCursor(req)
+	if err != nil {
+		return NewErrorResponse(req.ID, InvalidParams, "invalid tools/list params", err.Error())
+	}
+
+	sess := s.sessionFor(ctx)
+	tools := s.registry.ListToolsFor(sess)
+	slices.SortFunc(tools, func(a, b Tool) int { return strings.Compare(a.Name, b.Name) })
+	tools, next, err := PageAfter(tools, func(t Tool) string { return t.Name }, cursor, s.pageSize)
+	if err != nil {
+		return NewErrorResponse(req.ID, InvalidParams, err.Error(), nil)
+	}
+
+	if !sess.supports(structuredOutputVersion) {
+		for i := range tools {
+			tools[i].OutputSchema = nil
+		}
+	}
 	s.logger.Debug("Listed tools", "count", len(tools))
 	result := ToolsListResult{
-		Tools: tools,
+		Tools:      tools,
+		NextCursor: nextCursor(next),
 	}
 	return NewSuccessResponse(req.ID, result)
 }
@@ -292,38 +616,143 @@ func (s *Server) handleToolsCall(ctx context.Context, req *Request) *Response {
 		return NewErrorResponse(req.ID, InvalidParams, "invalid tools/call params", err.Error())
 	}
 
-	s.logger.Info("Tool call", "tool", params.Name)
+	s.logger.InfoContext(ctx, "Tool call", logAttrs(ctx, "tool", params.Name)...)
 
-	// DEBUG: Log tool arguments summary
-	if s.logger.IsDebugEnabled() {
-		argSummary := summarizeArguments(params.Arguments)
-		s.logger.Debug("Tool arguments", "summary", argSummary)
+	s.logToolArguments(params.Arguments)
+
+	handler, errResp := s.checkToolCall(ctx, req, &params)
+	if errResp != nil {
+		return errResp
 	}
 
-	// TRACE: Log full arguments
-	if s.logger.IsTraceEnabled() {
-		argsJSON, err := json.MarshalIndent(params.Arguments, "", "  ")
-		if err == nil {
-			s.logger.Trace("Tool call arguments", "arguments", string(argsJSON))
-		}
+	if params.Meta != nil && params.Meta.ProgressToken != nil {
+		ctx = withProgressReporter(ctx, newProgressReporter(ctx, params.Meta.ProgressToken))
 	}
 
-	handler, exists := s.registry.GetHandler(params.Name)
-	if !exists {
-		s.logger.Warn("Tool not found", "tool", params.Name)
-		return NewErrorResponse(req.ID, ToolNotFound, fmt.Sprintf("tool not found: %s", params.Name), nil)
+	// Track the call so that notifications/cancelled can stop it
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	sess := s.sessionFor(ctx)
+	if req.ID != nil {
+		sess.trackRequest(req.ID, cancel)
+		defer sess.untrackRequest(req.ID)
 	}
 
-	result, err := handler(ctx, s.haClient, params.Arguments)
+	// Confirmation may wait for the user, so it happens before taking a command slot
+	if resp, ok := s.confirmToolCall(ctx, req, &params); !ok {
+		return resp
+	}
+
+	release, err := s.acquireCommandSlot(ctx)
 	if err != nil {
-		s.logger.Error("Tool execution failed", "tool", params.Name, "error", err)
+		if errors.Is(err, errRequestCancelled) {
+			s.logger.InfoContext(ctx, "Tool call cancelled", logAttrs(ctx, "tool", params.Name, "id", formatID(req.ID))...)
+			return nil
+		}
+		return NewErrorResponse(req.ID, ToolExecutionErr, fmt.Sprintf("tool execution failed: %s", err.Error()), nil)
+	}
+	start := time.Now()
+	result, err := s.runTool(ctx, handler, &params)
+	release()
+	s.observeToolCall(params.Name, result, err, time.Since(start))
+	if errors.Is(context.Cause(ctx), errRequestCancelled) {
+		// The client is no longer interested in the result, so none is sent
+		s.logger.InfoContext(ctx, "Tool call cancelled", logAttrs(ctx, "tool", params.Name, "id", formatID(req.ID))...)
+		return nil
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Tool execution failed", logAttrs(ctx, "tool", params.Name, "error", err)...)
 		return NewErrorResponse(req.ID, ToolExecutionErr, fmt.Sprintf("tool execution failed: %s", err.Error()), nil)
 	}
 
-	s.logger.Debug("Tool call successful", "tool", params.Name)
+	return s.toolCallResponse(ctx, req, params.Name, result)
+}
+
+// toolCallResponse logs the result of a tool call and returns its response,
+// leaving out what the session's protocol version does not support.
+func (s *Server) toolCallResponse(ctx context.Context, req *Request, name string, result *ToolsCallResult) *Response {
+	if result != nil && !s.sessionFor(ctx).supports(structuredOutputVersion) {
+		result.StructuredContent = nil
+	}
+
+	if result != nil && result.IsError {
+		// Surface the underlying error (e.g. a failed WS command) to clients that enabled logging
+		s.logger.WarnContext(ctx, "Tool returned an error", logAttrs(ctx, "tool", name, "error", resultText(result))...)
+	} else {
+		s.logger.DebugContext(ctx, "Tool call successful", "tool", name)
+	}
 	return NewSuccessResponse(req.ID, result)
 }
 
+// checkToolCall looks up the handler of a tool call and checks that the call
+// may run: the tool is enabled for the session, the arguments are valid, and
+// neither the tool policy nor a rate limit rejects it. If the call may not
+// run, it returns the error response.
+func (s *Server) checkToolCall(ctx context.Context, req *Request, params *ToolsCallParams) (ToolHandler, *Response) {
+	handler, exists := s.registry.GetHandler(params.Name)
+	if !exists {
+		s.logger.WarnContext(ctx, "Tool not found", logAttrs(ctx, "tool", params.Name)...)
+		return nil, NewErrorResponse(req.ID, ToolNotFound, fmt.Sprintf("tool not found: %s", params.Name), nil)
+	}
+
+	if toolset, disabled := s.registry.disabledToolset(s.sessionFor(ctx), params.Name); disabled {
+		s.logger.WarnContext(ctx, "Tool in disabled toolset", logAttrs(ctx, "tool", params.Name, "toolset", toolset)...)
+		return nil, NewErrorResponse(req.ID, ToolNotFound,
+			fmt.Sprintf("tool %s belongs to toolset %s, which is not enabled for this session", params.Name, toolset), nil)
+	}
+
+	if err := s.registry.ValidateArguments(params.Name, params.Arguments); err != nil {
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			return nil, NewErrorResponse(req.ID, InvalidParams, err.Error(), nil)
+		}
+		// Point into the tools/call params rather than the arguments object
+		verr = &ValidationError{Pointer: "/arguments" + verr.Pointer, Message: verr.Message}
+		s.logger.WarnContext(ctx, "Invalid tool arguments", logAttrs(ctx, "tool", params.Name, "error", verr)...)
+		return nil, NewErrorResponse(req.ID, InvalidParams,
+			fmt.Sprintf("invalid arguments for %s: %s", params.Name, verr.Error()), verr)
+	}
+
+	if err := s.registry.CheckToolCall(params.Name, params.Arguments); err != nil {
+		s.logger.WarnContext(ctx, "Tool call not allowed", logAttrs(ctx, "tool", params.Name, "error", err)...)
+		return nil, NewErrorResponse(req.ID, ToolNotAllowed, fmt.Sprintf("tool call not allowed: %s", err.Error()), nil)
+	}
+
+	if rlErr := s.checkRateLimit(ctx, params.Name); rlErr != nil {
+		s.logger.WarnContext(ctx, "Tool call rate limited",
+			logAttrs(ctx, "tool", params.Name, "limit", rlErr.Limit, "retry_after", rlErr.RetryAfter)...)
+		return nil, NewErrorResponse(req.ID, RateLimited, rateLimitMessage(params.Name, rlErr), rlErr)
+	}
+
+	return handler, nil
+}
+
+// resultText returns the text content of a tool result.
+func resultText(result *ToolsCallResult) string {
+	texts := make([]string, 0, len(result.Content))
+	for _, block := range result.Content {
+		if block.Text != "" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// logToolArguments logs a summary of tool arguments at DEBUG level and the
+// full arguments at TRACE level.
+func (s *Server) logToolArguments(args map[string]any) {
+	if s.logger.IsDebugEnabled() {
+		s.logger.Debug("Tool arguments", "summary", summarizeArguments(args))
+	}
+
+	if s.logger.IsTraceEnabled() {
+		argsJSON, err := json.MarshalIndent(args, "", "  ")
+		if err == nil {
+			s.logger.Trace("Tool call arguments", "arguments", string(argsJSON))
+		}
+	}
+}
+
 // summarizeArguments creates a brief summary of tool arguments for DEBUG logging.
 func summarizeArguments(args map[string]any) string {
 	if len(args) == 0 {
@@ -341,12 +770,34 @@ func summarizeArguments(args map[string]any) string {
 	return fmt.Sprintf("keys=%v... (%d total)", keys[:3], len(keys))
 }
 
-// handleResourcesList handles resources/list requests.
-func (s *Server) handleResourcesList(req *Request) *Response {
+// handleResourcesList handles resources/list requests. Besides the registered
+// resources, it lists the concrete resources enumerated by resource templates.
+// A failing lister is logged and skipped so that the other resources are still listed.
+func (s *Server) handleResourcesList(ctx context.Context, req *Request) *Response {
+	cursor, err := listCursor(req)
+	if err != nil {
+		return NewErrorResponse(req.ID, InvalidParams, "invalid resources/list params", err.Error())
+	}
+
 	resources := s.registry.ListResources()
+	for _, lister := range s.registry.resourceListers() {
+		listed, err := lister(ctx, s.haClient)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to list resources", logAttrs(ctx, "error", err)...)
+			continue
+		}
+		resources = append(resources, listed...)
+	}
+	slices.SortFunc(resources, func(a, b Resource) int { return strings.Compare(a.URI, b.URI) })
+	resources, next, err := PageAfter(resources, func(r Resource) string { return r.URI }, cursor, s.pageSize)
+	if err != nil {
+		return NewErrorResponse(req.ID, InvalidParams, err.Error(), nil)
+	}
+
 	s.logger.Debug("Listed resources", "count", len(resources))
 	result := ResourcesListResult{
-		Resources: resources,
+		Resources:  resources,
+		NextCursor: nextCursor(next),
 	}
 	return NewSuccessResponse(req.ID, result)
 }
@@ -366,7 +817,12 @@ func (s *Server) handleResourcesRead(ctx context.Context, req *Request) *Respons
 		return NewErrorResponse(req.ID, ResourceNotFound, fmt.Sprintf("resource not found: %s", params.URI), nil)
 	}
 
+	release, err := s.acquireCommandSlot(ctx)
+	if err != nil {
+		return NewErrorResponse(req.ID, InternalError, fmt.Sprintf("resource read failed: %s", err.Error()), nil)
+	}
 	result, err := handler(ctx, s.haClient, params.URI)
+	release()
 	if err != nil {
 		s.logger.Error("Resource read failed", "uri", params.URI, "error", err)
 		return NewErrorResponse(req.ID, InternalError, fmt.Sprintf("resource read failed: %s", err.Error()), nil)
@@ -376,9 +832,79 @@ func (s *Server) handleResourcesRead(ctx context.Context, req *Request) *Respons
 	return NewSuccessResponse(req.ID, result)
 }
 
-// writeResponse writes a JSON-RPC response.
+// handleResourcesTemplatesList handles resources/templates/list requests.
+func (s *Server) handleResourcesTemplatesList(req *Request) *Response {
+	templates := s.registry.ListResourceTemplates()
+	s.logger.Debug("Listed resource templates", "count", len(templates))
+	result := ResourcesTemplatesListResult{
+		ResourceTemplates: templates,
+	}
+	return NewSuccessResponse(req.ID, result)
+}
+
+// handlePromptsList handles prompts/list requests.
+func (s *Server) handlePromptsList(req *Request) *Response {
+	prompts := s.registry.ListPrompts()
+	s.logger.Debug("Listed prompts", "count", len(prompts))
+	result := PromptsListResult{
+		Prompts: prompts,
+	}
+	return NewSuccessResponse(req.ID, result)
+}
+
+// handlePromptsGet handles prompts/get requests.
+func (s *Server) handlePromptsGet(ctx context.Context, req *Request) *Response {
+	var params PromptsGetParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return NewErrorResponse(req.ID, InvalidParams, "invalid prompts/get params", err.Error())
+	}
+
+	s.logger.InfoContext(ctx, "Prompt requested", logAttrs(ctx, "prompt", params.Name)...)
+
+	prompt, handler, exists := s.registry.GetPrompt(params.Name)
+	if !exists {
+		s.logger.Warn("Prompt not found", "prompt", params.Name)
+		return NewErrorResponse(req.ID, InvalidParams, fmt.Sprintf("prompt not found: %s", params.Name), nil)
+	}
+
+	if missing := missingPromptArguments(prompt, params.Arguments); len(missing) > 0 {
+		return NewErrorResponse(req.ID, InvalidParams,
+			fmt.Sprintf("missing required arguments: %s", strings.Join(missing, ", ")), nil)
+	}
+
+	if params.Arguments == nil {
+		params.Arguments = make(map[string]string)
+	}
+
+	release, err := s.acquireCommandSlot(ctx)
+	if err != nil {
+		return NewErrorResponse(req.ID, InternalError, fmt.Sprintf("prompt failed: %s", err.Error()), nil)
+	}
+	result, err := handler(ctx, s.haClient, params.Arguments)
+	release()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Prompt failed", logAttrs(ctx, "prompt", params.Name, "error", err)...)
+		return NewErrorResponse(req.ID, InternalError, fmt.Sprintf("prompt failed: %s", err.Error()), nil)
+	}
+
+	return NewSuccessResponse(req.ID, result)
+}
+
+// missingPromptArguments returns the names of required prompt arguments that
+// are absent or empty.
+func missingPromptArguments(prompt Prompt, args map[string]string) []string {
+	var missing []string
+	for _, arg := range prompt.Arguments {
+		if arg.Required && strings.TrimSpace(args[arg.Name]) == "" {
+			missing = append(missing, arg.Name)
+		}
+	}
+	return missing
+}
+
+// writeResponse writes a JSON-RPC response, or an array of responses for a batch.
 // For notifications (nil response), no response is written per JSON-RPC 2.0 spec.
-func (s *Server) writeResponse(w http.ResponseWriter, resp *Response) {
+func (s *Server) writeResponse(w http.ResponseWriter, resp any) {
 	if resp == nil {
 		return // Notifications don't get responses
 	}
@@ -403,11 +929,26 @@ func (s *Server) writeError(w http.ResponseWriter, id json.RawMessage, code Erro
 	s.writeResponse(w, resp)
 }
 
-// IsInitialized returns whether the server has been initialized by a client.
+// writeErrorStatus writes a JSON-RPC error response with the given HTTP status code.
+func (s *Server) writeErrorStatus(w http.ResponseWriter, status int, id json.RawMessage, code ErrorCode, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(NewErrorResponse(id, code, message, nil)); err != nil {
+		s.logger.Error("Failed to write response", "error", err)
+	}
+}
+
+// sessionFor returns the session carried by ctx, or the default session.
+func (s *Server) sessionFor(ctx context.Context) *Session {
+	if sess, ok := SessionFromContext(ctx); ok {
+		return sess
+	}
+	return s.sessions.defaultSession()
+}
+
+// IsInitialized returns whether at least one client has completed initialization.
 func (s *Server) IsInitialized() bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.initialized
+	return s.clientInitialized.Load()
 }
 
 // HAClient returns the Home Assistant client for use by handlers.
@@ -150,6 +150,14 @@ func (m *mockHAClient) GetLovelaceConfig(_ context.Context) (map[string]any, err
 	return nil, nil
 }
 
+func (m *mockHAClient) GetDashboardConfig(_ context.Context, _ string) (map[string]any, error) {
+	return nil, nil
+}
+
+func (m *mockHAClient) ListDashboards(_ context.Context) ([]homeassistant.Dashboard, error) {
+	return nil, nil
+}
+
 func (m *mockHAClient) GetStatistics(_ context.Context, _ []string, _ string) ([]homeassistant.StatisticsResult, error) {
 	return nil, nil
 }
@@ -389,8 +397,9 @@ func TestServer_HandleInitialize(t *testing.T) {
 		t.Fatalf("json.Unmarshal() error = %v", err)
 	}
 
-	if result.ProtocolVersion != ProtocolVersion {
-		t.Errorf("ProtocolVersion = %q, want %q", result.ProtocolVersion, ProtocolVersion)
+	// A supported version requested by the client is accepted
+	if result.ProtocolVersion != "2024-11-05" {
+		t.Errorf("ProtocolVersion = %q, want %q", result.ProtocolVersion, "2024-11-05")
 	}
 	if result.ServerInfo.Name != ServerName {
 		t.Errorf("ServerInfo.Name = %q, want %q", result.ServerInfo.Name, ServerName)
@@ -1137,8 +1146,8 @@ func TestServer_Constants(t *testing.T) {
 	if ServerVersion != "1.0.0" {
 		t.Errorf("ServerVersion = %q, want %q", ServerVersion, "1.0.0")
 	}
-	if ProtocolVersion != "2024-11-05" {
-		t.Errorf("ProtocolVersion = %q, want %q", ProtocolVersion, "2024-11-05")
+	if ProtocolVersion != "2025-11-25" {
+		t.Errorf("ProtocolVersion = %q, want %q", ProtocolVersion, "2025-11-25")
 	}
 }
 
@@ -1173,3 +1182,273 @@ func TestServer_HandleInitialize_NilParams(t *testing.T) {
 		t.Fatalf("Unexpected error: %+v", jsonResp.Error)
 	}
 }
+
+func TestServer_CancelToolCall(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "slow"},
+		func(ctx context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+	ctx := withSession(context.Background(), s.sessions.defaultSession())
+
+	done := make(chan *Response, 1)
+	go func() {
+		done <- s.handleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`42`),
+			Method:  MethodToolsCall,
+			Params:  json.RawMessage(`{"name":"slow"}`),
+		})
+	}()
+	<-started
+
+	resp := s.handleRequest(ctx, &Request{
+		JSONRPC: JSONRPCVersion,
+		Method:  MethodCanceled,
+		Params:  json.RawMessage(`{"requestId":42,"reason":"user gave up"}`),
+	})
+	if resp != nil {
+		t.Errorf("cancellation notification response = %+v, want nil", resp)
+	}
+
+	select {
+	case resp := <-done:
+		if resp != nil {
+			t.Errorf("cancelled tool call response = %+v, want nil", resp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("tool call did not stop after cancellation")
+	}
+}
+
+func TestServer_CancelUnknownRequest(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logging.New(logging.LevelOff))
+
+	for _, params := range []string{`{"requestId":7}`, `{}`, `not json`} {
+		resp := s.handleRequest(context.Background(), &Request{
+			JSONRPC: JSONRPCVersion,
+			Method:  MethodCanceled,
+			Params:  json.RawMessage(params),
+		})
+		if resp != nil {
+			t.Errorf("params %s: response = %+v, want nil", params, resp)
+		}
+	}
+}
+
+func TestServer_HandlePromptsList(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterPrompt(Prompt{Name: "a"}, nil)
+	registry.RegisterPrompt(Prompt{Name: "b"}, nil)
+
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	resp := s.handleRequest(context.Background(), &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodPromptsList,
+	})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+
+	result, ok := resp.Result.(PromptsListResult)
+	if !ok {
+		t.Fatalf("Result type = %T, want PromptsListResult", resp.Result)
+	}
+	if len(result.Prompts) != 2 {
+		t.Errorf("len(Prompts) = %d, want 2", len(result.Prompts))
+	}
+}
+
+func TestServer_HandlePromptsGet(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterPrompt(
+		Prompt{
+			Name: "greet",
+			Arguments: []PromptArgument{
+				{Name: "name", Required: true},
+				{Name: "greeting"},
+			},
+		},
+		func(_ context.Context, _ homeassistant.Client, args map[string]string) (*PromptsGetResult, error) {
+			if args["name"] == "fail" {
+				return nil, errors.New("boom")
+			}
+			return &PromptsGetResult{
+				Messages: []PromptMessage{NewUserPromptMessage("Hello " + args["name"] + args["greeting"])},
+			}, nil
+		},
+	)
+
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	tests := []struct {
+		name     string
+		params   string
+		wantCode ErrorCode
+		wantText string
+	}{
+		{
+			name:     "renders prompt",
+			params:   `{"name":"greet","arguments":{"name":"Ada"}}`,
+			wantText: "Hello Ada",
+		},
+		{
+			name:     "unknown prompt",
+			params:   `{"name":"missing"}`,
+			wantCode: InvalidParams,
+		},
+		{
+			name:     "missing required argument",
+			params:   `{"name":"greet","arguments":{"greeting":"!"}}`,
+			wantCode: InvalidParams,
+		},
+		{
+			name:     "blank required argument",
+			params:   `{"name":"greet","arguments":{"name":"  "}}`,
+			wantCode: InvalidParams,
+		},
+		{
+			name:     "handler error",
+			params:   `{"name":"greet","arguments":{"name":"fail"}}`,
+			wantCode: InternalError,
+		},
+		{
+			name:     "invalid params",
+			params:   `[]`,
+			wantCode: InvalidParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := s.handleRequest(context.Background(), &Request{
+				JSONRPC: JSONRPCVersion,
+				ID:      json.RawMessage(`1`),
+				Method:  MethodPromptsGet,
+				Params:  json.RawMessage(tt.params),
+			})
+
+			if tt.wantCode != 0 {
+				if resp.Error == nil || resp.Error.Code != tt.wantCode {
+					t.Fatalf("Error = %+v, want code %d", resp.Error, tt.wantCode)
+				}
+				return
+			}
+
+			if resp.Error != nil {
+				t.Fatalf("Unexpected error: %+v", resp.Error)
+			}
+			result, ok := resp.Result.(*PromptsGetResult)
+			if !ok {
+				t.Fatalf("Result type = %T, want *PromptsGetResult", resp.Result)
+			}
+			if len(result.Messages) != 1 || result.Messages[0].Content.Text != tt.wantText {
+				t.Errorf("Messages = %+v, want single message %q", result.Messages, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestServer_HandleResourcesList_Templates(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterResource(Resource{URI: "test://static", Name: "Static"}, nil)
+	registry.RegisterResourceTemplate(
+		ResourceTemplate{URITemplate: "test://item/{id}", Name: "Item"},
+		nil,
+		func(_ context.Context, _ homeassistant.Client) ([]Resource, error) {
+			return []Resource{{URI: "test://item/1", Name: "One"}}, nil
+		},
+	)
+	registry.RegisterResourceTemplate(
+		ResourceTemplate{URITemplate: "test://broken/{id}", Name: "Broken"},
+		nil,
+		func(_ context.Context, _ homeassistant.Client) ([]Resource, error) {
+			return nil, errors.New("unavailable")
+		},
+	)
+	registry.RegisterResourceTemplate(ResourceTemplate{URITemplate: "test://unlisted/{id}", Name: "Unlisted"}, nil, nil)
+
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	resp := s.handleRequest(context.Background(), &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodResourcesList,
+	})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+	result, ok := resp.Result.(ResourcesListResult)
+	if !ok {
+		t.Fatalf("Result type = %T, want ResourcesListResult", resp.Result)
+	}
+	uris := make([]string, 0, len(result.Resources))
+	for _, r := range result.Resources {
+		uris = append(uris, r.URI)
+	}
+	if want := []string{"test://item/1", "test://static"}; strings.Join(uris, ",") != strings.Join(want, ",") {
+		t.Errorf("resource URIs = %v, want %v", uris, want)
+	}
+
+	resp = s.handleRequest(context.Background(), &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`2`),
+		Method:  MethodResourcesTmpl,
+	})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+	templates, ok := resp.Result.(ResourcesTemplatesListResult)
+	if !ok {
+		t.Fatalf("Result type = %T, want ResourcesTemplatesListResult", resp.Result)
+	}
+	if len(templates.ResourceTemplates) != 3 {
+		t.Errorf("len(ResourceTemplates) = %d, want 3", len(templates.ResourceTemplates))
+	}
+}
+
+func TestServer_HandleToolsCall_NotAllowed(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	registry := NewRegistry()
+	registry.SetToolFilter(prefixFilter{prefix: "get_"})
+	registry.RegisterTool(Tool{Name: "get_state", InputSchema: JSONSchema{Type: "object"}},
+		func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			called = true
+			return &ToolsCallResult{}, nil
+		})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	resp := s.handleRequest(context.Background(), &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodToolsCall,
+		Params:  json.RawMessage(`{"name":"get_state","arguments":{"deny":true}}`),
+	})
+
+	if called {
+		t.Error("handler called despite denied call")
+	}
+	if resp.Error == nil || resp.Error.Code != ToolNotAllowed {
+		t.Fatalf("Error = %+v, want ToolNotAllowed", resp.Error)
+	}
+}
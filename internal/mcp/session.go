@@ -0,0 +1,519 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SessionIDHeader is the HTTP header carrying the MCP session ID (Streamable HTTP transport).
+const SessionIDHeader = "Mcp-Session-Id"
+
+// sessionIdleTimeout is how long a session may go unused before it is pruned.
+const sessionIdleTimeout = 1 * time.Hour
+
+// Session errors.
+var (
+	// errNoStream is returned when a message cannot be delivered because no stream is open.
+	errNoStream = errors.New("no open stream for session")
+	// errRequestCancelled is the cancellation cause of requests canceled by the client.
+	errRequestCancelled = errors.New("request cancelled by client")
+	// errSessionEnded is the cancellation cause of requests whose session ended.
+	errSessionEnded = errors.New("session ended")
+)
+
+// messageSink delivers server-to-client JSON-RPC messages over a transport.
+type messageSink interface {
+	send(msg any) error
+}
+
+// Session holds the protocol state of a single connected MCP client.
+// Each HTTP client gets its own session at initialize, and each HTTP request
+// without an Mcp-Session-Id header gets an ephemeral one. The stdio transport
+// uses the default session.
+type Session struct {
+	id        string
+	apiKey    string // name of the API key that created the session
+	ephemeral bool   // serves a single request of a client without a session
+
+	mu              sync.RWMutex
+	initialized     bool
+	clientInfo      Implementation
+	clientCaps      ClientCapabilities
+	protocolVersion string // negotiated at initialize
+	lastSeen        time.Time
+	sink            messageSink
+	inFlight        map[string]context.CancelCauseFunc
+	logLevel        *slog.Level
+	subscriptions   map[string]struct{}
+	toolsets        map[string]struct{} // enabled during the session
+	lastRequestID   int64               // of requests sent to the client
+	pending         map[string]chan *clientResponse
+}
+
+// clientResponse is the client's response to a request sent by the server.
+type clientResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *ErrorObject    `json:"error,omitempty"`
+}
+
+// newSession creates a session with the given ID.
+func newSession(id string) *Session {
+	return &Session{
+		id:       id,
+		lastSeen: time.Now(),
+	}
+}
+
+// newEphemeralSession creates a session for a single HTTP request without a
+// session ID. Its state is dropped when the request ends.
+func newEphemeralSession() *Session {
+	sess := newSession("")
+	sess.ephemeral = true
+	return sess
+}
+
+// ID returns the session ID. The default session has an empty ID.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// IsInitialized returns whether the client has sent notifications/initialized.
+func (s *Session) IsInitialized() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.initialized
+}
+
+// ClientInfo returns the client implementation reported at initialize.
+func (s *Session) ClientInfo() Implementation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clientInfo
+}
+
+// ProtocolVersion returns the protocol version negotiated at initialize, or the
+// version assumed for clients that did not initialize.
+func (s *Session) ProtocolVersion() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.protocolVersion == "" {
+		return defaultProtocolVersion
+	}
+	return s.protocolVersion
+}
+
+// supports reports whether the negotiated protocol version includes features
+// introduced in the given revision.
+func (s *Session) supports(revision string) bool {
+	return protocolAtLeast(s.ProtocolVersion(), revision)
+}
+
+// canElicit reports whether the client accepts elicitation/create requests.
+func (s *Session) canElicit() bool {
+	s.mu.RLock()
+	declared := s.clientCaps.Elicitation != nil
+	s.mu.RUnlock()
+	return declared && s.supports(elicitationVersion)
+}
+
+// setInitializeParams records the client's initialize parameters and
+// negotiates the protocol version.
+func (s *Session) setInitializeParams(params *InitializeParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clientInfo = params.ClientInfo
+	s.clientCaps = params.Capabilities
+	s.protocolVersion = negotiateProtocolVersion(params.ProtocolVersion)
+}
+
+// markInitialized records that the client completed initialization.
+func (s *Session) markInitialized() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initialized = true
+}
+
+// touch records activity on the session.
+func (s *Session) touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSeen = time.Now()
+}
+
+// idleSince returns the time of the last activity on the session.
+func (s *Session) idleSince() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastSeen
+}
+
+// isActive reports whether the session has an open stream or requests in progress.
+func (s *Session) isActive() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sink != nil || len(s.inFlight) > 0
+}
+
+// end closes the session's GET stream, if one is open, and cancels its
+// requests in progress.
+func (s *Session) end() {
+	s.mu.Lock()
+	sink := s.sink
+	s.sink = nil
+	inFlight := s.inFlight
+	s.inFlight = nil
+	s.mu.Unlock()
+
+	if stream, ok := sink.(*sessionStream); ok {
+		stream.close()
+	}
+	for _, cancel := range inFlight {
+		cancel(errSessionEnded)
+	}
+}
+
+// attachSink sets the sink used for server-initiated messages and returns the previous one.
+func (s *Session) attachSink(sink messageSink) messageSink {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.sink
+	s.sink = sink
+	return prev
+}
+
+// detachSink clears the sink if it is still the given one.
+func (s *Session) detachSink(sink messageSink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sink == sink {
+		s.sink = nil
+	}
+}
+
+// setLogLevel sets the minimum level of log records forwarded to the client.
+func (s *Session) setLogLevel(level slog.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logLevel = &level
+}
+
+// clientLogLevel returns the level the client asked for, if any.
+func (s *Session) clientLogLevel() (slog.Level, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.logLevel == nil {
+		return 0, false
+	}
+	return *s.logLevel, true
+}
+
+// wantsLog reports whether the client asked for log records at the given level.
+func (s *Session) wantsLog(level slog.Level) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.logLevel != nil && level >= *s.logLevel
+}
+
+// subscribe records a subscription to the resource with the given URI.
+func (s *Session) subscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscriptions == nil {
+		s.subscriptions = make(map[string]struct{})
+	}
+	s.subscriptions[uri] = struct{}{}
+}
+
+// unsubscribe removes a subscription to the resource with the given URI.
+func (s *Session) unsubscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, uri)
+}
+
+// isSubscribed reports whether the client subscribed to the resource with the given URI.
+func (s *Session) isSubscribed(uri string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.subscriptions[uri]
+	return ok
+}
+
+// enableToolset enables a toolset for the session and reports whether it was not enabled before.
+func (s *Session) enableToolset(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.toolsets[name]; ok {
+		return false
+	}
+	if s.toolsets == nil {
+		s.toolsets = make(map[string]struct{})
+	}
+	s.toolsets[name] = struct{}{}
+	return true
+}
+
+// hasToolset reports whether the session enabled a toolset.
+func (s *Session) hasToolset(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.toolsets[name]
+	return ok
+}
+
+// trackRequest registers the cancel function of an in-flight request.
+func (s *Session) trackRequest(id json.RawMessage, cancel context.CancelCauseFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight == nil {
+		s.inFlight = make(map[string]context.CancelCauseFunc)
+	}
+	s.inFlight[string(id)] = cancel
+}
+
+// untrackRequest removes an in-flight request once it has finished.
+func (s *Session) untrackRequest(id json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, string(id))
+}
+
+// cancelRequest cancels the in-flight request with the given ID and reports
+// whether such a request was found.
+func (s *Session) cancelRequest(id json.RawMessage) bool {
+	s.mu.Lock()
+	cancel, ok := s.inFlight[string(id)]
+	delete(s.inFlight, string(id))
+	s.mu.Unlock()
+
+	if ok {
+		cancel(errRequestCancelled)
+	}
+	return ok
+}
+
+// Notify sends a JSON-RPC notification to the client over the session's stream.
+// It returns an error if no stream is open for the session.
+func (s *Session) Notify(method string, params any) error {
+	return s.send(NewNotification(method, params))
+}
+
+// send sends a message to the client over the session's stream.
+func (s *Session) send(msg any) error {
+	s.mu.RLock()
+	sink := s.sink
+	s.mu.RUnlock()
+
+	if sink == nil {
+		return errNoStream
+	}
+	return sink.send(msg)
+}
+
+// startRequest allocates the ID of a request to the client and returns it
+// with the channel its response is delivered to.
+func (s *Session) startRequest() (json.RawMessage, <-chan *clientResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRequestID++
+	id := json.RawMessage(strconv.FormatInt(s.lastRequestID, 10))
+	if s.pending == nil {
+		s.pending = make(map[string]chan *clientResponse)
+	}
+	replies := make(chan *clientResponse, 1)
+	s.pending[string(id)] = replies
+	return id, replies
+}
+
+// endRequest stops waiting for the response to a request to the client.
+func (s *Session) endRequest(id json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, string(id))
+}
+
+// deliverResponse hands a response from the client to the request waiting
+// for it and reports whether one was waiting.
+func (s *Session) deliverResponse(resp *clientResponse) bool {
+	s.mu.Lock()
+	replies, ok := s.pending[string(resp.ID)]
+	delete(s.pending, string(resp.ID))
+	s.mu.Unlock()
+
+	if ok {
+		replies <- resp
+	}
+	return ok
+}
+
+// sessionStore manages the sessions of a server.
+type sessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+	fallback *Session
+}
+
+// newSessionStore creates an empty session store with a default session.
+func newSessionStore() *sessionStore {
+	return &sessionStore{
+		sessions: make(map[string]*Session),
+		fallback: newSession(""),
+	}
+}
+
+// create creates and registers a new session with a random ID for the client
+// authenticated with the named API key.
+// Sessions that have been idle for longer than sessionIdleTimeout and have
+// neither an open stream nor requests in progress are pruned.
+func (st *sessionStore) create(apiKey string) (*Session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	sess := newSession(id)
+	sess.apiKey = apiKey
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.pruneLocked(time.Now().Add(-sessionIdleTimeout))
+	st.sessions[id] = sess
+	return sess, nil
+}
+
+// get returns the session with the given ID.
+func (st *sessionStore) get(id string) (*Session, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	sess, ok := st.sessions[id]
+	return sess, ok
+}
+
+// remove deletes the session with the given ID and reports whether it existed.
+func (st *sessionStore) remove(id string) (*Session, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sess, ok := st.sessions[id]
+	delete(st.sessions, id)
+	return sess, ok
+}
+
+// defaultSession returns the session of the stdio transport.
+func (st *sessionStore) defaultSession() *Session {
+	return st.fallback
+}
+
+// all returns the default session followed by all registered sessions.
+func (st *sessionStore) all() []*Session {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	sessions := make([]*Session, 0, len(st.sessions)+1)
+	sessions = append(sessions, st.fallback)
+	for _, sess := range st.sessions {
+		sessions = append(sessions, sess)
+	}
+	return sessions
+}
+
+// pruneLocked removes and ends inactive sessions idle since before cutoff
+// (must hold lock). A client listening on a long-lived stream is not idle.
+func (st *sessionStore) pruneLocked(cutoff time.Time) {
+	for id, sess := range st.sessions {
+		if sess.idleSince().Before(cutoff) && !sess.isActive() {
+			delete(st.sessions, id)
+			sess.end()
+		}
+	}
+}
+
+// newSessionID generates a cryptographically random session ID.
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating session ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// sessionContextKey is the context key for the current session.
+type sessionContextKey struct{}
+
+// requestSinkContextKey is the context key for the request-scoped message sink.
+type requestSinkContextKey struct{}
+
+// withSession returns a context carrying the given session.
+func withSession(ctx context.Context, sess *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, sess)
+}
+
+// SessionFromContext returns the session handling the current request, if any.
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	sess, ok := ctx.Value(sessionContextKey{}).(*Session)
+	return sess, ok && sess != nil
+}
+
+// withRequestSink returns a context carrying a sink for messages related to the current request.
+func withRequestSink(ctx context.Context, sink messageSink) context.Context {
+	return context.WithValue(ctx, requestSinkContextKey{}, sink)
+}
+
+// notifyContext sends a notification related to the current request.
+// It prefers the request's own stream (an upgraded POST response) and falls
+// back to the session's stream.
+func notifyContext(ctx context.Context, method string, params any) error {
+	return sendContext(ctx, NewNotification(method, params))
+}
+
+// sendContext sends a message related to the current request, preferring the
+// request's own stream like notifyContext.
+func sendContext(ctx context.Context, msg any) error {
+	if sink, ok := ctx.Value(requestSinkContextKey{}).(messageSink); ok && sink != nil {
+		err := sink.send(msg)
+		if !errors.Is(err, errNoStream) {
+			return err
+		}
+	}
+
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return errNoStream
+	}
+	return sess.send(msg)
+}
+
+// requestContext sends a request related to the current request to the client
+// and waits for its result. It gives up when ctx is done.
+func requestContext(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, errNoStream
+	}
+
+	id, replies := sess.startRequest()
+	defer sess.endRequest(id)
+
+	if err := sendContext(ctx, NewServerRequest(id, method, params)); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-replies:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s failed: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
@@ -0,0 +1,298 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingSink is a messageSink that records sent messages.
+type recordingSink struct {
+	messages []any
+	err      error
+}
+
+func (r *recordingSink) send(msg any) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func TestSessionStore_CreateGetRemove(t *testing.T) {
+	t.Parallel()
+
+	store := newSessionStore()
+
+	sess, err := store.create("")
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	if len(sess.ID()) != 32 {
+		t.Errorf("ID() length = %d, want 32", len(sess.ID()))
+	}
+
+	other, err := store.create("")
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	if other.ID() == sess.ID() {
+		t.Error("create() returned duplicate session IDs")
+	}
+
+	got, ok := store.get(sess.ID())
+	if !ok || got != sess {
+		t.Errorf("get() = %v, %v; want created session", got, ok)
+	}
+
+	if _, ok := store.remove(sess.ID()); !ok {
+		t.Error("remove() = false, want true for existing session")
+	}
+	if _, ok := store.get(sess.ID()); ok {
+		t.Error("get() found session after remove()")
+	}
+	if _, ok := store.remove(sess.ID()); ok {
+		t.Error("remove() = true, want false for removed session")
+	}
+
+	// Default session plus the remaining one
+	if n := len(store.all()); n != 2 {
+		t.Errorf("all() returned %d sessions, want 2", n)
+	}
+}
+
+func TestSessionStore_PrunesIdleSessions(t *testing.T) {
+	t.Parallel()
+
+	store := newSessionStore()
+
+	stale, err := store.create("")
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	stale.lastSeen = time.Now().Add(-2 * sessionIdleTimeout)
+
+	if _, err := store.create(""); err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+
+	if _, ok := store.get(stale.ID()); ok {
+		t.Error("idle session was not pruned")
+	}
+}
+
+func TestSessionStore_KeepsActiveSessions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		activate func(sess *Session)
+	}{
+		{name: "open stream", activate: func(sess *Session) { sess.attachSink(newSessionStream()) }},
+		{name: "request in progress", activate: func(sess *Session) {
+			sess.trackRequest(json.RawMessage(`1`), func(error) {})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newSessionStore()
+			sess, err := store.create("")
+			if err != nil {
+				t.Fatalf("create() error = %v", err)
+			}
+			tt.activate(sess)
+			sess.lastSeen = time.Now().Add(-2 * sessionIdleTimeout)
+
+			if _, err := store.create(""); err != nil {
+				t.Fatalf("create() error = %v", err)
+			}
+			if _, ok := store.get(sess.ID()); !ok {
+				t.Error("active session was pruned")
+			}
+		})
+	}
+}
+
+func TestSession_End(t *testing.T) {
+	t.Parallel()
+
+	sess := newSession("abc")
+	stream := newSessionStream()
+	sess.attachSink(stream)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	sess.trackRequest(json.RawMessage(`1`), cancel)
+
+	sess.end()
+
+	select {
+	case <-stream.done:
+	default:
+		t.Error("stream still open after end()")
+	}
+	if err := context.Cause(ctx); !errors.Is(err, errSessionEnded) {
+		t.Errorf("request cancellation cause = %v, want errSessionEnded", err)
+	}
+	if sess.isActive() {
+		t.Error("isActive() = true after end()")
+	}
+}
+
+func TestSession_State(t *testing.T) {
+	t.Parallel()
+
+	sess := newSession("abc")
+
+	if sess.IsInitialized() {
+		t.Error("IsInitialized() = true for new session")
+	}
+
+	sess.setInitializeParams(&InitializeParams{
+		ProtocolVersion: "2024-11-05",
+		ClientInfo:      Implementation{Name: "client", Version: "1.0"},
+	})
+	sess.markInitialized()
+
+	if !sess.IsInitialized() {
+		t.Error("IsInitialized() = false after markInitialized()")
+	}
+	if got := sess.ClientInfo().Name; got != "client" {
+		t.Errorf("ClientInfo().Name = %q, want %q", got, "client")
+	}
+}
+
+func TestSession_Notify(t *testing.T) {
+	t.Parallel()
+
+	sess := newSession("abc")
+
+	if err := sess.Notify("notifications/test", nil); !errors.Is(err, errNoStream) {
+		t.Errorf("Notify() without sink error = %v, want errNoStream", err)
+	}
+
+	sink := &recordingSink{}
+	sess.attachSink(sink)
+
+	if err := sess.Notify("notifications/test", map[string]any{"k": "v"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(sink.messages) != 1 {
+		t.Fatalf("sink received %d messages, want 1", len(sink.messages))
+	}
+
+	data, _ := json.Marshal(sink.messages[0])
+	want := `{"jsonrpc":"2.0","method":"notifications/test","params":{"k":"v"}}`
+	if string(data) != want {
+		t.Errorf("notification = %s, want %s", data, want)
+	}
+
+	sess.detachSink(&recordingSink{}) // Different sink, must not detach
+	if err := sess.Notify("notifications/test", nil); err != nil {
+		t.Errorf("Notify() after detaching other sink error = %v", err)
+	}
+
+	sess.detachSink(sink)
+	if err := sess.Notify("notifications/test", nil); !errors.Is(err, errNoStream) {
+		t.Errorf("Notify() after detachSink() error = %v, want errNoStream", err)
+	}
+}
+
+func TestNotifyContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no session", func(t *testing.T) {
+		t.Parallel()
+
+		if err := notifyContext(context.Background(), "m", nil); !errors.Is(err, errNoStream) {
+			t.Errorf("notifyContext() error = %v, want errNoStream", err)
+		}
+	})
+
+	t.Run("prefers request sink", func(t *testing.T) {
+		t.Parallel()
+
+		sessSink := &recordingSink{}
+		reqSink := &recordingSink{}
+		sess := newSession("abc")
+		sess.attachSink(sessSink)
+
+		ctx := withRequestSink(withSession(context.Background(), sess), reqSink)
+		if err := notifyContext(ctx, "m", nil); err != nil {
+			t.Fatalf("notifyContext() error = %v", err)
+		}
+		if len(reqSink.messages) != 1 || len(sessSink.messages) != 0 {
+			t.Errorf("request sink got %d, session sink got %d; want 1, 0",
+				len(reqSink.messages), len(sessSink.messages))
+		}
+	})
+
+	t.Run("falls back to session sink", func(t *testing.T) {
+		t.Parallel()
+
+		sessSink := &recordingSink{}
+		reqSink := &recordingSink{err: errNoStream}
+		sess := newSession("abc")
+		sess.attachSink(sessSink)
+
+		ctx := withRequestSink(withSession(context.Background(), sess), reqSink)
+		if err := notifyContext(ctx, "m", nil); err != nil {
+			t.Fatalf("notifyContext() error = %v", err)
+		}
+		if len(sessSink.messages) != 1 {
+			t.Errorf("session sink got %d messages, want 1", len(sessSink.messages))
+		}
+	})
+}
+
+func TestSession_CancelRequest(t *testing.T) {
+	t.Parallel()
+
+	sess := newSession("s")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	sess.trackRequest(json.RawMessage(`1`), cancel)
+
+	if sess.cancelRequest(json.RawMessage(`"1"`)) {
+		t.Error("cancelRequest() matched a string ID against a numeric ID")
+	}
+	if !sess.cancelRequest(json.RawMessage(`1`)) {
+		t.Fatal("cancelRequest() = false for tracked request")
+	}
+	if !errors.Is(context.Cause(ctx), errRequestCancelled) {
+		t.Errorf("context.Cause() = %v, want errRequestCancelled", context.Cause(ctx))
+	}
+	if sess.cancelRequest(json.RawMessage(`1`)) {
+		t.Error("cancelRequest() = true for already cancelled request")
+	}
+
+	sess.trackRequest(json.RawMessage(`2`), cancel)
+	sess.untrackRequest(json.RawMessage(`2`))
+	if sess.cancelRequest(json.RawMessage(`2`)) {
+		t.Error("cancelRequest() = true for finished request")
+	}
+}
+
+func TestSession_Subscriptions(t *testing.T) {
+	t.Parallel()
+
+	sess := newSession("s1")
+	uri := "hass://entity/light.kitchen"
+
+	if sess.isSubscribed(uri) {
+		t.Fatal("isSubscribed() = true before subscribe")
+	}
+	sess.subscribe(uri)
+	if !sess.isSubscribed(uri) {
+		t.Error("isSubscribed() = false after subscribe")
+	}
+	sess.unsubscribe(uri)
+	if sess.isSubscribed(uri) {
+		t.Error("isSubscribed() = true after unsubscribe")
+	}
+}
@@ -0,0 +1,156 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSE stream settings.
+const (
+	// contentTypeEventStream is the MIME type of Server-Sent Events streams.
+	contentTypeEventStream = "text/event-stream"
+	// sseKeepAliveInterval is the interval between keep-alive comments on idle streams.
+	sseKeepAliveInterval = 15 * time.Second
+	// sseStreamBuffer is the number of messages buffered per session stream.
+	sseStreamBuffer = 64
+)
+
+// errStreamFull is returned when a session stream's buffer is full and the message was dropped.
+var errStreamFull = errors.New("session stream buffer full, message dropped")
+
+// acceptsEventStream reports whether the client accepts an SSE response.
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), contentTypeEventStream)
+}
+
+// beginEventStream writes SSE response headers and lifts the server's write
+// timeout, which would otherwise cut long-lived streams off.
+func beginEventStream(w http.ResponseWriter) *http.ResponseController {
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{}) // Not supported by all writers (e.g. test recorders)
+
+	w.Header().Set("Content-Type", contentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+	return rc
+}
+
+// writeSSEEvent writes a single JSON-RPC message as an SSE "message" event and flushes it.
+func writeSSEEvent(w http.ResponseWriter, rc *http.ResponseController, data []byte) error {
+	if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// sessionStream is the long-lived SSE stream opened by a GET request.
+// Messages are queued and written by the GET handler's goroutine.
+type sessionStream struct {
+	messages  chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newSessionStream creates a session stream with a bounded buffer.
+func newSessionStream() *sessionStream {
+	return &sessionStream{
+		messages: make(chan []byte, sseStreamBuffer),
+		done:     make(chan struct{}),
+	}
+}
+
+// send queues a message on the stream. Messages are dropped when the buffer is full.
+func (st *sessionStream) send(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+
+	select {
+	case <-st.done:
+		return errNoStream
+	default:
+	}
+
+	select {
+	case st.messages <- data:
+		return nil
+	case <-st.done:
+		return errNoStream
+	default:
+		return errStreamFull
+	}
+}
+
+// close stops the stream. It is safe to call more than once.
+func (st *sessionStream) close() {
+	st.closeOnce.Do(func() { close(st.done) })
+}
+
+// responseStream is the response of a single POST request. It answers with a
+// plain JSON body unless a message is sent before the response is ready, in which
+// case it upgrades to an SSE stream carrying those messages followed by the response.
+type responseStream struct {
+	mu        sync.Mutex
+	w         http.ResponseWriter
+	rc        *http.ResponseController
+	canStream bool
+	streaming bool
+	closed    bool
+}
+
+// newResponseStream creates a response stream for a POST request.
+func newResponseStream(w http.ResponseWriter, r *http.Request) *responseStream {
+	return &responseStream{
+		w:         w,
+		canStream: acceptsEventStream(r),
+	}
+}
+
+// send writes a server-to-client message, upgrading the response to SSE if needed.
+func (rs *responseStream) send(msg any) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.closed || !rs.canStream {
+		return errNoStream
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+
+	if !rs.streaming {
+		rs.rc = beginEventStream(rs.w)
+		rs.streaming = true
+	}
+	return writeSSEEvent(rs.w, rs.rc, data)
+}
+
+// finish marks the stream as finished so later messages fall back to the session
+// stream. If the response was upgraded to SSE, resp is written as the final event
+// and finish returns true; otherwise the caller must write resp as a plain body.
+func (rs *responseStream) finish(resp any) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.closed = true
+	if !rs.streaming {
+		return false
+	}
+	if resp != nil {
+		if data, err := json.Marshal(resp); err == nil {
+			_ = writeSSEEvent(rs.w, rs.rc, data)
+		}
+	}
+	return true
+}
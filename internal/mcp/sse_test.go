@@ -0,0 +1,291 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+//
+//nolint:errcheck,noctx // Test file - defer body.Close() and requests without context are acceptable
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+// newTestHTTPServer starts an httptest server serving the MCP endpoint.
+func newTestHTTPServer(t *testing.T, registry *Registry) (*Server, *httptest.Server) {
+	t.Helper()
+
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+	ts := httptest.NewServer(http.HandlerFunc(s.handleMCP))
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// postMessage sends a JSON-RPC message to the test server.
+func postMessage(t *testing.T, url, sessionID, accept, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if sessionID != "" {
+		req.Header.Set(SessionIDHeader, sessionID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	return resp
+}
+
+// initializeSession performs initialize and returns the issued session ID.
+func initializeSession(t *testing.T, url string) string {
+	t.Helper()
+
+	resp := postMessage(t, url, "", "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"clientInfo":{"name":"test"}}}`)
+	defer resp.Body.Close()
+
+	id := resp.Header.Get(SessionIDHeader)
+	if id == "" {
+		t.Fatal("initialize response has no session ID header")
+	}
+	return id
+}
+
+// readSSEData reads the next "data:" line from an SSE stream.
+func readSSEData(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading SSE stream: %v", err)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			return strings.TrimSpace(data)
+		}
+	}
+}
+
+func TestStreamableHTTP_SessionsAreIndependent(t *testing.T) {
+	t.Parallel()
+
+	s, ts := newTestHTTPServer(t, NewRegistry())
+
+	first := initializeSession(t, ts.URL)
+	second := initializeSession(t, ts.URL)
+	if first == second {
+		t.Fatal("initialize returned the same session ID twice")
+	}
+
+	resp := postMessage(t, ts.URL, first, "", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("notification status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+
+	firstSess, _ := s.sessions.get(first)
+	secondSess, _ := s.sessions.get(second)
+	if !firstSess.IsInitialized() {
+		t.Error("first session not initialized")
+	}
+	if secondSess.IsInitialized() {
+		t.Error("second session initialized by first session's notification")
+	}
+	if s.sessions.defaultSession().IsInitialized() {
+		t.Error("default session initialized by a session-scoped notification")
+	}
+}
+
+func TestStreamableHTTP_RequestsWithoutSessionShareNoState(t *testing.T) {
+	t.Parallel()
+
+	sessions := make(chan *Session, 2)
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "whoami"},
+		func(ctx context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			sess, _ := SessionFromContext(ctx)
+			sessions <- sess
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("ok")}}, nil
+		})
+
+	s, ts := newTestHTTPServer(t, registry)
+	for range 2 {
+		resp := postMessage(t, ts.URL, "", "", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami"}}`)
+		resp.Body.Close()
+	}
+
+	first, second := <-sessions, <-sessions
+	if first == nil || first == second {
+		t.Error("requests without a session ID share a session")
+	}
+	if first == s.sessions.defaultSession() || second == s.sessions.defaultSession() {
+		t.Error("request without a session ID uses the default session")
+	}
+}
+
+func TestStreamableHTTP_UnknownSession(t *testing.T) {
+	t.Parallel()
+
+	_, ts := newTestHTTPServer(t, NewRegistry())
+
+	resp := postMessage(t, ts.URL, "does-not-exist", "", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestStreamableHTTP_DeleteSession(t *testing.T) {
+	t.Parallel()
+
+	s, ts := newTestHTTPServer(t, NewRegistry())
+	id := initializeSession(t, ts.URL)
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL, nil)
+	req.Header.Set(SessionIDHeader, id)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE StatusCode = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	if _, ok := s.sessions.get(id); ok {
+		t.Error("session still exists after DELETE")
+	}
+
+	resp = postMessage(t, ts.URL, id, "", `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("POST after DELETE StatusCode = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestStreamableHTTP_GetStreamDeliversNotifications(t *testing.T) {
+	t.Parallel()
+
+	s, ts := newTestHTTPServer(t, NewRegistry())
+	id := initializeSession(t, ts.URL)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set(SessionIDHeader, id)
+	req.Header.Set("Accept", contentTypeEventStream)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != contentTypeEventStream {
+		t.Fatalf("Content-Type = %q, want %q", ct, contentTypeEventStream)
+	}
+
+	sess, _ := s.sessions.get(id)
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := sess.Notify("notifications/test", map[string]any{"n": 1})
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Notify() error = %v", err)
+		}
+		time.Sleep(10 * time.Millisecond) // Stream attaches asynchronously
+	}
+
+	data := readSSEData(t, bufio.NewReader(resp.Body))
+	var notif Notification
+	if err := json.Unmarshal([]byte(data), &notif); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if notif.Method != "notifications/test" {
+		t.Errorf("Method = %q, want %q", notif.Method, "notifications/test")
+	}
+}
+
+func TestStreamableHTTP_GetRequiresEventStreamAccept(t *testing.T) {
+	t.Parallel()
+
+	_, ts := newTestHTTPServer(t, NewRegistry())
+	id := initializeSession(t, ts.URL)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set(SessionIDHeader, id)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusNotAcceptable)
+	}
+}
+
+func TestStreamableHTTP_PostUpgradesToEventStream(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "chatty"},
+		func(ctx context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			_ = notifyContext(ctx, "notifications/test", nil)
+			return &ToolsCallResult{Content: []ContentBlock{NewTextContent("done")}}, nil
+		})
+
+	_, ts := newTestHTTPServer(t, registry)
+	body := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"chatty"}}`
+
+	t.Run("client accepts SSE", func(t *testing.T) {
+		t.Parallel()
+
+		resp := postMessage(t, ts.URL, "", "application/json, text/event-stream", body)
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != contentTypeEventStream {
+			t.Fatalf("Content-Type = %q, want %q", ct, contentTypeEventStream)
+		}
+
+		reader := bufio.NewReader(resp.Body)
+		if data := readSSEData(t, reader); !strings.Contains(data, `"method":"notifications/test"`) {
+			t.Errorf("first event = %s, want notification", data)
+		}
+		if data := readSSEData(t, reader); !strings.Contains(data, `"id":7`) {
+			t.Errorf("second event = %s, want response with id 7", data)
+		}
+	})
+
+	t.Run("client accepts JSON only", func(t *testing.T) {
+		t.Parallel()
+
+		resp := postMessage(t, ts.URL, "", "application/json", body)
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("Content-Type = %q, want %q", ct, "application/json")
+		}
+
+		var jsonResp Response
+		if err := json.NewDecoder(resp.Body).Decode(&jsonResp); err != nil {
+			t.Fatalf("json.Decode() error = %v", err)
+		}
+		if jsonResp.Error != nil {
+			t.Errorf("Unexpected error: %+v", jsonResp.Error)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// stdioRemoteAddr is used in log lines in place of an HTTP remote address.
+const stdioRemoteAddr = "stdio"
+
+// stdioWriter serializes newline-delimited JSON-RPC messages onto a single stream.
+type stdioWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// send encodes msg as a single line of JSON.
+func (w *stdioWriter) send(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+	data = append(data, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.out.Write(data)
+	return err
+}
+
+// stdioLine is a single line read from the input stream, or the error that ended it.
+type stdioLine struct {
+	data []byte
+	err  error
+}
+
+// ServeStdio serves newline-delimited JSON-RPC messages read from in and writes
+// responses to out, one message per line. Responses are written as requests
+// complete, so they may arrive in a different order than the requests. It returns
+// nil when in reaches EOF and ctx.Err() when the context is canceled.
+//
+// Nothing but protocol messages may be written to out, so the server's logger
+// must not write to the same stream (use logging.NewWithWriter with os.Stderr).
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	writer := &stdioWriter{out: out}
+	lines := make(chan stdioLine)
+
+	// The stdio client is the only client, so it uses the default session, with
+	// stdout as the stream for server-initiated messages.
+	sess := s.sessions.defaultSession()
+	sess.attachSink(writer)
+	defer sess.detachSink(writer)
+	ctx = withSession(ctx, sess)
+
+	go readStdioLines(ctx, in, lines)
+
+	s.logger.Info("MCP server serving on stdio")
+
+	// Messages are processed concurrently so that a notification such as
+	// notifications/cancelled can reach a request that is still running.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line := <-lines:
+			if line.err != nil {
+				if errors.Is(line.err, io.EOF) {
+					s.logger.Info("stdin closed, stopping stdio transport")
+					return nil
+				}
+				return fmt.Errorf("reading stdin: %w", line.err)
+			}
+
+			wg.Add(1)
+			go func(data []byte) {
+				defer wg.Done()
+				s.serveStdioMessage(ctx, writer, data)
+			}(line.data)
+		}
+	}
+}
+
+// serveStdioMessage processes a single message and writes its reply, if any.
+func (s *Server) serveStdioMessage(ctx context.Context, writer *stdioWriter, data []byte) {
+	resp := s.processMessage(ctx, data, stdioRemoteAddr)
+	if resp == nil {
+		return // Notifications don't get responses
+	}
+	if err := writer.send(resp); err != nil {
+		s.logger.Error("Failed to write response", "error", err)
+	}
+}
+
+// readStdioLines reads newline-delimited messages from in and sends them to lines.
+// Blank lines are skipped. The final send carries the error that ended reading.
+func readStdioLines(ctx context.Context, in io.Reader, lines chan<- stdioLine) {
+	reader := bufio.NewReader(in)
+	for {
+		data, err := reader.ReadBytes('\n')
+		data = bytes.TrimSpace(data)
+
+		if len(data) > 0 {
+			select {
+			case lines <- stdioLine{data: data}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err != nil {
+			select {
+			case lines <- stdioLine{err: err}:
+			case <-ctx.Done():
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+func TestServer_ServeStdio(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logging.New(logging.LevelOff))
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+		``,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`not valid json`,
+		`{"jsonrpc":"2.0","id":"two","method":"tools/list"}`,
+	}, "\n")
+
+	var out strings.Builder
+	if err := s.ServeStdio(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("ServeStdio() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d response lines, want 3:\n%s", len(lines), out.String())
+	}
+
+	// Messages are processed concurrently, so responses are matched by ID
+	resps := make(map[string]Response, len(lines))
+	for i, line := range lines {
+		var resp Response
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("line %d: json.Unmarshal() error = %v", i, err)
+		}
+		resps[string(resp.ID)] = resp
+	}
+
+	if resp, ok := resps["1"]; !ok || resp.Error != nil {
+		t.Errorf("ping response = %+v, want success with id 1", resp)
+	}
+	if resp, ok := resps[""]; !ok || resp.Error == nil || resp.Error.Code != ParseError {
+		t.Errorf("invalid JSON response = %+v, want ParseError", resp)
+	}
+	if resp, ok := resps[`"two"`]; !ok || resp.Error != nil {
+		t.Errorf("tools/list response = %+v, want success with id \"two\"", resp)
+	}
+
+	if !s.IsInitialized() {
+		t.Error("IsInitialized() = false after initialized notification over stdio")
+	}
+}
+
+func TestServer_ServeStdio_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logging.New(logging.LevelOff))
+
+	inReader, inWriter := io.Pipe()
+	defer inWriter.Close()
+	outReader, outWriter := io.Pipe()
+	defer outReader.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ServeStdio(ctx, inReader, outWriter)
+	}()
+
+	go func() {
+		_, _ = inWriter.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n"))
+	}()
+
+	line, err := bufio.NewReader(outReader).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	if !strings.Contains(line, `"id":1`) {
+		t.Errorf("response = %q, want id 1", line)
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ServeStdio() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ServeStdio() did not return after context cancellation")
+	}
+}
+
+func TestServer_ServeStdio_CancelInFlight(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{Name: "slow"},
+		func(ctx context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	defer outReader.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ServeStdio(context.Background(), inReader, outWriter)
+	}()
+
+	go func() {
+		_, _ = inWriter.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}}` + "\n"))
+		time.Sleep(20 * time.Millisecond)
+		_, _ = inWriter.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}` + "\n"))
+		_, _ = inWriter.Write([]byte(`{"jsonrpc":"2.0","id":2,"method":"ping"}` + "\n"))
+		time.Sleep(20 * time.Millisecond)
+		_ = inWriter.Close()
+	}()
+
+	line, err := bufio.NewReader(outReader).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	if !strings.Contains(line, `"id":2`) {
+		t.Errorf("response = %q, want only the ping response (id 2)", line)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ServeStdio() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ServeStdio() did not return; cancelled tool call still running")
+	}
+}
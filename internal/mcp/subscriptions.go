@@ -0,0 +1,164 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+)
+
+// EntityResourceTemplate is the URI template of entity resources, the only
+// resources that support subscriptions.
+const EntityResourceTemplate = "hass://entity/{entity_id}"
+
+// entityResourcePrefix is the URI prefix of entity resources.
+const entityResourcePrefix = "hass://entity/"
+
+// stateUpdateBuffer is the number of state changes queued for delivery to
+// subscribed clients. Changes beyond that are dropped.
+const stateUpdateBuffer = 256
+
+// errSubscriptionsUnsupported is returned when the Home Assistant client cannot stream state changes.
+var errSubscriptionsUnsupported = errors.New("resource subscriptions are not supported by this client")
+
+// stateWatcher forwards Home Assistant state changes to subscribed sessions.
+type stateWatcher struct {
+	unsubscribe func(context.Context) error
+	updates     chan string // entity IDs
+	done        chan struct{}
+}
+
+// supportsSubscriptions reports whether the Home Assistant client can stream state changes.
+func (s *Server) supportsSubscriptions() bool {
+	_, ok := s.haClient.(homeassistant.StateSubscriber)
+	return ok
+}
+
+// entityResourceURI returns the canonical URI of an entity resource, or false
+// if uri is not an entity resource.
+func entityResourceURI(uri string) (string, bool) {
+	vars, ok := MatchURITemplate(EntityResourceTemplate, uri)
+	if !ok {
+		return "", false
+	}
+	return entityResourcePrefix + vars["entity_id"], true
+}
+
+// handleResourcesSubscribe handles resources/subscribe requests.
+func (s *Server) handleResourcesSubscribe(ctx context.Context, req *Request) *Response {
+	var params ResourcesSubscribeParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return NewErrorResponse(req.ID, InvalidParams, "invalid resources/subscribe params", err.Error())
+	}
+
+	uri, ok := entityResourceURI(params.URI)
+	if !ok {
+		return NewErrorResponse(req.ID, InvalidParams,
+			fmt.Sprintf("subscriptions are only supported for entity resources: %s", params.URI), nil)
+	}
+
+	if err := s.ensureWatching(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to watch state changes", logAttrs(ctx, "error", err)...)
+		return NewErrorResponse(req.ID, InternalError, fmt.Sprintf("subscribe failed: %s", err.Error()), nil)
+	}
+
+	sess := s.sessionFor(ctx)
+	sess.subscribe(uri)
+	s.logger.InfoContext(ctx, "Resource subscribed", logAttrs(ctx, "uri", uri, "session_id", sess.ID())...)
+
+	return NewSuccessResponse(req.ID, EmptyResult{})
+}
+
+// handleResourcesUnsubscribe handles resources/unsubscribe requests.
+func (s *Server) handleResourcesUnsubscribe(ctx context.Context, req *Request) *Response {
+	var params ResourcesSubscribeParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return NewErrorResponse(req.ID, InvalidParams, "invalid resources/unsubscribe params", err.Error())
+	}
+
+	if uri, ok := entityResourceURI(params.URI); ok {
+		sess := s.sessionFor(ctx)
+		sess.unsubscribe(uri)
+		s.logger.InfoContext(ctx, "Resource unsubscribed", logAttrs(ctx, "uri", uri, "session_id", sess.ID())...)
+	}
+
+	return NewSuccessResponse(req.ID, EmptyResult{})
+}
+
+// ensureWatching subscribes to Home Assistant state changes on the first
+// resource subscription. The subscription is shared by all sessions and lasts
+// until the server shuts down.
+func (s *Server) ensureWatching(ctx context.Context) error {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	if s.watcher != nil {
+		return nil
+	}
+
+	subscriber, ok := s.haClient.(homeassistant.StateSubscriber)
+	if !ok {
+		return errSubscriptionsUnsupported
+	}
+
+	w := &stateWatcher{
+		updates: make(chan string, stateUpdateBuffer),
+		done:    make(chan struct{}),
+	}
+
+	unsubscribe, err := subscriber.SubscribeStateChanges(ctx, func(event homeassistant.StateChangedEvent) {
+		select {
+		case w.updates <- event.EntityID:
+		case <-w.done:
+		default:
+			s.logger.Warn("Dropped state change, update queue full", "entity_id", event.EntityID)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	w.unsubscribe = unsubscribe
+	s.watcher = w
+
+	go s.deliverResourceUpdates(w)
+	return nil
+}
+
+// deliverResourceUpdates notifies subscribed sessions of changed entities until the watcher stops.
+func (s *Server) deliverResourceUpdates(w *stateWatcher) {
+	for {
+		select {
+		case <-w.done:
+			return
+		case entityID := <-w.updates:
+			uri := entityResourcePrefix + entityID
+			for _, sess := range s.sessions.all() {
+				if !sess.isSubscribed(uri) {
+					continue
+				}
+				if err := sess.Notify(MethodResourcesUpdated, ResourceUpdatedParams{URI: uri}); err != nil {
+					s.logger.Debug("Resource update not delivered", "uri", uri, "session_id", sess.ID(), "error", err)
+				}
+			}
+		}
+	}
+}
+
+// stopWatching ends the Home Assistant state change subscription, if any.
+func (s *Server) stopWatching(ctx context.Context) {
+	s.watchMu.Lock()
+	w := s.watcher
+	s.watcher = nil
+	s.watchMu.Unlock()
+
+	if w == nil {
+		return
+	}
+	close(w.done)
+	if err := w.unsubscribe(ctx); err != nil {
+		s.logger.Debug("Failed to unsubscribe from state changes", "error", err)
+	}
+}
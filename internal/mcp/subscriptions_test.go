@@ -0,0 +1,227 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+// mockSubscriberClient is a mockHAClient that can stream state changes.
+type mockSubscriberClient struct {
+	mockHAClient
+
+	mu           sync.Mutex
+	handler      func(homeassistant.StateChangedEvent)
+	subscribes   int
+	unsubscribed bool
+}
+
+func (m *mockSubscriberClient) SubscribeStateChanges(_ context.Context, handler func(homeassistant.StateChangedEvent)) (func(context.Context) error, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handler = handler
+	m.subscribes++
+	return func(context.Context) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.unsubscribed = true
+		return nil
+	}, nil
+}
+
+// emit delivers a state change for entityID to the subscribed handler.
+func (m *mockSubscriberClient) emit(entityID string) {
+	m.mu.Lock()
+	handler := m.handler
+	m.mu.Unlock()
+	handler(homeassistant.StateChangedEvent{EntityID: entityID})
+}
+
+// syncSink is a recordingSink that is safe for use by the delivery goroutine.
+type syncSink struct {
+	mu   sync.Mutex
+	sent []any
+}
+
+func (r *syncSink) send(msg any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+func (r *syncSink) messages() []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]any(nil), r.sent...)
+}
+
+// subscribeRequest sends resources/subscribe or resources/unsubscribe for the session in ctx.
+func subscribeRequest(s *Server, ctx context.Context, method, uri string) *Response {
+	params, _ := json.Marshal(ResourcesSubscribeParams{URI: uri})
+	return s.handleRequest(ctx, &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  method,
+		Params:  params,
+	})
+}
+
+func TestServer_ResourceSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	client := &mockSubscriberClient{}
+	s := NewServer(client, NewRegistry(), 8080, logging.New(logging.LevelOff))
+
+	subscribed := newSession("subscribed")
+	subscribedSink := &syncSink{}
+	subscribed.attachSink(subscribedSink)
+
+	other := newSession("other")
+	otherSink := &syncSink{}
+	other.attachSink(otherSink)
+
+	s.sessions.mu.Lock()
+	s.sessions.sessions[subscribed.ID()] = subscribed
+	s.sessions.sessions[other.ID()] = other
+	s.sessions.mu.Unlock()
+
+	ctx := withSession(context.Background(), subscribed)
+	if resp := subscribeRequest(s, ctx, MethodResourcesSubscribe, "hass://entity/binary_sensor.door"); resp.Error != nil {
+		t.Fatalf("subscribe error: %+v", resp.Error)
+	}
+	if resp := subscribeRequest(s, withSession(context.Background(), other), MethodResourcesSubscribe, "hass://entity/light.kitchen"); resp.Error != nil {
+		t.Fatalf("subscribe error: %+v", resp.Error)
+	}
+	client.mu.Lock()
+	subscribes := client.subscribes
+	client.mu.Unlock()
+	if subscribes != 1 {
+		t.Errorf("SubscribeStateChanges() called %d times, want 1", subscribes)
+	}
+
+	client.emit("binary_sensor.door")
+	client.emit("sensor.unrelated")
+
+	waitFor(t, func() bool { return len(subscribedSink.messages()) == 1 })
+
+	notification, ok := subscribedSink.messages()[0].(*Notification)
+	if !ok || notification.Method != MethodResourcesUpdated {
+		t.Fatalf("message = %+v, want %s", subscribedSink.messages()[0], MethodResourcesUpdated)
+	}
+	if params, _ := notification.Params.(ResourceUpdatedParams); params.URI != "hass://entity/binary_sensor.door" {
+		t.Errorf("updated URI = %q, want hass://entity/binary_sensor.door", params.URI)
+	}
+	if n := len(otherSink.messages()); n != 0 {
+		t.Errorf("other session received %d messages, want 0", n)
+	}
+
+	// After unsubscribing, changes are no longer delivered
+	if resp := subscribeRequest(s, ctx, MethodResourcesUnsubscribe, "hass://entity/binary_sensor.door"); resp.Error != nil {
+		t.Fatalf("unsubscribe error: %+v", resp.Error)
+	}
+	client.emit("binary_sensor.door")
+	client.emit("light.kitchen")
+	waitFor(t, func() bool { return len(otherSink.messages()) == 1 })
+	if n := len(subscribedSink.messages()); n != 1 {
+		t.Errorf("subscribed session received %d messages after unsubscribe, want 1", n)
+	}
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if !client.unsubscribed {
+		t.Error("Shutdown() did not end the state change subscription")
+	}
+}
+
+func TestServer_ResourceSubscribe_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		client   homeassistant.Client
+		uri      string
+		wantCode ErrorCode
+	}{
+		{
+			name:     "not an entity resource",
+			client:   &mockSubscriberClient{},
+			uri:      "hass://automation/morning",
+			wantCode: InvalidParams,
+		},
+		{
+			name:     "client cannot stream state changes",
+			client:   &mockHAClient{},
+			uri:      "hass://entity/light.kitchen",
+			wantCode: InternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewServer(tt.client, NewRegistry(), 8080, logging.New(logging.LevelOff))
+			resp := subscribeRequest(s, context.Background(), MethodResourcesSubscribe, tt.uri)
+			if resp.Error == nil || resp.Error.Code != tt.wantCode {
+				t.Errorf("Error = %+v, want code %d", resp.Error, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestServer_SubscribeCapability(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		client homeassistant.Client
+		want   bool
+	}{
+		{name: "subscriber", client: &mockSubscriberClient{}, want: true},
+		{name: "plain client", client: &mockHAClient{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewServer(tt.client, NewRegistry(), 8080, logging.New(logging.LevelOff))
+			resp := s.handleRequest(context.Background(), &Request{
+				JSONRPC: JSONRPCVersion,
+				ID:      json.RawMessage(`1`),
+				Method:  MethodInitialize,
+				Params:  json.RawMessage(`{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1"}}`),
+			})
+			result, ok := resp.Result.(InitializeResult)
+			if !ok {
+				t.Fatalf("Result type = %T, want InitializeResult", resp.Result)
+			}
+			if got := result.Capabilities.Resources.Subscribe; got != tt.want {
+				t.Errorf("Resources.Subscribe = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// waitFor polls cond until it holds or the test times out.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 2s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,184 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Toolset is a named group of tools that clients enable as a unit.
+type Toolset struct {
+	Name        string
+	Description string
+}
+
+// ToolsetInfo describes a toolset and whether it is enabled for a session.
+type ToolsetInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Tools       []string `json:"tools"`
+}
+
+// RegisterToolset registers the tools added by register as members of the
+// given toolset. A tool registered again in a later toolset moves to it.
+func (r *Registry) RegisterToolset(toolset Toolset, register func(*Registry)) {
+	r.mu.Lock()
+	r.toolsets = append(r.toolsets, toolset)
+	r.currentToolset = toolset.Name
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.currentToolset = ""
+		r.mu.Unlock()
+	}()
+
+	register(r)
+}
+
+// ToolsetNames returns the names of all registered toolsets in registration order.
+func (r *Registry) ToolsetNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.toolsets))
+	for i, ts := range r.toolsets {
+		names[i] = ts.Name
+	}
+	return names
+}
+
+// SetDefaultToolsets selects the toolsets enabled in new sessions. Tools
+// outside any toolset are always enabled. An empty list enables all toolsets,
+// which is also the default.
+func (r *Registry) SetDefaultToolsets(names []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(names) == 0 {
+		r.defaultToolsets = nil
+		return nil
+	}
+
+	defaults := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !r.hasToolsetLocked(name) {
+			return fmt.Errorf("unknown toolset %q", name)
+		}
+		defaults[name] = true
+	}
+	r.defaultToolsets = defaults
+	return nil
+}
+
+// Toolsets describes all toolsets as seen by the session handling ctx.
+func (r *Registry) Toolsets(ctx context.Context) []ToolsetInfo {
+	sess, _ := SessionFromContext(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make(map[string][]string, len(r.toolsets))
+	for name, entry := range r.tools {
+		if entry.toolset != "" {
+			members[entry.toolset] = append(members[entry.toolset], name)
+		}
+	}
+
+	infos := make([]ToolsetInfo, len(r.toolsets))
+	for i, ts := range r.toolsets {
+		tools := members[ts.Name]
+		sort.Strings(tools)
+		infos[i] = ToolsetInfo{
+			Name:        ts.Name,
+			Description: ts.Description,
+			Enabled:     r.toolsetEnabledLocked(sess, ts.Name),
+			Tools:       tools,
+		}
+	}
+	return infos
+}
+
+// EnableToolset enables a toolset for the session handling ctx and notifies
+// the client that its tool list changed. It reports whether the toolset was
+// disabled before.
+func (r *Registry) EnableToolset(ctx context.Context, name string) (bool, error) {
+	// An ephemeral session ends with the request, and the toolset with it
+	sess, ok := SessionFromContext(ctx)
+	if !ok || sess.ephemeral {
+		return false, errors.New("toolsets can only be enabled within a session")
+	}
+
+	r.mu.RLock()
+	known := r.hasToolsetLocked(name)
+	enabled := r.toolsetEnabledLocked(sess, name)
+	r.mu.RUnlock()
+
+	if !known {
+		return false, fmt.Errorf("unknown toolset %q", name)
+	}
+	if enabled || !sess.enableToolset(name) {
+		return false, nil
+	}
+
+	if err := notifyContext(ctx, MethodToolsListChanged, nil); err != nil && !errors.Is(err, errNoStream) {
+		return true, fmt.Errorf("notifying tool list change: %w", err)
+	}
+	return true, nil
+}
+
+// ListToolsFor returns the tools enabled for a session.
+func (r *Registry) ListToolsFor(sess *Session) []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]Tool, 0, len(r.tools))
+	for _, entry := range r.tools {
+		if entry.toolset == "" || r.toolsetEnabledLocked(sess, entry.toolset) {
+			tools = append(tools, entry.tool)
+		}
+	}
+	return tools
+}
+
+// disabledToolset returns the toolset of the named tool if it is not
+// enabled for the session.
+func (r *Registry) disabledToolset(sess *Session, name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, exists := r.tools[name]
+	if !exists || entry.toolset == "" || r.toolsetEnabledLocked(sess, entry.toolset) {
+		return "", false
+	}
+	return entry.toolset, true
+}
+
+// hasToolsets reports whether any toolset is registered.
+func (r *Registry) hasToolsets() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.toolsets) > 0
+}
+
+// hasToolsetLocked reports whether a toolset is registered. r.mu must be held.
+func (r *Registry) hasToolsetLocked(name string) bool {
+	for _, ts := range r.toolsets {
+		if ts.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// toolsetEnabledLocked reports whether a toolset is enabled by default or
+// by the session. sess may be nil. r.mu must be held.
+func (r *Registry) toolsetEnabledLocked(sess *Session, name string) bool {
+	if r.defaultToolsets == nil || r.defaultToolsets[name] {
+		return true
+	}
+	return sess != nil && sess.hasToolset(name)
+}
@@ -0,0 +1,159 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+// toolsetRegistry returns a registry with an always-enabled tool and two toolsets.
+func toolsetRegistry(t *testing.T) *Registry {
+	t.Helper()
+
+	handler := func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+		return &ToolsCallResult{Content: []ContentBlock{NewTextContent("ok")}}, nil
+	}
+
+	r := NewRegistry()
+	r.RegisterTool(Tool{Name: "meta"}, handler)
+	r.RegisterToolset(Toolset{Name: "core", Description: "Core"}, func(r *Registry) {
+		r.RegisterTool(Tool{Name: "get_state"}, handler)
+	})
+	r.RegisterToolset(Toolset{Name: "helpers", Description: "Helpers"}, func(r *Registry) {
+		r.RegisterTool(Tool{Name: "create_counter"}, handler)
+		r.RegisterTool(Tool{Name: "delete_counter"}, handler)
+	})
+	if err := r.SetDefaultToolsets([]string{"core"}); err != nil {
+		t.Fatalf("SetDefaultToolsets() error = %v", err)
+	}
+	return r
+}
+
+func toolNames(tools []Tool) []string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRegistry_Toolsets(t *testing.T) {
+	t.Parallel()
+
+	r := toolsetRegistry(t)
+	sess := newSession("s")
+	ctx := withSession(context.Background(), sess)
+
+	if diff := cmp.Diff([]string{"core", "helpers"}, r.ToolsetNames()); diff != "" {
+		t.Errorf("ToolsetNames() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"get_state", "meta"}, toolNames(r.ListToolsFor(sess))); diff != "" {
+		t.Errorf("ListToolsFor() before enabling mismatch (-want +got):\n%s", diff)
+	}
+
+	changed, err := r.EnableToolset(ctx, "helpers")
+	if err != nil || !changed {
+		t.Fatalf("EnableToolset() = %v, %v, want true, nil", changed, err)
+	}
+	changed, err = r.EnableToolset(ctx, "helpers")
+	if err != nil || changed {
+		t.Errorf("EnableToolset() again = %v, %v, want false, nil", changed, err)
+	}
+
+	want := []string{"create_counter", "delete_counter", "get_state", "meta"}
+	if diff := cmp.Diff(want, toolNames(r.ListToolsFor(sess))); diff != "" {
+		t.Errorf("ListToolsFor() after enabling mismatch (-want +got):\n%s", diff)
+	}
+
+	wantInfos := []ToolsetInfo{
+		{Name: "core", Description: "Core", Enabled: true, Tools: []string{"get_state"}},
+		{Name: "helpers", Description: "Helpers", Enabled: true, Tools: []string{"create_counter", "delete_counter"}},
+	}
+	if diff := cmp.Diff(wantInfos, r.Toolsets(ctx)); diff != "" {
+		t.Errorf("Toolsets() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Other sessions are unaffected
+	if diff := cmp.Diff([]string{"get_state", "meta"}, toolNames(r.ListToolsFor(newSession("other")))); diff != "" {
+		t.Errorf("ListToolsFor(other session) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRegistry_ToolsetErrors(t *testing.T) {
+	t.Parallel()
+
+	r := toolsetRegistry(t)
+
+	if err := r.SetDefaultToolsets([]string{"missing"}); err == nil {
+		t.Error("SetDefaultToolsets(missing) error = nil, want error")
+	}
+	if _, err := r.EnableToolset(withSession(context.Background(), newSession("s")), "missing"); err == nil {
+		t.Error("EnableToolset(missing) error = nil, want error")
+	}
+	if _, err := r.EnableToolset(context.Background(), "helpers"); err == nil {
+		t.Error("EnableToolset() without session error = nil, want error")
+	}
+	if _, err := r.EnableToolset(withSession(context.Background(), newEphemeralSession()), "helpers"); err == nil {
+		t.Error("EnableToolset() in an ephemeral session error = nil, want error")
+	}
+
+	if err := r.SetDefaultToolsets(nil); err != nil {
+		t.Fatalf("SetDefaultToolsets(nil) error = %v", err)
+	}
+	if got := len(r.ListToolsFor(newSession("s"))); got != 4 {
+		t.Errorf("len(ListToolsFor()) with all toolsets = %d, want 4", got)
+	}
+}
+
+func TestServer_Toolsets(t *testing.T) {
+	t.Parallel()
+
+	r := toolsetRegistry(t)
+	s := NewServer(&mockHAClient{}, r, 8080, logging.New(logging.LevelOff))
+	sess := newSession("s")
+	sink := &recordingSink{}
+	sess.attachSink(sink)
+	ctx := withSession(context.Background(), sess)
+
+	init := s.handleRequest(ctx, &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodInitialize,
+		Params:  json.RawMessage(`{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}`),
+	})
+	if caps := init.Result.(InitializeResult).Capabilities; !caps.Tools.ListChanged {
+		t.Error("Capabilities.Tools.ListChanged = false, want true")
+	}
+
+	call := func() *Response {
+		return s.handleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      json.RawMessage(`2`),
+			Method:  MethodToolsCall,
+			Params:  json.RawMessage(`{"name":"create_counter"}`),
+		})
+	}
+
+	if resp := call(); resp.Error == nil || resp.Error.Code != ToolNotFound {
+		t.Fatalf("call in disabled toolset: Error = %+v, want ToolNotFound", resp.Error)
+	}
+
+	if _, err := r.EnableToolset(ctx, "helpers"); err != nil {
+		t.Fatalf("EnableToolset() error = %v", err)
+	}
+
+	if resp := call(); resp.Error != nil {
+		t.Errorf("call in enabled toolset: Error = %+v, want nil", resp.Error)
+	}
+	want := []any{NewNotification(MethodToolsListChanged, nil)}
+	if diff := cmp.Diff(want, sink.messages); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -24,6 +24,21 @@ type Response struct {
 	Error   *ErrorObject    `json:"error,omitempty"`
 }
 
+// ServerRequest represents a JSON-RPC 2.0 request sent from server to client.
+type ServerRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  any             `json:"params,omitempty"`
+}
+
+// Notification represents a JSON-RPC 2.0 notification sent from server to client.
+type Notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
 // ErrorObject represents a JSON-RPC 2.0 error.
 type ErrorObject struct {
 	Code    ErrorCode `json:"code"`
@@ -48,6 +63,11 @@ const (
 	ResourceNotFound ErrorCode = -32001
 	ToolNotFound     ErrorCode = -32002
 	ToolExecutionErr ErrorCode = -32003
+	ToolNotAllowed   ErrorCode = -32004
+	RateLimited      ErrorCode = -32005
+	// ConfirmationRequired is returned for calls that need the user's
+	// confirmation when the client cannot ask for it.
+	ConfirmationRequired ErrorCode = -32006
 )
 
 // MCP Protocol Methods.
@@ -58,12 +78,22 @@ const (
 	MethodToolsCall     = "tools/call"
 	MethodResourcesList = "resources/list"
 	MethodResourcesRead = "resources/read"
+	MethodResourcesTmpl = "resources/templates/list"
 	MethodPromptsList   = "prompts/list"
 	MethodPromptsGet    = "prompts/get"
 	MethodLoggingSetLvl = "logging/setLevel"
 	MethodPing          = "ping"
 	MethodCanceled      = "notifications/cancelled" //nolint:misspell // MCP protocol-defined value
 	MethodProgress      = "notifications/progress"
+	MethodLogMessage    = "notifications/message"
+
+	MethodResourcesSubscribe   = "resources/subscribe"
+	MethodResourcesUnsubscribe = "resources/unsubscribe"
+	MethodResourcesUpdated     = "notifications/resources/updated"
+
+	MethodToolsListChanged = "notifications/tools/list_changed"
+
+	MethodElicitationCreate = "elicitation/create"
 )
 
 // InitializeParams represents the parameters for the initialize request.
@@ -92,6 +122,7 @@ type ClientCapabilities struct {
 	Experimental map[string]any   `json:"experimental,omitempty"`
 	Roots        *RootsCapability `json:"roots,omitempty"`
 	Sampling     map[string]any   `json:"sampling,omitempty"`
+	Elicitation  map[string]any   `json:"elicitation,omitempty"`
 }
 
 // RootsCapability describes root listing support.
@@ -102,12 +133,15 @@ type RootsCapability struct {
 // ServerCapabilities describes what the server supports.
 type ServerCapabilities struct {
 	Experimental map[string]any       `json:"experimental,omitempty"`
-	Logging      map[string]any       `json:"logging,omitempty"`
+	Logging      *LoggingCapability   `json:"logging,omitempty"`
 	Prompts      *PromptsCapability   `json:"prompts,omitempty"`
 	Resources    *ResourcesCapability `json:"resources,omitempty"`
 	Tools        *ToolsCapability     `json:"tools,omitempty"`
 }
 
+// LoggingCapability describes support for logging/setLevel and notifications/message.
+type LoggingCapability struct{}
+
 // PromptsCapability describes prompt support.
 type PromptsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
@@ -126,20 +160,39 @@ type ToolsCapability struct {
 
 // Tool represents an MCP tool definition.
 type Tool struct {
-	Name        string     `json:"name"`
-	Description string     `json:"description,omitempty"`
-	InputSchema JSONSchema `json:"inputSchema"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description,omitempty"`
+	InputSchema  JSONSchema       `json:"inputSchema"`
+	OutputSchema *JSONSchema      `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations describes the behavior of a tool to clients. The hints are
+// always serialized because the MCP defaults for absent destructiveHint and
+// openWorldHint are true.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint"`
+	DestructiveHint bool   `json:"destructiveHint"`
+	IdempotentHint  bool   `json:"idempotentHint"`
+	OpenWorldHint   bool   `json:"openWorldHint"`
 }
 
-// JSONSchema represents a JSON Schema for tool input validation.
+// JSONSchema represents a JSON Schema for tool input validation. Type may be
+// empty for schemas that only combine others through OneOf.
 type JSONSchema struct {
-	Type        string                `json:"type"`
-	Description string                `json:"description,omitempty"`
-	Properties  map[string]JSONSchema `json:"properties,omitempty"`
-	Required    []string              `json:"required,omitempty"`
-	Items       *JSONSchema           `json:"items,omitempty"`
-	Enum        []string              `json:"enum,omitempty"`
-	Default     any                   `json:"default,omitempty"`
+	Type                 string                `json:"type,omitempty"`
+	Description          string                `json:"description,omitempty"`
+	Properties           map[string]JSONSchema `json:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty"`
+	AdditionalProperties *bool                 `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema           `json:"items,omitempty"`
+	Enum                 []string              `json:"enum,omitempty"`
+	Default              any                   `json:"default,omitempty"`
+	Minimum              *float64              `json:"minimum,omitempty"`
+	Maximum              *float64              `json:"maximum,omitempty"`
+	Pattern              string                `json:"pattern,omitempty"` // RE2 syntax
+	OneOf                []JSONSchema          `json:"oneOf,omitempty"`
 }
 
 // ToolsListResult represents the result of tools/list.
@@ -152,12 +205,47 @@ type ToolsListResult struct {
 type ToolsCallParams struct {
 	Name      string         `json:"name"`
 	Arguments map[string]any `json:"arguments,omitempty"`
+	Meta      *RequestMeta   `json:"_meta,omitempty"`
+}
+
+// RequestMeta represents the _meta object of request parameters.
+type RequestMeta struct {
+	// ProgressToken asks for notifications/progress tagged with this token.
+	ProgressToken json.RawMessage `json:"progressToken,omitempty"`
 }
 
-// ToolsCallResult represents the result of tools/call.
+// ProgressParams represents the parameters of notifications/progress.
+type ProgressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// ToolsCallResult represents the result of tools/call. StructuredContent
+// holds a JSON object conforming to the tool's OutputSchema.
 type ToolsCallResult struct {
-	Content []ContentBlock `json:"content"`
-	IsError bool           `json:"isError,omitempty"`
+	Content           []ContentBlock `json:"content"`
+	StructuredContent any            `json:"structuredContent,omitempty"`
+	IsError           bool           `json:"isError,omitempty"`
+}
+
+// LoggingSetLevelParams represents the parameters of logging/setLevel.
+type LoggingSetLevelParams struct {
+	Level string `json:"level"`
+}
+
+// LoggingMessageParams represents the parameters of notifications/message.
+type LoggingMessageParams struct {
+	Level  string `json:"level"`
+	Logger string `json:"logger,omitempty"`
+	Data   any    `json:"data"`
+}
+
+// CancelledParams represents the parameters of notifications/cancelled.
+type CancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
 }
 
 // ContentBlock represents a content block in a tool result.
@@ -177,6 +265,21 @@ type Resource struct {
 	MimeType    string `json:"mimeType,omitempty"`
 }
 
+// ResourceTemplate represents a parameterized MCP resource. URITemplate is an
+// RFC 6570 level 1 template such as "hass://entity/{entity_id}".
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourcesTemplatesListResult represents the result of resources/templates/list.
+type ResourcesTemplatesListResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        *string            `json:"nextCursor,omitempty"`
+}
+
 // ResourcesListResult represents the result of resources/list.
 type ResourcesListResult struct {
 	Resources  []Resource `json:"resources"`
@@ -188,6 +291,17 @@ type ResourcesReadParams struct {
 	URI string `json:"uri"`
 }
 
+// ResourcesSubscribeParams represents the parameters for resources/subscribe
+// and resources/unsubscribe.
+type ResourcesSubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams represents the parameters of notifications/resources/updated.
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
 // ResourcesReadResult represents the result of resources/read.
 type ResourcesReadResult struct {
 	Contents []ResourceContent `json:"contents"`
@@ -201,9 +315,80 @@ type ResourceContent struct {
 	Blob     string `json:"blob,omitempty"` // base64
 }
 
+// Prompt represents an MCP prompt template.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument accepted by a prompt.
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptsListResult represents the result of prompts/list.
+type PromptsListResult struct {
+	Prompts    []Prompt `json:"prompts"`
+	NextCursor *string  `json:"nextCursor,omitempty"`
+}
+
+// PromptsGetParams represents the parameters for prompts/get.
+type PromptsGetParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// PromptsGetResult represents the result of prompts/get.
+type PromptsGetResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptMessage is a single message in a prompt.
+type PromptMessage struct {
+	Role    string       `json:"role"` // "user" or "assistant"
+	Content ContentBlock `json:"content"`
+}
+
+// NewUserPromptMessage creates a user message with text content.
+func NewUserPromptMessage(text string) PromptMessage {
+	return PromptMessage{
+		Role:    "user",
+		Content: NewTextContent(text),
+	}
+}
+
+// Actions reported in ElicitResult.
+const (
+	ElicitActionAccept  = "accept"
+	ElicitActionDecline = "decline"
+	ElicitActionCancel  = "cancel"
+)
+
+// ElicitRequestParams represents the parameters of elicitation/create.
+type ElicitRequestParams struct {
+	Message string `json:"message"`
+	// RequestedSchema is a flat object schema with primitive properties.
+	RequestedSchema JSONSchema `json:"requestedSchema"`
+}
+
+// ElicitResult represents the client's answer to elicitation/create.
+type ElicitResult struct {
+	// Action is ElicitActionAccept, ElicitActionDecline, or ElicitActionCancel.
+	Action string `json:"action"`
+	// Content holds the submitted values if the user accepted.
+	Content map[string]any `json:"content,omitempty"`
+}
+
 // PingResult represents the result of a ping request.
 type PingResult struct{}
 
+// EmptyResult represents the result of requests that return no data.
+type EmptyResult struct{}
+
 // NewTextContent creates a text content block.
 func NewTextContent(text string) ContentBlock {
 	return ContentBlock{
@@ -225,6 +410,25 @@ func NewErrorResponse(id json.RawMessage, code ErrorCode, message string, data a
 	}
 }
 
+// NewNotification creates a server-to-client notification.
+func NewNotification(method string, params any) *Notification {
+	return &Notification{
+		JSONRPC: JSONRPCVersion,
+		Method:  method,
+		Params:  params,
+	}
+}
+
+// NewServerRequest creates a request to send to the client.
+func NewServerRequest(id json.RawMessage, method string, params any) *ServerRequest {
+	return &ServerRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	}
+}
+
 // NewSuccessResponse creates a success response.
 func NewSuccessResponse(id json.RawMessage, result any) *Response {
 	return &Response{
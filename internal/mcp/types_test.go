@@ -444,6 +444,58 @@ func TestTool_JSONSerialization(t *testing.T) {
 	}
 }
 
+func TestTool_AnnotationsAndOutputSchema(t *testing.T) {
+	t.Parallel()
+
+	tool := Tool{
+		Name:        "delete_automation",
+		InputSchema: JSONSchema{Type: "object"},
+		OutputSchema: &JSONSchema{
+			Type:       "object",
+			Properties: map[string]JSONSchema{"count": {Type: "integer"}},
+		},
+		Annotations: &ToolAnnotations{Title: "Delete Automation", DestructiveHint: true, IdempotentHint: true},
+	}
+
+	data, err := json.Marshal(tool)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	// Hints are sent even when false, since absent hints default to true for some of them
+	wantAnnotations := map[string]any{
+		"title":           "Delete Automation",
+		"readOnlyHint":    false,
+		"destructiveHint": true,
+		"idempotentHint":  true,
+		"openWorldHint":   false,
+	}
+	if diff := cmp.Diff(wantAnnotations, got["annotations"]); diff != "" {
+		t.Errorf("annotations mismatch (-want +got):\n%s", diff)
+	}
+	if _, ok := got["outputSchema"].(map[string]any); !ok {
+		t.Errorf("outputSchema = %v, want object", got["outputSchema"])
+	}
+
+	// Both fields are omitted when unset
+	data, err = json.Marshal(Tool{Name: "plain"})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	got = nil
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if _, ok := got["annotations"]; ok {
+		t.Error("annotations present for a tool without annotations")
+	}
+}
+
 func TestResource_JSONSerialization(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,233 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidationError describes a tool argument that violates the tool's input schema.
+type ValidationError struct {
+	// Pointer is an RFC 6901 JSON pointer to the offending value, relative to
+	// the arguments object. It is empty if the arguments object itself is invalid.
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	if e.Pointer == "" {
+		return e.Message
+	}
+	return e.Pointer + ": " + e.Message
+}
+
+// compileSchemaPatterns compiles the patterns of schema and all nested schemas into patterns.
+func compileSchemaPatterns(schema *JSONSchema, patterns map[string]*regexp.Regexp) error {
+	if schema.Pattern != "" {
+		if _, ok := patterns[schema.Pattern]; !ok {
+			re, err := regexp.Compile(schema.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid schema pattern %q: %w", schema.Pattern, err)
+			}
+			patterns[schema.Pattern] = re
+		}
+	}
+
+	for name := range schema.Properties {
+		prop := schema.Properties[name]
+		if err := compileSchemaPatterns(&prop, patterns); err != nil {
+			return err
+		}
+	}
+	if schema.Items != nil {
+		if err := compileSchemaPatterns(schema.Items, patterns); err != nil {
+			return err
+		}
+	}
+	for i := range schema.OneOf {
+		if err := compileSchemaPatterns(&schema.OneOf[i], patterns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaValidator validates decoded JSON values against a JSONSchema.
+type schemaValidator struct {
+	patterns map[string]*regexp.Regexp
+}
+
+// validate checks value, located at pointer, against schema and returns the first violation.
+func (v schemaValidator) validate(schema *JSONSchema, value any, pointer string) *ValidationError {
+	if len(schema.OneOf) > 0 {
+		if verr := v.validateOneOf(schema.OneOf, value, pointer); verr != nil {
+			return verr
+		}
+	}
+
+	if schema.Type != "" && !hasJSONType(value, schema.Type) {
+		return &ValidationError{
+			Pointer: pointer,
+			Message: fmt.Sprintf("must be of type %s, got %s", schema.Type, jsonTypeName(value)),
+		}
+	}
+
+	switch val := value.(type) {
+	case string:
+		return v.validateString(schema, val, pointer)
+	case float64:
+		return validateNumber(schema, val, pointer)
+	case map[string]any:
+		return v.validateObject(schema, val, pointer)
+	case []any:
+		if schema.Items == nil {
+			return nil
+		}
+		for i, item := range val {
+			if verr := v.validate(schema.Items, item, pointer+"/"+strconv.Itoa(i)); verr != nil {
+				return verr
+			}
+		}
+	}
+	return nil
+}
+
+// validateOneOf checks that value matches exactly one of the schemas.
+func (v schemaValidator) validateOneOf(schemas []JSONSchema, value any, pointer string) *ValidationError {
+	matches := 0
+	var firstErr *ValidationError
+	for i := range schemas {
+		verr := v.validate(&schemas[i], value, pointer)
+		if verr == nil {
+			matches++
+		} else if firstErr == nil {
+			firstErr = verr
+		}
+	}
+
+	switch {
+	case matches == 1:
+		return nil
+	case matches == 0 && len(schemas) == 1:
+		return firstErr
+	case matches == 0:
+		return &ValidationError{Pointer: pointer, Message: fmt.Sprintf("must match one of %d alternatives", len(schemas))}
+	default:
+		return &ValidationError{Pointer: pointer, Message: fmt.Sprintf("matches %d alternatives, want exactly one", matches)}
+	}
+}
+
+func (v schemaValidator) validateString(schema *JSONSchema, value, pointer string) *ValidationError {
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, value) {
+		return &ValidationError{
+			Pointer: pointer,
+			Message: fmt.Sprintf("must be one of %s", strings.Join(schema.Enum, ", ")),
+		}
+	}
+	if schema.Pattern != "" && !v.patterns[schema.Pattern].MatchString(value) {
+		return &ValidationError{
+			Pointer: pointer,
+			Message: fmt.Sprintf("must match pattern %s", schema.Pattern),
+		}
+	}
+	return nil
+}
+
+func validateNumber(schema *JSONSchema, value float64, pointer string) *ValidationError {
+	if schema.Minimum != nil && value < *schema.Minimum {
+		return &ValidationError{Pointer: pointer, Message: fmt.Sprintf("must be >= %g", *schema.Minimum)}
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		return &ValidationError{Pointer: pointer, Message: fmt.Sprintf("must be <= %g", *schema.Maximum)}
+	}
+	return nil
+}
+
+func (v schemaValidator) validateObject(schema *JSONSchema, value map[string]any, pointer string) *ValidationError {
+	for _, name := range schema.Required {
+		if _, ok := value[name]; !ok {
+			return &ValidationError{Pointer: pointer + "/" + escapePointerToken(name), Message: "is required"}
+		}
+	}
+
+	// Sorted for a deterministic first error
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propPointer := pointer + "/" + escapePointerToken(name)
+		prop, known := schema.Properties[name]
+		if !known {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				return &ValidationError{Pointer: propPointer, Message: "is not allowed"}
+			}
+			continue
+		}
+		if verr := v.validate(&prop, value[name], propPointer); verr != nil {
+			return verr
+		}
+	}
+	return nil
+}
+
+// hasJSONType reports whether a value decoded by encoding/json has the given JSON Schema type.
+func hasJSONType(value any, typ string) bool {
+	switch typ {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+// jsonTypeName returns the JSON type of a value decoded by encoding/json.
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// escapePointerToken escapes a JSON pointer reference token per RFC 6901.
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
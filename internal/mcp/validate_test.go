@@ -0,0 +1,200 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+// validationTool is a tool whose schema uses every supported keyword.
+func validationTool() Tool {
+	return Tool{
+		Name: "set_light",
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"entity_id":  {Type: "string", Pattern: `^light\.`},
+				"brightness": {Type: "integer", Minimum: float64Ptr(0), Maximum: float64Ptr(255)},
+				"effect":     {Type: "string", Enum: []string{"none", "colorloop"}},
+				"tags":       {Type: "array", Items: &JSONSchema{Type: "string"}},
+				"transition": {OneOf: []JSONSchema{
+					{Type: "number", Minimum: float64Ptr(0)},
+					{Type: "string", Pattern: `^\d{2}:\d{2}:\d{2}$`},
+				}},
+				"data": {
+					Type: "object",
+					Properties: map[string]JSONSchema{
+						"a/b": {Type: "boolean"},
+					},
+					AdditionalProperties: boolPtr(false),
+				},
+			},
+			Required: []string{"entity_id"},
+		},
+	}
+}
+
+func TestRegistry_ValidateArguments(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterTool(validationTool(), nil)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want *ValidationError
+	}{
+		{
+			name: "valid",
+			args: map[string]any{
+				"entity_id":  "light.kitchen",
+				"brightness": float64(128),
+				"effect":     "colorloop",
+				"tags":       []any{"a", "b"},
+				"transition": "00:00:05",
+				"data":       map[string]any{"a/b": true},
+				"unknown":    "allowed at the top level",
+			},
+		},
+		{
+			name: "missing required",
+			args: nil,
+			want: &ValidationError{Pointer: "/entity_id", Message: "is required"},
+		},
+		{
+			name: "wrong type",
+			args: map[string]any{"entity_id": float64(1)},
+			want: &ValidationError{Pointer: "/entity_id", Message: "must be of type string, got number"},
+		},
+		{
+			name: "pattern",
+			args: map[string]any{"entity_id": "switch.kitchen"},
+			want: &ValidationError{Pointer: "/entity_id", Message: `must match pattern ^light\.`},
+		},
+		{
+			name: "integer",
+			args: map[string]any{"entity_id": "light.kitchen", "brightness": 1.5},
+			want: &ValidationError{Pointer: "/brightness", Message: "must be of type integer, got number"},
+		},
+		{
+			name: "maximum",
+			args: map[string]any{"entity_id": "light.kitchen", "brightness": float64(256)},
+			want: &ValidationError{Pointer: "/brightness", Message: "must be <= 255"},
+		},
+		{
+			name: "minimum",
+			args: map[string]any{"entity_id": "light.kitchen", "brightness": float64(-1)},
+			want: &ValidationError{Pointer: "/brightness", Message: "must be >= 0"},
+		},
+		{
+			name: "enum",
+			args: map[string]any{"entity_id": "light.kitchen", "effect": "strobe"},
+			want: &ValidationError{Pointer: "/effect", Message: "must be one of none, colorloop"},
+		},
+		{
+			name: "array item",
+			args: map[string]any{"entity_id": "light.kitchen", "tags": []any{"a", true}},
+			want: &ValidationError{Pointer: "/tags/1", Message: "must be of type string, got boolean"},
+		},
+		{
+			name: "oneOf matches none",
+			args: map[string]any{"entity_id": "light.kitchen", "transition": "5s"},
+			want: &ValidationError{Pointer: "/transition", Message: "must match one of 2 alternatives"},
+		},
+		{
+			name: "additional property",
+			args: map[string]any{"entity_id": "light.kitchen", "data": map[string]any{"c": 1}},
+			want: &ValidationError{Pointer: "/data/c", Message: "is not allowed"},
+		},
+		{
+			name: "escaped pointer",
+			args: map[string]any{"entity_id": "light.kitchen", "data": map[string]any{"a/b": "yes"}},
+			want: &ValidationError{Pointer: "/data/a~1b", Message: "must be of type boolean, got string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := registry.ValidateArguments("set_light", tt.args)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateArguments() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var got *ValidationError
+			if !errors.As(err, &got) {
+				t.Fatalf("ValidateArguments() error = %v, want *ValidationError", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ValidationError mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegistry_RegisterTool_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterTool() did not panic on an invalid pattern")
+		}
+	}()
+
+	NewRegistry().RegisterTool(Tool{
+		Name: "broken",
+		InputSchema: JSONSchema{
+			Type:       "object",
+			Properties: map[string]JSONSchema{"id": {Type: "string", Pattern: "("}},
+		},
+	}, nil)
+}
+
+func TestServer_HandleToolsCall_InvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	registry := NewRegistry()
+	registry.RegisterTool(validationTool(), func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+		called = true
+		return &ToolsCallResult{}, nil
+	})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	resp := s.handleRequest(context.Background(), &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(`1`),
+		Method:  MethodToolsCall,
+		Params:  json.RawMessage(`{"name":"set_light","arguments":{"entity_id":"light.kitchen","brightness":"high"}}`),
+	})
+
+	if called {
+		t.Error("handler called despite invalid arguments")
+	}
+	if resp.Error == nil || resp.Error.Code != InvalidParams {
+		t.Fatalf("Error = %+v, want InvalidParams", resp.Error)
+	}
+	want := &ValidationError{Pointer: "/arguments/brightness", Message: "must be of type integer, got string"}
+	if diff := cmp.Diff(want, resp.Error.Data); diff != "" {
+		t.Errorf("error data mismatch (-want +got):\n%s", diff)
+	}
+}
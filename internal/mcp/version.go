@@ -0,0 +1,55 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import "slices"
+
+// ProtocolVersionHeader is the HTTP header in which clients repeat the
+// negotiated protocol version on every request after initialize.
+const ProtocolVersionHeader = "Mcp-Protocol-Version"
+
+// MCP protocol revisions supported by the server.
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20250618 = "2025-06-18"
+	ProtocolVersion20251125 = "2025-11-25"
+)
+
+// supportedProtocolVersions lists the supported revisions, oldest first.
+var supportedProtocolVersions = []string{
+	ProtocolVersion20241105,
+	ProtocolVersion20250326,
+	ProtocolVersion20250618,
+	ProtocolVersion20251125,
+}
+
+// defaultProtocolVersion is assumed for sessions that never negotiated a
+// version, as the Streamable HTTP transport prescribes for requests without
+// an Mcp-Protocol-Version header.
+const defaultProtocolVersion = ProtocolVersion20250326
+
+// Minimum protocol revisions of features that depend on the negotiated version.
+const (
+	structuredOutputVersion = ProtocolVersion20250618
+	elicitationVersion      = ProtocolVersion20250618
+)
+
+// IsSupportedProtocolVersion reports whether the server implements the given protocol revision.
+func IsSupportedProtocolVersion(version string) bool {
+	return slices.Contains(supportedProtocolVersions, version)
+}
+
+// negotiateProtocolVersion returns the version requested by the client if it
+// is supported, or the newest supported version otherwise.
+func negotiateProtocolVersion(requested string) string {
+	if IsSupportedProtocolVersion(requested) {
+		return requested
+	}
+	return ProtocolVersion
+}
+
+// protocolAtLeast reports whether version is the revision minimum or a later one.
+// Revisions are dates, so they order lexically.
+func protocolAtLeast(version, minimum string) bool {
+	return version >= minimum
+}
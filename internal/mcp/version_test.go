@@ -0,0 +1,192 @@
+// Package mcp implements the Model Context Protocol (MCP) server.
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zorak1103/ha-mcp/internal/homeassistant"
+	"github.com/zorak1103/ha-mcp/internal/logging"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{requested: "2024-11-05", want: "2024-11-05"},
+		{requested: "2025-03-26", want: "2025-03-26"},
+		{requested: "2025-06-18", want: "2025-06-18"},
+		{requested: "2025-11-25", want: "2025-11-25"},
+		{requested: "2099-01-01", want: ProtocolVersion},
+		{requested: "", want: ProtocolVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.requested, func(t *testing.T) {
+			t.Parallel()
+
+			if got := negotiateProtocolVersion(tt.requested); got != tt.want {
+				t.Errorf("negotiateProtocolVersion(%q) = %q, want %q", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_ProtocolFeatures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		params         *InitializeParams
+		wantVersion    string
+		wantStructured bool
+		wantCanElicit  bool
+	}{
+		{
+			name:        "not initialized",
+			wantVersion: defaultProtocolVersion,
+		},
+		{
+			name: "old revision declaring elicitation",
+			params: &InitializeParams{
+				ProtocolVersion: "2025-03-26",
+				Capabilities:    ClientCapabilities{Elicitation: map[string]any{}},
+			},
+			wantVersion: "2025-03-26",
+		},
+		{
+			name:           "new revision without elicitation",
+			params:         &InitializeParams{ProtocolVersion: "2025-06-18"},
+			wantVersion:    "2025-06-18",
+			wantStructured: true,
+		},
+		{
+			name: "new revision with elicitation",
+			params: &InitializeParams{
+				ProtocolVersion: "2025-11-25",
+				Capabilities:    ClientCapabilities{Elicitation: map[string]any{}},
+			},
+			wantVersion:    "2025-11-25",
+			wantStructured: true,
+			wantCanElicit:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sess := newSession("s")
+			if tt.params != nil {
+				sess.setInitializeParams(tt.params)
+			}
+
+			if got := sess.ProtocolVersion(); got != tt.wantVersion {
+				t.Errorf("ProtocolVersion() = %q, want %q", got, tt.wantVersion)
+			}
+			if got := sess.supports(structuredOutputVersion); got != tt.wantStructured {
+				t.Errorf("supports(structured output) = %v, want %v", got, tt.wantStructured)
+			}
+			if got := sess.canElicit(); got != tt.wantCanElicit {
+				t.Errorf("canElicit() = %v, want %v", got, tt.wantCanElicit)
+			}
+		})
+	}
+}
+
+func TestServer_StructuredOutputGating(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.RegisterTool(Tool{
+		Name:         "typed",
+		InputSchema:  JSONSchema{Type: "object"},
+		OutputSchema: &JSONSchema{Type: "object"},
+	}, func(_ context.Context, _ homeassistant.Client, _ map[string]any) (*ToolsCallResult, error) {
+		return &ToolsCallResult{
+			Content:           []ContentBlock{NewTextContent(`{"ok":true}`)},
+			StructuredContent: map[string]any{"ok": true},
+		}, nil
+	})
+	s := NewServer(&mockHAClient{}, registry, 8080, logging.New(logging.LevelOff))
+
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "2024-11-05", want: false},
+		{version: "2025-03-26", want: false},
+		{version: "2025-06-18", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			t.Parallel()
+
+			sess := newSession(tt.version)
+			sess.setInitializeParams(&InitializeParams{ProtocolVersion: tt.version})
+			ctx := withSession(context.Background(), sess)
+
+			list := s.handleRequest(ctx, &Request{JSONRPC: JSONRPCVersion, ID: json.RawMessage(`1`), Method: MethodToolsList})
+			tools := list.Result.(ToolsListResult).Tools
+			if got := tools[0].OutputSchema != nil; got != tt.want {
+				t.Errorf("outputSchema listed = %v, want %v", got, tt.want)
+			}
+
+			call := s.handleRequest(ctx, &Request{
+				JSONRPC: JSONRPCVersion,
+				ID:      json.RawMessage(`2`),
+				Method:  MethodToolsCall,
+				Params:  json.RawMessage(`{"name":"typed"}`),
+			})
+			result := call.Result.(*ToolsCallResult)
+			if got := result.StructuredContent != nil; got != tt.want {
+				t.Errorf("structuredContent returned = %v, want %v", got, tt.want)
+			}
+			if len(result.Content) != 1 {
+				t.Errorf("len(Content) = %d, want 1", len(result.Content))
+			}
+		})
+	}
+}
+
+func TestServer_ProtocolVersionHeader(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(&mockHAClient{}, NewRegistry(), 8080, logging.New(logging.LevelOff))
+
+	tests := []struct {
+		header     string
+		wantStatus int
+	}{
+		{header: "", wantStatus: http.StatusOK},
+		{header: "2025-06-18", wantStatus: http.StatusOK},
+		{header: "1999-01-01", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			t.Parallel()
+
+			body := []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+			if tt.header != "" {
+				req.Header.Set(ProtocolVersionHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			s.handleMCP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	calls := r.NewCounterVec("test_calls_total", "Calls.\nPer tool.", "tool")
+	latency := r.NewHistogramVec("test_duration_seconds", "Call latency.", []float64{0.1, 1}, "tool")
+	r.NewGaugeFunc("test_up", "Whether it is up.", func() float64 { return 1 })
+
+	calls.Inc("get_states")
+	calls.Add(2, `say "hi"`)
+	calls.Inc("get_states")
+	latency.Observe(0.05, "get_states")
+	latency.Observe(0.1, "get_states")
+	latency.Observe(3, "get_states")
+
+	var sb strings.Builder
+	if err := r.WriteText(&sb); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `# HELP test_calls_total Calls.\nPer tool.
+# TYPE test_calls_total counter
+test_calls_total{tool="get_states"} 2
+test_calls_total{tool="say \"hi\""} 2
+# HELP test_duration_seconds Call latency.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{tool="get_states",le="0.1"} 2
+test_duration_seconds_bucket{tool="get_states",le="1"} 2
+test_duration_seconds_bucket{tool="get_states",le="+Inf"} 3
+test_duration_seconds_sum{tool="get_states"} 3.15
+test_duration_seconds_count{tool="get_states"} 3
+# HELP test_up Whether it is up.
+# TYPE test_up gauge
+test_up 1
+`
+	if diff := cmp.Diff(want, sb.String()); diff != "" {
+		t.Errorf("WriteText() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	r.NewCounterVec("test_total", "Total.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, ContentType)
+	}
+	if !strings.Contains(rec.Body.String(), "\ntest_total 1\n") {
+		t.Errorf("body = %q, want the test_total sample", rec.Body.String())
+	}
+}
+
+func TestRegistry_DuplicateName(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	r.NewCounterVec("test_total", "Total.")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice did not panic")
+		}
+	}()
+	r.NewGaugeFunc("test_total", "Total.", func() float64 { return 0 })
+}